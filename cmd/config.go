@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
+)
+
+// applyConfigFile sets the flags of cmd to the values of the YAML file given
+// with --config, a map from the names of the flags, without the leading
+// dashes, to their values. The lists set the flags that can be repeated.
+// The flags given on the command line take precedence over the file.
+// The flags of the scan not used by cmd, e.g. by the preflight command, are
+// ignored, so that the same file can be used by all the commands.
+func applyConfigFile(cmd *cobra.Command) error {
+	path, err := cmd.Flags().GetString("config")
+	if err != nil {
+		return err
+	}
+	if path == "" {
+		return nil
+	}
+
+	options, err := readConfigFile(path)
+	if err != nil {
+		return err
+	}
+	for _, name := range slices.Sorted(maps.Keys(options)) {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil {
+			if cmd.Root().Flags().Lookup(name) != nil {
+				continue
+			}
+			return fmt.Errorf("unknown option %q in the config file %s", name, path)
+		}
+		if flag.Name == "config" {
+			return fmt.Errorf("the config file %s cannot set the config option", path)
+		}
+		if flag.Changed {
+			// the command line overrides the config file
+			continue
+		}
+
+		values, err := configOptionValues(options[name])
+		if err != nil {
+			return fmt.Errorf("invalid option %q in the config file %s: %w", name, path, err)
+		}
+		sliceValue, repeatable := flag.Value.(pflag.SliceValue)
+		if !repeatable && len(values) != 1 {
+			return fmt.Errorf("invalid option %q in the config file %s: it takes a single value", name, path)
+		}
+		if repeatable && len(values) == 0 {
+			// an empty list clears the default values
+			if err := sliceValue.Replace(nil); err != nil {
+				return fmt.Errorf("invalid option %q in the config file %s: %w", name, path, err)
+			}
+			flag.Changed = true
+			continue
+		}
+		for _, value := range values {
+			if err := cmd.Flags().Set(name, value); err != nil {
+				return fmt.Errorf("invalid option %q in the config file %s: %w", name, path, err)
+			}
+		}
+	}
+
+	// the flags set by the config file must obey the same constraints as the
+	// ones given on the command line, e.g. the mutually exclusive ones
+	return cmd.ValidateFlagGroups()
+}
+
+// readConfigFile returns the options of the YAML config file, by name.
+func readConfigFile(path string) (map[string]any, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the config file: %w", err)
+	}
+	content, err = yaml.YAMLToJSON(content)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse the config file %s: %w", path, err)
+	}
+
+	options := map[string]any{}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	// the numbers are kept as written, e.g. the integers aren't turned into floats
+	decoder.UseNumber()
+	if err := decoder.Decode(&options); err != nil {
+		return nil, fmt.Errorf("the config file %s must be a map from option names to values: %w", path, err)
+	}
+
+	return options, nil
+}
+
+// configOptionValues returns the values of an option of the config file, as
+// given on the command line.
+func configOptionValues(option any) ([]string, error) {
+	switch option := option.(type) {
+	case []any:
+		values := make([]string, 0, len(option))
+		for _, item := range option {
+			value, err := configOptionValue(item)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	default:
+		value, err := configOptionValue(option)
+		if err != nil {
+			return nil, err
+		}
+		return []string{value}, nil
+	}
+}
+
+func configOptionValue(option any) (string, error) {
+	switch option := option.(type) {
+	case string:
+		return option, nil
+	case bool, json.Number:
+		return fmt.Sprint(option), nil
+	default:
+		return "", fmt.Errorf("unsupported value %v, it must be a string, a number, a boolean or a list of them", option)
+	}
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestApplyConfigFile(t *testing.T) {
+	path := writeConfigFile(t, `
+namespace: from-file
+parallel-namespaces: 3
+skip-namespace-regex:
+  - pr-.*
+  - test-.*
+include-cluster-wide: true
+`)
+
+	tests := []struct {
+		name                string
+		args                []string
+		expectedNamespace   string
+		expectedParallel    int
+		expectedSkipRegexes []string
+		expectedClusterWide bool
+	}{
+		{
+			"file only",
+			[]string{"--config", path},
+			"from-file", 3, []string{"pr-.*", "test-.*"}, true,
+		},
+		{
+			"command line overrides the file",
+			[]string{"--config", path, "--namespace", "from-flag", "--skip-namespace-regex", "dev-.*", "--include-cluster-wide=false"},
+			"from-flag", 3, []string{"dev-.*"}, false,
+		},
+		{
+			"command line alias overrides the file",
+			[]string{"--config", path, "--namespace-concurrency", "7"},
+			"from-file", 7, []string{"pr-.*", "test-.*"}, true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rootCmd := NewRootCommand()
+			require.NoError(t, rootCmd.ParseFlags(test.args))
+
+			require.NoError(t, applyConfigFile(rootCmd))
+
+			namespace, err := rootCmd.Flags().GetString("namespace")
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedNamespace, namespace)
+			parallelNamespaces, err := rootCmd.Flags().GetInt("parallel-namespaces")
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedParallel, parallelNamespaces)
+			skipRegexes, err := rootCmd.Flags().GetStringArray("skip-namespace-regex")
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedSkipRegexes, skipRegexes)
+			clusterWide, err := rootCmd.Flags().GetBool("include-cluster-wide")
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedClusterWide, clusterWide)
+		})
+	}
+}
+
+func TestApplyConfigFileEmptyList(t *testing.T) {
+	path := writeConfigFile(t, "ignore-namespaces: []\n")
+	rootCmd := NewRootCommand()
+	require.NoError(t, rootCmd.ParseFlags([]string{"--config", path}))
+
+	require.NoError(t, applyConfigFile(rootCmd))
+
+	ignoredNamespaces, err := rootCmd.Flags().GetStringSlice("ignore-namespaces")
+	require.NoError(t, err)
+	assert.Empty(t, ignoredNamespaces)
+	assert.True(t, rootCmd.Flags().Changed("ignore-namespaces"))
+}
+
+func TestApplyConfigFilePreflight(t *testing.T) {
+	// the options of the scan not used by preflight are ignored
+	path := writeConfigFile(t, "kubewarden-namespace: from-file\nsample-size: 10\n")
+	rootCmd := NewRootCommand()
+	preflightCmd, _, err := rootCmd.Find([]string{"preflight"})
+	require.NoError(t, err)
+	require.NoError(t, preflightCmd.ParseFlags([]string{"--config", path}))
+
+	require.NoError(t, applyConfigFile(preflightCmd))
+
+	kubewardenNamespace, err := preflightCmd.Flags().GetString("kubewarden-namespace")
+	require.NoError(t, err)
+	assert.Equal(t, "from-file", kubewardenNamespace)
+}
+
+func TestApplyConfigFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"unknown option", "unknown: value\n"},
+		{"config option", "config: other.yaml\n"},
+		{"list for a single value", "namespace: [a, b]\n"},
+		{"invalid value", "parallel-namespaces: many\n"},
+		{"map value", "namespace: {name: default}\n"},
+		{"not a map", "- namespace\n"},
+		{"mutually exclusive options", "watch: true\nfail-on-violation: true\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := writeConfigFile(t, test.content)
+			rootCmd := NewRootCommand()
+			require.NoError(t, rootCmd.ParseFlags([]string{"--config", path}))
+
+			require.Error(t, applyConfigFile(rootCmd))
+		})
+	}
+
+	rootCmd := NewRootCommand()
+	require.NoError(t, rootCmd.ParseFlags([]string{"--config", filepath.Join(t.TempDir(), "missing.yaml")}))
+	require.Error(t, applyConfigFile(rootCmd))
+}
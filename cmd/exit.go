@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// exitCodeError is used when the scanner fails because of an operational error.
+	exitCodeError = 1
+	// defaultExitCodeViolation is used by default when --fail-on-violation is set and
+	// the scan found resources violating policies.
+	defaultExitCodeViolation = 2
+	// exitCodeScanTimeout is used when the scan doesn't complete within --scan-timeout.
+	exitCodeScanTimeout = 3
+	// maxExitCode is the highest exit code supported by POSIX shells.
+	maxExitCode = 255
+)
+
+// exitError makes the process exit with a specific code, so that callers can
+// tell apart the reasons why the scan did not succeed.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// validateViolationExitCode returns an error when the exit code given with
+// --violation-exit-code can't be told apart from the other exit codes.
+func validateViolationExitCode(code int) error {
+	if code <= 0 || code > maxExitCode || code == exitCodeError || code == exitCodeScanTimeout {
+		return fmt.Errorf("--violation-exit-code must be between 1 and %d, and differ from %d and %d, used for operational errors and timeouts", maxExitCode, exitCodeError, exitCodeScanTimeout)
+	}
+
+	return nil
+}
+
+// scanExitError returns the error making the process exit with the code
+// telling why the completed scan did not succeed, or nil when it succeeded.
+// A scan that timed out takes precedence over the violations it found, since
+// it didn't audit all the resources.
+func scanExitError(scanErr error, scanTimeout time.Duration, timedOut bool, violations int, failOnViolation bool, violationExitCode int) error {
+	if timedOut {
+		return &exitError{
+			code: exitCodeScanTimeout,
+			err:  fmt.Errorf("scan did not complete within %s: %w", scanTimeout, scanErr),
+		}
+	}
+	if failOnViolation && violations > 0 {
+		return &exitError{
+			code: violationExitCode,
+			err:  fmt.Errorf("found %d policy violations", violations),
+		}
+	}
+
+	return nil
+}
+
+// exitCode returns the code the process exits with because of err.
+func exitCode(err error) int {
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+
+	return exitCodeError
+}
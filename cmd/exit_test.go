@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanExitError(t *testing.T) {
+	tests := []struct {
+		name            string
+		timedOut        bool
+		violations      int
+		failOnViolation bool
+		expectedCode    int
+	}{
+		{"successful scan", false, 0, true, 0},
+		{"violations ignored", false, 3, false, 0},
+		{"violations", false, 3, true, 42},
+		{"timeout", true, 0, false, exitCodeScanTimeout},
+		{"timeout with violations", true, 3, true, exitCodeScanTimeout},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var scanErr error
+			if test.timedOut {
+				scanErr = context.DeadlineExceeded
+			}
+
+			err := scanExitError(scanErr, time.Minute, test.timedOut, test.violations, test.failOnViolation, 42)
+			if test.expectedCode == 0 {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, test.expectedCode, exitCode(err))
+			if test.timedOut {
+				assert.ErrorIs(t, err, context.DeadlineExceeded)
+			}
+		})
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	assert.Equal(t, exitCodeError, exitCode(errors.New("cannot connect")))
+
+	// the exit code survives the wrapping of the error
+	err := fmt.Errorf("scan failed: %w", &exitError{code: exitCodeScanTimeout, err: context.DeadlineExceeded})
+	assert.Equal(t, exitCodeScanTimeout, exitCode(err))
+}
+
+func TestValidateViolationExitCode(t *testing.T) {
+	for _, code := range []int{defaultExitCodeViolation, 4, maxExitCode} {
+		require.NoError(t, validateViolationExitCode(code), code)
+	}
+	for _, code := range []int{-1, 0, exitCodeError, exitCodeScanTimeout, maxExitCode + 1} {
+		require.Error(t, validateViolationExitCode(code), code)
+	}
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// waitStartupJitter waits a random time between 0 and maxJitter, so that the
+// scanners started on the same schedule, e.g. by the CronJobs of many
+// clusters, don't send their requests to shared PolicyServers all together.
+// It doesn't wait when maxJitter is 0. The wait is interrupted when ctx is done.
+func waitStartupJitter(ctx context.Context, maxJitter time.Duration) error {
+	if maxJitter <= 0 {
+		return nil
+	}
+
+	//nolint:gosec // the jitter doesn't need a cryptographically secure random number
+	jitter := rand.N(maxJitter + 1)
+	log.Info().Dur("startup-jitter", jitter).Msg("waiting before starting the scan")
+
+	timer := time.NewTimer(jitter)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("interrupted while waiting before starting the scan: %w", ctx.Err())
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+
+	"github.com/kubewarden/audit-scanner/internal/k8s"
+	logconfig "github.com/kubewarden/audit-scanner/internal/log"
+	"github.com/kubewarden/audit-scanner/internal/policies"
+	"github.com/kubewarden/audit-scanner/internal/scanner"
+	"github.com/kubewarden/audit-scanner/internal/scheme"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// preflightFlags are the flags of the root command used by the preflight
+// command, the ones telling how to reach the cluster and the PolicyServers.
+var preflightFlags = []string{
+	"config",
+	"kubewarden-namespace",
+	"ignore-namespaces",
+	"policy",
+	"mode-filter",
+	"audit-operation",
+	"audit-user",
+	"audit-group",
+	"admission-api-version",
+	"include-kind",
+	"exclude-kind",
+	"extra-gvr",
+	"kubeconfig",
+	"kube-context",
+	"kube-api-qps",
+	"kube-api-burst",
+	"user-agent",
+	"policy-server-url",
+	"policy-server-name",
+	"policy-server-allow-http",
+	"policy-server-timeout",
+	"policy-server-token",
+	"policy-server-token-file",
+	"insecure-ssl",
+	"accept-insecure-tls",
+	"extra-ca",
+	"ca-cert-dir",
+	"client-cert",
+	"client-key",
+	"loglevel",
+	"log-format",
+}
+
+// policyServerToCheck is a policy evaluated to check the PolicyServer running it.
+type policyServerToCheck struct {
+	policy *policies.Policy
+	gvr    schema.GroupVersionResource
+}
+
+// newPreflightCommand returns the command checking that the Kubernetes API
+// server and the PolicyServers can be reached, sharing the flags of rootCmd.
+func newPreflightCommand(rootCmd *cobra.Command, level *logconfig.Level, logFormat *logconfig.Format) *cobra.Command {
+	preflightCmd := &cobra.Command{
+		Use:   "preflight",
+		Short: "Check that the Kubernetes API server and the PolicyServers can be reached, without scanning",
+		Long: `Checks that the Kubernetes API server can be reached, resolves the policies to be evaluated and sends
+an AdmissionReview of an empty resource to every PolicyServer running them, using the same settings as a scan.
+It catches TLS, CA and authentication misconfigurations before starting a scan. No report is written.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := applyConfigFile(cmd); err != nil {
+				return err
+			}
+			logFormat.SetZeroLogFormat()
+			level.SetZeroLogLevel()
+			logconfig.RedirectLibraryLogs()
+			return runPreflight(cmd, cmd.OutOrStdout())
+		},
+	}
+	for _, name := range preflightFlags {
+		preflightCmd.Flags().AddFlag(rootCmd.Flags().Lookup(name))
+	}
+	preflightCmd.Flags().SetNormalizeFunc(normalizeFlagAliases)
+
+	return preflightCmd
+}
+
+// runPreflight checks every endpoint, writing the outcome of each check to out.
+// It returns an error when any of the checks failed.
+func runPreflight(cmd *cobra.Command, out io.Writer) error {
+	ctx := cmd.Context()
+	checks, failures := 0, 0
+	printCheck := func(endpoint string, err error) {
+		checks++
+		if err != nil {
+			failures++
+			fmt.Fprintf(out, "FAILED  %s: %s\n", endpoint, err)
+			return
+		}
+		fmt.Fprintf(out, "OK      %s\n", endpoint)
+	}
+
+	config, err := newRESTConfig(cmd)
+	if err != nil {
+		return err
+	}
+	kubewardenNamespace, err := cmd.Flags().GetString("kubewarden-namespace")
+	if err != nil {
+		return err
+	}
+	skippedNs, err := cmd.Flags().GetStringSlice("ignore-namespaces")
+	if err != nil {
+		return err
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	k8sClient, err := k8s.NewClient(dynamicClient, clientset, kubewardenNamespace, skippedNs, defaultPageSize)
+	if err != nil {
+		return err
+	}
+
+	namespaces, err := k8sClient.GetAuditedNamespaces(ctx)
+	printCheck("Kubernetes API server "+config.Host, err)
+	if err != nil {
+		return errors.New("the Kubernetes API server cannot be reached")
+	}
+
+	auditScheme, err := scheme.NewScheme()
+	if err != nil {
+		return err
+	}
+	client, err := client.New(config, client.Options{Scheme: auditScheme})
+	if err != nil {
+		return err
+	}
+	policyNames, err := cmd.Flags().GetStringSlice("policy")
+	if err != nil {
+		return err
+	}
+	includedKinds, excludedKinds, err := kindFilter(cmd)
+	if err != nil {
+		return err
+	}
+	extraGVRs, err := extraResources(cmd)
+	if err != nil {
+		return err
+	}
+	admissionConfig, err := newAdmissionConfig(cmd)
+	if err != nil {
+		return err
+	}
+	policiesClient, err := newPoliciesClient(cmd, client, policyNames, includedKinds, excludedKinds, extraGVRs, admissionConfig.Operation)
+	if err != nil {
+		return err
+	}
+
+	// a single policy is evaluated for every PolicyServer
+	policyServers := map[string]policyServerToCheck{}
+	addPolicyServers := func(policies *policies.Policies) {
+		for gvr, gvrPolicies := range policies.PoliciesByGVR {
+			for _, policy := range gvrPolicies {
+				endpoint := policy.PolicyServer.Scheme + "://" + policy.PolicyServer.Host
+				if _, found := policyServers[endpoint]; !found {
+					policyServers[endpoint] = policyServerToCheck{policy: policy, gvr: gvr}
+				}
+			}
+		}
+	}
+	clusterWidePolicies, err := policiesClient.GetClusterWidePolicies(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot resolve the cluster wide policies: %w", err)
+	}
+	addPolicyServers(clusterWidePolicies)
+	for _, namespace := range namespaces.Items {
+		namespacedPolicies, err := policiesClient.GetPoliciesByNamespace(ctx, &namespace)
+		if err != nil {
+			return fmt.Errorf("cannot resolve the policies of namespace %s: %w", namespace.Name, err)
+		}
+		addPolicyServers(namespacedPolicies)
+	}
+	if len(policyServers) == 0 {
+		fmt.Fprintln(out, "no policies to be evaluated, no PolicyServer checked")
+		return nil
+	}
+
+	tlsConfig, err := newTLSConfig(cmd)
+	if err != nil {
+		return err
+	}
+	policyServerTimeout, err := cmd.Flags().GetDuration("policy-server-timeout")
+	if err != nil {
+		return err
+	}
+	policyServerToken, err := cmd.Flags().GetString("policy-server-token")
+	if err != nil {
+		return err
+	}
+	policyServerTokenFile, err := cmd.Flags().GetString("policy-server-token-file")
+	if err != nil {
+		return err
+	}
+	userAgent, err := cmd.Flags().GetString("user-agent")
+	if err != nil {
+		return err
+	}
+	policyServerChecker, err := scanner.NewScanner(scanner.Config{
+		TLS: tlsConfig,
+		PolicyServer: scanner.PolicyServerConfig{
+			Timeout:   policyServerTimeout,
+			Token:     policyServerToken,
+			TokenFile: policyServerTokenFile,
+			UserAgent: userAgent,
+		},
+		Admission: admissionConfig,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, endpoint := range slices.Sorted(maps.Keys(policyServers)) {
+		toCheck := policyServers[endpoint]
+		err := policyServerChecker.CheckPolicyServer(ctx, toCheck.policy, toCheck.gvr)
+		printCheck(fmt.Sprintf("PolicyServer %s, evaluating policy %s", endpoint, toCheck.policy.GetUniqueName()), err)
+	}
+	if failures > 0 {
+		return fmt.Errorf("%d of %d preflight checks failed", failures, checks)
+	}
+
+	return nil
+}
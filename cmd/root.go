@@ -2,20 +2,51 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/kubewarden/audit-scanner/internal/export"
+	"github.com/kubewarden/audit-scanner/internal/health"
 	"github.com/kubewarden/audit-scanner/internal/k8s"
 	logconfig "github.com/kubewarden/audit-scanner/internal/log"
+	"github.com/kubewarden/audit-scanner/internal/metrics"
+	"github.com/kubewarden/audit-scanner/internal/notify"
 	"github.com/kubewarden/audit-scanner/internal/policies"
 	"github.com/kubewarden/audit-scanner/internal/report"
 	"github.com/kubewarden/audit-scanner/internal/scanner"
 	"github.com/kubewarden/audit-scanner/internal/scheme"
+	"github.com/kubewarden/audit-scanner/internal/tracing"
+	"github.com/kubewarden/audit-scanner/internal/version"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
-	ctrl "sigs.k8s.io/controller-runtime"
+	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -25,16 +56,77 @@ const (
 	defaultParallelPolicies    = 5
 	defaultParallelNamespaces  = 1
 	defaultPageSize            = 100
+	defaultKubeAPIQPS          = 50
+	defaultKubeAPIBurst        = 100
+	defaultNamespaceCacheTTL   = 30 * time.Second
+	defaultVerdictCacheTTL     = time.Hour
+	defaultPolicyServerTimeout = 10 * time.Second
+	defaultPolicyServerRetries = 3
+	defaultPolicyServerBackoff = 500 * time.Millisecond
+	defaultPolicyServerBurst   = 10
+	// the PolicyServers are a handful of hosts receiving many requests, hence
+	// more idle connections than the net/http defaults are kept for each of them
+	defaultPolicyServerMaxIdleConns        = 100
+	defaultPolicyServerMaxIdleConnsPerHost = 50
+	defaultPolicyServerIdleConnTimeout     = 90 * time.Second
+	tracingShutdownTimeout                 = 5 * time.Second
+	defaultWatchInterval                   = time.Hour
+	defaultHealthStallTimeout              = 2 * time.Hour
+	outputDirPermissions                   = 0o755
+	defaultS3Endpoint                      = "s3.amazonaws.com"
+	defaultSlackMaxResults                 = 20
+	// defaultAuditUser is the user of the AdmissionRequests auditing the
+	// resources, named after the service account of the audit scanner
+	defaultAuditUser = "system:serviceaccount:kubewarden:audit-scanner"
 )
 
+// defaultAuditGroups are the groups of the AdmissionRequests auditing the
+// resources, the ones Kubernetes gives to the service account of defaultAuditUser.
+var defaultAuditGroups = []string{"system:serviceaccounts", "system:serviceaccounts:kubewarden", "system:authenticated"}
+
+// scanFilterFlags are the flags restricting the resources audited, the
+// policies evaluated or the results written. The ones given are recorded in
+// the reports, so that their consumers know what the scan covered.
+var scanFilterFlags = []string{
+	"namespace",
+	"cluster",
+	"namespace-selector",
+	"ignore-namespaces",
+	"skip-namespace-regex",
+	"resource-selector",
+	"field-selector",
+	"include-kind",
+	"exclude-kind",
+	"extra-gvr",
+	"skip-owned-resources",
+	"skip-owner-kind",
+	"sample-size",
+	"policy",
+	"policy-server-name",
+	"mode-filter",
+	"audit-operation",
+	"min-severity",
+	"only-failed",
+}
+
+// fullScanFilterFlags are the scanFilterFlags not narrowing the resources
+// audited or the policies evaluated: --extra-gvr adds resources and
+// --only-failed drops only the results written, not the ones counted.
+var fullScanFilterFlags = []string{
+	"extra-gvr",
+	"only-failed",
+}
+
 //nolint:gocognit,funlen // This function is the CLI entrypoint and it's expected to be long.
 func NewRootCommand() *cobra.Command {
 	var (
-		level        logconfig.Level // log level.
-		outputScan   bool            // print result of scan as JSON to stdout.
-		skippedNs    []string        // list of namespaces to be skipped from scan.
-		insecureSSL  bool            // skip SSL cert validation when connecting to PolicyServers endpoints.
-		disableStore bool            // disable storing the results in the k8s cluster.
+		level        logconfig.Level  // log level.
+		logFormat    logconfig.Format // log encoder.
+		outputScan   bool             // print result of scan as JSON to stdout.
+		skippedNs    []string         // list of namespaces to be skipped from scan.
+		insecureSSL  bool             // skip SSL cert validation when connecting to PolicyServers endpoints.
+		disableStore bool             // disable storing the results in the k8s cluster.
+		outputFile   string           // write all the reports of the scan as JSON to this file.
 	)
 
 	// rootCmd represents the base command when called without any subcommands.
@@ -44,9 +136,22 @@ func NewRootCommand() *cobra.Command {
 		Long: `Scans resources in your kubernetes cluster with your already deployed Kubewarden policies.
 Each namespace will have a PolicyReport with the outcome of the scan for resources within this namespace.
 There will be a ClusterPolicyReport with results for cluster-wide resources.`,
+		Version: version.String(),
 
 		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := applyConfigFile(cmd); err != nil {
+				return err
+			}
+			logFormat.SetZeroLogFormat()
 			level.SetZeroLogLevel()
+			quiet, err := cmd.Flags().GetBool("quiet")
+			if err != nil {
+				return err
+			}
+			if quiet {
+				logconfig.SetQuiet()
+			}
+			logconfig.RedirectLibraryLogs()
 			namespace, err := cmd.Flags().GetString("namespace")
 			if err != nil {
 				return err
@@ -59,19 +164,106 @@ There will be a ClusterPolicyReport with results for cluster-wide resources.`,
 			if err != nil {
 				return err
 			}
-			policyServerURL, err := cmd.Flags().GetString("policy-server-url")
+			if clusterWide && namespace != "" {
+				return errors.New("--cluster scans only the cluster wide resources, it cannot be used together with --namespace")
+			}
+			includeClusterWide, err := cmd.Flags().GetBool("include-cluster-wide")
+			if err != nil {
+				return err
+			}
+			if includeClusterWide && namespace == "" {
+				return errors.New("--include-cluster-wide requires --namespace, the cluster wide resources are already scanned otherwise")
+			}
+			namespaceSelectorFlag, err := cmd.Flags().GetString("namespace-selector")
+			if err != nil {
+				return err
+			}
+			if namespace != "" && namespaceSelectorFlag != "" {
+				return errors.New("--namespace and --namespace-selector cannot be used together")
+			}
+			namespaceSelector, err := parseNamespaceSelector(namespaceSelectorFlag)
+			if err != nil {
+				return err
+			}
+			skipNamespaceRegexesFlag, err := cmd.Flags().GetStringArray("skip-namespace-regex")
+			if err != nil {
+				return err
+			}
+			skipNamespaceRegexes, err := parseSkipNamespaceRegexes(skipNamespaceRegexesFlag)
+			if err != nil {
+				return err
+			}
+			redactPaths, err := cmd.Flags().GetStringArray("redact-path")
+			if err != nil {
+				return err
+			}
+			redactions, err := parseRedactions(redactPaths)
+			if err != nil {
+				return err
+			}
+			admissionConfig, err := newAdmissionConfig(cmd)
+			if err != nil {
+				return err
+			}
+			skipOwnedResources, err := cmd.Flags().GetBool("skip-owned-resources")
+			if err != nil {
+				return err
+			}
+			skipOwnerKinds, err := cmd.Flags().GetStringSlice("skip-owner-kind")
+			if err != nil {
+				return err
+			}
+			if len(skipOwnerKinds) > 0 && !skipOwnedResources {
+				return errors.New("--skip-owner-kind requires --skip-owned-resources")
+			}
+			sampleSize, err := cmd.Flags().GetInt("sample-size")
+			if err != nil {
+				return err
+			}
+			if sampleSize < 0 {
+				return errors.New("--sample-size cannot be negative")
+			}
+			resourceSelectorFlag, err := cmd.Flags().GetString("resource-selector")
+			if err != nil {
+				return err
+			}
+			resourceSelector, err := labels.Parse(resourceSelectorFlag)
+			if err != nil {
+				return fmt.Errorf("invalid --resource-selector %q: %w", resourceSelectorFlag, err)
+			}
+			minSeverity, err := cmd.Flags().GetString("min-severity")
+			if err != nil {
+				return err
+			}
+			includeUnsetSeverity, err := cmd.Flags().GetBool("include-unset-severity")
+			if err != nil {
+				return err
+			}
+			severityFilter, err := report.NewSeverityFilter(minSeverity, includeUnsetSeverity)
+			if err != nil {
+				return fmt.Errorf("invalid --min-severity: %w", err)
+			}
+			fieldSelectorFlag, err := cmd.Flags().GetString("field-selector")
+			if err != nil {
+				return err
+			}
+			fieldSelector, err := fields.ParseSelector(fieldSelectorFlag)
+			if err != nil {
+				return fmt.Errorf("invalid --field-selector %q: %w", fieldSelectorFlag, err)
+			}
+			policyNames, err := cmd.Flags().GetStringSlice("policy")
 			if err != nil {
 				return err
 			}
-			caFile, err := cmd.Flags().GetString("extra-ca")
+			includedKinds, excludedKinds, err := kindFilter(cmd)
 			if err != nil {
 				return err
 			}
-			clientCertFile, err := cmd.Flags().GetString("client-cert")
+			extraGVRs, err := extraResources(cmd)
 			if err != nil {
 				return err
 			}
-			clientKeyFile, err := cmd.Flags().GetString("client-key")
+			tlsConfig, err := newTLSConfig(cmd)
 			if err != nil {
 				return err
 			}
@@ -91,10 +283,187 @@ There will be a ClusterPolicyReport with results for cluster-wide resources.`,
 			if err != nil {
 				return err
 			}
+			if pageSize <= 0 {
+				return errors.New("--page-size must be a positive number")
+			}
+			policyServerTimeout, err := cmd.Flags().GetDuration("policy-server-timeout")
+			if err != nil {
+				return err
+			}
+			if policyServerTimeout <= 0 {
+				return errors.New("--policy-server-timeout must be a positive duration")
+			}
+			policyServerMaxRetries, err := cmd.Flags().GetInt("policy-server-max-retries")
+			if err != nil {
+				return err
+			}
+			if policyServerMaxRetries < 0 {
+				return errors.New("--policy-server-max-retries cannot be negative")
+			}
+			policyServerRetryBackoff, err := cmd.Flags().GetDuration("policy-server-retry-backoff")
+			if err != nil {
+				return err
+			}
+			if policyServerMaxRetries > 0 && policyServerRetryBackoff <= 0 {
+				// the backoff is used only when the requests are retried
+				return errors.New("--policy-server-retry-backoff must be a positive duration")
+			}
+			policyServerQPS, err := cmd.Flags().GetFloat64("policy-server-qps")
+			if err != nil {
+				return err
+			}
+			if policyServerQPS < 0 {
+				return errors.New("--policy-server-qps cannot be negative")
+			}
+			policyServerBurst, err := cmd.Flags().GetInt("policy-server-burst")
+			if err != nil {
+				return err
+			}
+			if policyServerBurst < 1 {
+				return errors.New("--policy-server-burst must be at least 1")
+			}
+			compressRequests, err := cmd.Flags().GetBool("compress-requests")
+			if err != nil {
+				return err
+			}
+			maxResourceBytes, err := cmd.Flags().GetInt("max-resource-bytes")
+			if err != nil {
+				return err
+			}
+			if maxResourceBytes < 0 {
+				return errors.New("--max-resource-bytes cannot be negative")
+			}
+			policyServerToken, err := cmd.Flags().GetString("policy-server-token")
+			if err != nil {
+				return err
+			}
+			policyServerTokenFile, err := cmd.Flags().GetString("policy-server-token-file")
+			if err != nil {
+				return err
+			}
+
+			watchMode, err := cmd.Flags().GetBool("watch")
+			if err != nil {
+				return err
+			}
+			watchInterval, err := cmd.Flags().GetDuration("interval")
+			if err != nil {
+				return err
+			}
+			if watchMode && watchInterval <= 0 {
+				return errors.New("--interval must be a positive duration")
+			}
+			policyServerKeepAlive, err := cmd.Flags().GetBool("policy-server-keep-alive")
+			if err != nil {
+				return err
+			}
+			policyServerMaxIdleConns, err := cmd.Flags().GetInt("policy-server-max-idle-conns")
+			if err != nil {
+				return err
+			}
+			policyServerMaxIdleConnsPerHost, err := cmd.Flags().GetInt("policy-server-max-idle-conns-per-host")
+			if err != nil {
+				return err
+			}
+			if policyServerMaxIdleConns < 0 || policyServerMaxIdleConnsPerHost < 0 {
+				return errors.New("--policy-server-max-idle-conns and --policy-server-max-idle-conns-per-host cannot be negative")
+			}
+			policyServerIdleConnTimeout, err := cmd.Flags().GetDuration("policy-server-idle-conn-timeout")
+			if err != nil {
+				return err
+			}
+			if policyServerIdleConnTimeout <= 0 {
+				return errors.New("--policy-server-idle-conn-timeout must be a positive duration")
+			}
+
+			startupJitter, err := cmd.Flags().GetDuration("startup-jitter")
+			if err != nil {
+				return err
+			}
+			if startupJitter < 0 {
+				return errors.New("--startup-jitter cannot be negative")
+			}
+			scanTimeout, err := cmd.Flags().GetDuration("scan-timeout")
+			if err != nil {
+				return err
+			}
+			if scanTimeout < 0 {
+				return errors.New("--scan-timeout cannot be negative")
+			}
+			failOnViolation, err := cmd.Flags().GetBool("fail-on-violation")
+			if err != nil {
+				return err
+			}
+			violationExitCode, err := cmd.Flags().GetInt("violation-exit-code")
+			if err != nil {
+				return err
+			}
+			if err := validateViolationExitCode(violationExitCode); err != nil {
+				return err
+			}
+			errorsFile, err := cmd.Flags().GetString("errors-file")
+			if err != nil {
+				return err
+			}
+			policySummaryFile, err := cmd.Flags().GetString("policy-summary-file")
+			if err != nil {
+				return err
+			}
+			summaryReport, err := cmd.Flags().GetBool("summary-report")
+			if err != nil {
+				return err
+			}
+			if summaryReport && disableStore {
+				return errors.New("--summary-report cannot be used together with --disable-store")
+			}
+			if flag := partialScanFlag(cmd); summaryReport && flag != "" {
+				return fmt.Errorf("--summary-report cannot be used together with --%s, the summary would count only part of the resources", flag)
+			}
+			checkpointFile, err := cmd.Flags().GetString("checkpoint-file")
+			if err != nil {
+				return err
+			}
+			resumeFrom, err := cmd.Flags().GetString("resume-from")
+			if err != nil {
+				return err
+			}
+			if checkpointFile == "" {
+				// the resumed scan goes on recording its progress in the same file
+				checkpointFile = resumeFrom
+			}
+			metricsAddress, err := cmd.Flags().GetString("metrics-address")
+			if err != nil {
+				return err
+			}
+			otelEndpoint, err := cmd.Flags().GetString("otel-endpoint")
+			if err != nil {
+				return err
+			}
+			healthAddress, err := cmd.Flags().GetString("health-address")
+			if err != nil {
+				return err
+			}
+			healthStallTimeout, err := cmd.Flags().GetDuration("health-stall-timeout")
+			if err != nil {
+				return err
+			}
 
-			config := ctrl.GetConfigOrDie()
-			dynamicClient := dynamic.NewForConfigOrDie(config)
-			clientset := kubernetes.NewForConfigOrDie(config)
+			userAgent, err := cmd.Flags().GetString("user-agent")
+			if err != nil {
+				return err
+			}
+			config, err := newRESTConfig(cmd)
+			if err != nil {
+				return err
+			}
+			dynamicClient, err := dynamic.NewForConfig(config)
+			if err != nil {
+				return err
+			}
+			clientset, err := kubernetes.NewForConfig(config)
+			if err != nil {
+				return err
+			}
 
 			auditScheme, err := scheme.NewScheme()
 			if err != nil {
@@ -104,40 +473,359 @@ There will be a ClusterPolicyReport with results for cluster-wide resources.`,
 			if err != nil {
 				return err
 			}
-			policiesClient, err := policies.NewClient(client, kubewardenNamespace, policyServerURL)
+			policiesClient, err := newPoliciesClient(cmd, client, policyNames, includedKinds, excludedKinds, extraGVRs, admissionConfig.Operation)
+			if err != nil {
+				return err
+			}
+			missingPolicyNames, err := policiesClient.MissingPolicyNames(context.Background())
+			if err != nil {
+				return err
+			}
+			if len(missingPolicyNames) > 0 {
+				log.Warn().Strs("policies", missingPolicyNames).Msg("the selected policies don't exist in the cluster")
+			}
+			namespaceCacheTTL, err := cmd.Flags().GetDuration("namespace-cache-ttl")
+			if err != nil {
+				return err
+			}
+			if namespaceCacheTTL < 0 {
+				return errors.New("--namespace-cache-ttl cannot be negative")
+			}
+			// every page of resources fetched is a progress of the scan for the liveness probe
+			healthChecker := health.NewChecker(healthStallTimeout)
+			k8sClient, err := k8s.NewClient(dynamicClient, clientset, kubewardenNamespace, skippedNs, int64(pageSize),
+				k8s.WithNamespaceCacheTTL(namespaceCacheTTL), k8s.WithPageFetched(healthChecker.Progress))
+			if err != nil {
+				return err
+			}
+			unknownKinds, err := k8sClient.UnknownKinds(slices.Concat(includedKinds, excludedKinds))
 			if err != nil {
 				return err
 			}
-			k8sClient, err := k8s.NewClient(dynamicClient, clientset, kubewardenNamespace, skippedNs, int64(pageSize))
+			if len(unknownKinds) > 0 {
+				return fmt.Errorf("the kinds %v given with --include-kind or --exclude-kind are not served by the cluster", unknownKinds)
+			}
+			unknownGVRs, err := k8sClient.UnknownResources(extraGVRs)
 			if err != nil {
 				return err
 			}
-			policyReportStore := report.NewPolicyReportStore(client)
+			if len(unknownGVRs) > 0 {
+				return fmt.Errorf("the resources %v given with --extra-gvr are not served by the cluster", unknownGVRs)
+			}
+			outputFormat, err := cmd.Flags().GetString("output-format")
+			if err != nil {
+				return err
+			}
+			if !slices.Contains(report.SupportedOutputFormats(), report.OutputFormat(outputFormat)) {
+				return fmt.Errorf("unsupported --output-format %q, supported values are: %v", outputFormat, report.SupportedOutputFormats())
+			}
+			if report.OutputFormat(outputFormat) == report.OutputFormatTable && outputFile != "" {
+				return errors.New("--output-format table is meant for terminals and cannot be used together with --output-file")
+			}
+			// the reports are written to stdout when the format is requested without an output file
+			writeReports := outputFile != "" || cmd.Flags().Changed("output-format")
+			// with jsonl, the reports are streamed as they are recorded instead of being kept in memory
+			streamReports := writeReports && report.OutputFormat(outputFormat) == report.OutputFormatJSONLines
+
+			dryRun, err := cmd.Flags().GetBool("dry-run")
+			if err != nil {
+				return err
+			}
+			recordTimings, err := cmd.Flags().GetBool("record-timings")
+			if err != nil {
+				return err
+			}
+			cacheVerdicts, err := cmd.Flags().GetBool("cache-verdicts")
+			if err != nil {
+				return err
+			}
+			verdictCacheTTL, err := cmd.Flags().GetDuration("verdict-cache-ttl")
+			if err != nil {
+				return err
+			}
+			if cacheVerdicts && verdictCacheTTL <= 0 {
+				return errors.New("--verdict-cache-ttl must be a positive duration")
+			}
+			if !cacheVerdicts {
+				verdictCacheTTL = 0
+			}
+			keepOldReports, err := cmd.Flags().GetBool("keep-old-reports")
+			if err != nil {
+				return err
+			}
+			pruneStaleReports, err := cmd.Flags().GetBool("prune-stale-reports")
+			if err != nil {
+				return err
+			}
+			mirrorNamespace, err := cmd.Flags().GetString("resource-namespace-override")
+			if err != nil {
+				return err
+			}
+			if mirrorNamespace != "" {
+				if errs := validation.IsDNS1123Label(mirrorNamespace); len(errs) > 0 {
+					return fmt.Errorf("--resource-namespace-override must be a valid namespace name: %s", strings.Join(errs, ", "))
+				}
+			}
+			reportHistory, err := cmd.Flags().GetInt("report-history")
+			if err != nil {
+				return err
+			}
+			if reportHistory < 0 || reportHistory > report.MaxReportHistory {
+				return fmt.Errorf("--report-history must be between 0 and %d", report.MaxReportHistory)
+			}
+			onlyFailed, err := cmd.Flags().GetBool("only-failed")
+			if err != nil {
+				return err
+			}
+
+			applyMode, err := cmd.Flags().GetString("apply-mode")
+			if err != nil {
+				return err
+			}
+			if !slices.Contains(report.SupportedApplyModes(), report.ApplyMode(applyMode)) {
+				return fmt.Errorf("unsupported --apply-mode %q, supported values are: %v", applyMode, report.SupportedApplyModes())
+			}
+
+			s3Exporter, err := newS3Exporter(cmd)
+			if err != nil {
+				return err
+			}
+
+			slackNotifier, err := newSlackNotifier(cmd)
+			if err != nil {
+				return err
+			}
+
+			storeOpts := []report.StoreOption{
+				report.WithApplyMode(report.ApplyMode(applyMode)),
+				report.WithScanCoverage(scanFilters(cmd)),
+			}
+			if streamReports {
+				stream, closeStream, err := openReportStream(outputFile)
+				if err != nil {
+					return err
+				}
+				defer closeStream()
+				storeOpts = append(storeOpts, report.WithReportStream(stream))
+			}
+			if (writeReports && !streamReports) || s3Exporter != nil || slackNotifier != nil {
+				storeOpts = append(storeOpts, report.WithInMemoryReports())
+			}
+			if dryRun {
+				storeOpts = append(storeOpts, report.WithDryRun())
+			}
+			if reportHistory > 0 {
+				storeOpts = append(storeOpts, report.WithReportHistory(reportHistory))
+			}
+			if onlyFailed {
+				storeOpts = append(storeOpts, report.WithOnlyFailedResults())
+			}
+			if sampleSize > 0 {
+				storeOpts = append(storeOpts, report.WithSampleSize(sampleSize))
+			}
+			policyReportStore := report.NewPolicyReportStore(client, storeOpts...)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			shutdownTracing, err := tracing.Setup(ctx, otelEndpoint)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				// flush the pending spans, even when the scan has been cancelled
+				shutdownCtx, shutdownCancel := context.WithTimeout(context.WithoutCancel(ctx), tracingShutdownTimeout)
+				defer shutdownCancel()
+				if err := shutdownTracing(shutdownCtx); err != nil {
+					log.Error().Err(err).Msg("error flushing traces")
+				}
+			}()
+
+			var scanMetrics *metrics.Metrics
+			if metricsAddress != "" {
+				registry := prometheus.NewRegistry()
+				scanMetrics, err = metrics.New(registry)
+				if err != nil {
+					return err
+				}
+				metricsDone, err := metrics.Serve(ctx, metricsAddress, registry)
+				if err != nil {
+					return err
+				}
+				// stop the metrics server once the scan is over, before returning
+				defer func() {
+					cancel()
+					if err := <-metricsDone; err != nil {
+						log.Error().Err(err).Msg("metrics server failed")
+					}
+				}()
+			}
+
+			if healthAddress != "" {
+				healthDone, err := health.Serve(ctx, healthAddress, healthChecker)
+				if err != nil {
+					return err
+				}
+				// stop the health server once the scanner is stopped, before returning
+				defer func() {
+					cancel()
+					if err := <-healthDone; err != nil {
+						log.Error().Err(err).Msg("health server failed")
+					}
+				}()
+			}
 
 			scannerConfig := scanner.Config{
 				PoliciesClient:    policiesClient,
 				K8sClient:         k8sClient,
 				PolicyReportStore: policyReportStore,
-				TLS: scanner.TLSConfig{
-					Insecure:       insecureSSL,
-					CAFile:         caFile,
-					ClientCertFile: clientCertFile,
-					ClientKeyFile:  clientKeyFile,
-				},
+				Metrics:           scanMetrics,
+				TLS:               tlsConfig,
 				Parallelization: scanner.ParallelizationConfig{
 					ParallelNamespacesAudits: parallelNamespacesAudits,
 					ParallelResourcesAudits:  parallelResourcesAudits,
 					PoliciesAudits:           parallelPoliciesAudit,
 				},
-				OutputScan:   outputScan,
-				DisableStore: disableStore,
+				PolicyServer: scanner.PolicyServerConfig{
+					Timeout:             policyServerTimeout,
+					MaxRetries:          policyServerMaxRetries,
+					RetryBackoff:        policyServerRetryBackoff,
+					Token:               policyServerToken,
+					TokenFile:           policyServerTokenFile,
+					KeepAlive:           policyServerKeepAlive,
+					MaxIdleConns:        policyServerMaxIdleConns,
+					MaxIdleConnsPerHost: policyServerMaxIdleConnsPerHost,
+					IdleConnTimeout:     policyServerIdleConnTimeout,
+					QPS:                 policyServerQPS,
+					Burst:               policyServerBurst,
+					MaxResourceBytes:    maxResourceBytes,
+					CompressRequests:    compressRequests,
+					UserAgent:           userAgent,
+				},
+				Admission:            admissionConfig,
+				NamespaceSelector:    namespaceSelector,
+				SkipNamespaceRegexes: skipNamespaceRegexes,
+				ResourceSelector:     resourceSelector,
+				FieldSelector:        fieldSelector,
+				PolicyNames:          policyNames,
+				IncludedKinds:        includedKinds,
+				ExcludedKinds:        excludedKinds,
+				SeverityFilter:       severityFilter,
+				Redactions:           redactions,
+				SkipOwnedResources:   skipOwnedResources,
+				SkipOwnerKinds:       skipOwnerKinds,
+				SampleSize:           sampleSize,
+				RecordTimings:        recordTimings,
+				VerdictCacheTTL:      verdictCacheTTL,
+				KeepOldReports:       keepOldReports,
+				PruneStaleReports:    pruneStaleReports,
+				MirrorNamespace:      mirrorNamespace,
+				OutputScan:           outputScan,
+				DisableStore:         disableStore,
+				CollectErrors:        errorsFile != "",
+				CheckpointFile:       checkpointFile,
 			}
 
 			scanner, err := scanner.NewScanner(scannerConfig)
 			if err != nil {
 				return err
 			}
-			return startScanner(namespace, clusterWide, scanner)
+			healthChecker.SetReady(true)
+
+			runScan := func() error {
+				policyReportStore.Reset()
+				scanner.ResetErrors()
+				healthChecker.ScanStarted()
+				defer healthChecker.ScanFinished()
+
+				scanCtx := ctx
+				if scanTimeout > 0 {
+					var scanCancel context.CancelFunc
+					scanCtx, scanCancel = context.WithTimeout(ctx, scanTimeout)
+					defer scanCancel()
+				}
+
+				log.Info().Dict("dict", zerolog.Dict().
+					Str("version", version.Version).
+					Str("commit", version.Commit).
+					Str("build-date", version.BuildDate),
+				).Msg("audit scan started")
+				runUID, err := startCheckpoint(scanner, resumeFrom)
+				if err != nil {
+					return err
+				}
+				scanErr := startScanner(scanCtx, runUID, namespace, clusterWide || includeClusterWide, scanner)
+				if scanErr == nil {
+					if err := scanner.FinishCheckpoint(); err != nil {
+						return err
+					}
+				}
+				logScanSummary(policyReportStore.ScanSummary(), quiet)
+				// the errors are written even when the scan failed, to tell what went wrong
+				if errorsFile != "" {
+					if err := writeScanErrors(scanner.Errors(), errorsFile); err != nil {
+						return err
+					}
+				}
+				timedOut := scanErr != nil && errors.Is(scanCtx.Err(), context.DeadlineExceeded)
+				if scanErr != nil && !timedOut {
+					return scanErr
+				}
+				if err := policyReportStore.StreamErr(); err != nil {
+					return fmt.Errorf("cannot stream the reports: %w", err)
+				}
+				// the reports gathered so far are written even when the scan timed out
+				if writeReports && !streamReports {
+					if err := writeScanResults(policyReportStore, outputFile, report.OutputFormat(outputFormat)); err != nil {
+						return err
+					}
+				}
+				// the summary of a scan that timed out would count only some resources
+				if summaryReport && !timedOut {
+					if err := policyReportStore.CreateOrPatchSummaryReport(ctx, runUID); err != nil {
+						return fmt.Errorf("cannot write the summary report: %w", err)
+					}
+				}
+				if policySummaryFile != "" {
+					if err := writePolicySummaries(policyReportStore, policySummaryFile); err != nil {
+						return err
+					}
+				}
+				if s3Exporter != nil {
+					if _, err := s3Exporter.Export(ctx, policyReportStore); err != nil {
+						return err
+					}
+				}
+				if slackNotifier != nil {
+					if err := slackNotifier.Notify(ctx, policyReportStore.GetFailedResults()); err != nil {
+						return err
+					}
+				}
+				return scanExitError(scanErr, scanTimeout, timedOut, policyReportStore.Summary().Fail, failOnViolation, violationExitCode)
+			}
+
+			// the signals stop the wait, the scan isn't started then
+			jitterCtx, stopJitter := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+			err = waitStartupJitter(jitterCtx, startupJitter)
+			stopJitter()
+			if err != nil {
+				return err
+			}
+
+			if !watchMode {
+				return runScan()
+			}
+
+			// the signals stop the periodic scans, the scan in progress is completed
+			stopCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			go func() {
+				// no new scans are started once stopping
+				<-stopCtx.Done()
+				healthChecker.SetReady(false)
+			}()
+			watch(stopCtx, watchInterval, runScan)
+			return nil
 		},
 	}
 
@@ -145,23 +833,114 @@ There will be a ClusterPolicyReport with results for cluster-wide resources.`,
 	rootCmd.SilenceErrors = true
 	rootCmd.SilenceUsage = true
 
-	rootCmd.Flags().StringP("namespace", "n", "", "namespace to be evaluated")
-	rootCmd.Flags().BoolP("cluster", "c", false, "scan cluster wide resources")
+	rootCmd.Flags().String("config", "", "YAML file setting the options of the scan, as a map from the names of the flags, without the leading dashes, to their values, e.g. 'namespace-selector: team=payments'. The flags that can be repeated take lists. The flags given on the command line override the file")
+	rootCmd.Flags().Bool("include-cluster-wide", false, "with --namespace, scan the cluster wide resources too, e.g. Namespaces and ClusterRoles")
+	rootCmd.Flags().StringP("namespace", "n", "", "namespace to be evaluated, skipping the other namespaces and, without --include-cluster-wide, the cluster wide resources")
+	rootCmd.Flags().BoolP("cluster", "c", false, "scan only the cluster wide resources, e.g. Namespaces, skipping the namespaced ones. The cluster wide resources and all the namespaces are scanned when neither --cluster nor --namespace is given. Can be given as --cluster-wide-only too")
+	rootCmd.Flags().String("namespace-selector", "", "label selector restricting the namespaces to be evaluated, e.g. team=payments. Cannot be used together with --namespace")
+	rootCmd.Flags().Bool("skip-owned-resources", false, "skip the resources controlled by another one, e.g. the Pods of a ReplicaSet or the ReplicaSets of a Deployment, so that only the top-level objects are evaluated. The reports of previous scans are kept")
+	rootCmd.Flags().StringSlice("skip-owner-kind", nil, "with --skip-owned-resources, skip only the resources controlled by a resource of this kind, e.g. ReplicaSet. This flag can be repeated. The resources of all the controllers are skipped when empty")
+	rootCmd.Flags().Int("sample-size", 0, "audit only the first N resources of every type, in every namespace, for a quick spot check. The reports written are labeled with kubewarden.io/policyreport-sample-size, and the ones of previous scans are kept. All the resources are audited when 0")
+	rootCmd.Flags().String("audit-operation", string(admissionv1.Create), fmt.Sprintf("operation of the AdmissionRequests auditing the resources. With UPDATE, the policies with rules for UPDATE are evaluated instead of the ones for CREATE, and the old object of the requests is the resource itself. Supported values are: %v", scanner.SupportedAdmissionOperations()))
+	rootCmd.Flags().String("admission-api-version", admissionv1.SchemeGroupVersion.String(), fmt.Sprintf("API version of the AdmissionReviews sent to the PolicyServers, and expected back from them. Use admission.k8s.io/v1beta1 for the PolicyServers and policies expecting the older one. Supported values are: %v", scanner.SupportedAdmissionAPIVersions()))
+	rootCmd.Flags().String("audit-user", defaultAuditUser, "user of the AdmissionRequests auditing the resources, evaluated by the policies making decisions on the requesting user. The user info of the requests is empty when empty")
+	rootCmd.Flags().StringSlice("audit-group", defaultAuditGroups, "group of the user of the AdmissionRequests auditing the resources. This flag can be repeated")
+	rootCmd.Flags().StringArray("redact-path", nil, "JSONPath, optionally prefixed by a kind, of a field removed from the resources before they are sent to the PolicyServers, e.g. 'Secret:.data'. Only fields and all the items of lists, with [*], can be selected. This flag can be repeated")
+	rootCmd.Flags().StringArray("skip-namespace-regex", nil, "regular expression matching the whole name of namespaces to be skipped from scan, e.g. 'pr-.*'. This flag can be repeated")
+	rootCmd.Flags().String("resource-selector", "", "label selector restricting the resources to be evaluated, e.g. app=frontend. It applies to both namespaced and cluster wide resources. The reports of previous scans are kept")
+	rootCmd.Flags().String("field-selector", "", "field selector restricting the resources to be evaluated, e.g. status.phase=Running. Resource types not supporting the selected fields are skipped. The reports of previous scans are kept")
+	rootCmd.Flags().String("mode-filter", string(policies.ModeFilterBoth), fmt.Sprintf("evaluate only the policies in this mode, the other policies are skipped. Supported values are: %v", policies.SupportedModeFilters()))
+	rootCmd.Flags().StringSlice("include-kind", nil, "kind of the resources to be evaluated, in the form <kind>[.<group>], e.g. Deployment or Deployment.apps. The resources of the other kinds are not fetched. This flag can be repeated. The reports of previous scans are kept. All the kinds are evaluated when empty")
+	rootCmd.Flags().StringSlice("exclude-kind", nil, "kind of the resources never evaluated, in the form <kind>[.<group>], e.g. Event. It takes precedence over --include-kind. This flag can be repeated. The reports of previous scans are kept")
+	rootCmd.Flags().StringSlice("extra-gvr", nil, "resource evaluated by the policies whose rules match it, in the form <group>/<version>/<resource>, or <version>/<resource> for the core group, e.g. example.com/v1/widgets. It makes the policies with wildcard rules, which are skipped otherwise, evaluate the resource. This flag can be repeated")
+	rootCmd.Flags().StringSlice("policy", nil, "name of a policy to be evaluated, the other policies are skipped. This flag can be repeated. The reports of previous scans are kept. All the policies are evaluated when empty")
+	rootCmd.Flags().String("min-severity", "", fmt.Sprintf("report only the results of policies with at least this severity. The results of policies in monitor mode have the info severity. Supported values are: %v. All the results are reported when empty", report.SupportedSeverities()))
+	rootCmd.Flags().Bool("include-unset-severity", false, "with --min-severity, report also the results of policies without a severity")
 	rootCmd.Flags().StringP("kubewarden-namespace", "k", defaultKubewardenNamespace, "namespace where the Kubewarden components (e.g. PolicyServer) are installed (required)")
 	rootCmd.Flags().StringP("policy-server-url", "u", "", "URI to the PolicyServers the Audit Scanner will query. Example: https://localhost:3000. Useful for out-of-cluster debugging")
+	rootCmd.Flags().String("policy-server-name", "", "name of the PolicyServer reached at --policy-server-url. The policies run by the other PolicyServers are sent to their in-cluster Services. --policy-server-url is used for all the PolicyServers when empty")
+	rootCmd.Flags().Bool("policy-server-allow-http", false, "allow --policy-server-url to use the http scheme, sending the resources to the PolicyServers unencrypted")
 	rootCmd.Flags().VarP(&level, "loglevel", "l", fmt.Sprintf("level of the logs. Supported values are: %v", logconfig.GetSupportedValues()))
+	rootCmd.Flags().Var(&logFormat, "log-format", fmt.Sprintf("encoder of the logs written to stderr. Supported values are: %v", logconfig.GetSupportedFormats()))
+	rootCmd.Flags().BoolP("quiet", "q", false, "log only the warnings, the errors and the summary of every scan, whatever --loglevel")
 	rootCmd.Flags().BoolVarP(&outputScan, "output-scan", "o", false, "print result of scan in JSON to stdout")
-	rootCmd.Flags().StringSliceVarP(&skippedNs, "ignore-namespaces", "i", nil, "comma separated list of namespace names to be skipped from scan. This flag can be repeated")
-	rootCmd.Flags().BoolVar(&insecureSSL, "insecure-ssl", false, "skip SSL cert validation when connecting to PolicyServers endpoints. Useful for development")
-	rootCmd.Flags().StringP("extra-ca", "f", "", "File path to CA cert in PEM format of PolicyServer endpoints")
-	rootCmd.Flags().StringP("client-cert", "", "", "File path to client cert in PEM format used for mTLS communication with the PolicyServer endpoints")
-	rootCmd.Flags().StringP("client-key", "", "", "File path to client key in PEM format used for mTLS communication with the PolicyServer endpoints")
+	rootCmd.Flags().StringVar(&outputFile, "output-file", "", "write all the PolicyReports and ClusterPolicyReports of the scan to this file using --output-format, replacing it if it exists")
+	rootCmd.Flags().String("output-format", string(report.OutputFormatJSON), fmt.Sprintf("format of the scan results written to --output-file, or to stdout when no file is given. With jsonl, every report is written as soon as it is computed. Supported values are: %v", report.SupportedOutputFormats()))
+	rootCmd.Flags().StringSliceVarP(&skippedNs, "ignore-namespaces", "i", nil, "comma separated list of namespace names to be skipped from scan. This flag can be repeated. Can be given as --skip-namespace too")
+	rootCmd.Flags().BoolVar(&insecureSSL, "insecure-ssl", false, "skip SSL cert validation when connecting to PolicyServers endpoints. Useful for development. Requires --accept-insecure-tls")
+	rootCmd.Flags().Bool("accept-insecure-tls", false, "acknowledge that --insecure-ssl makes the scanner trust any certificate presented by the PolicyServers. The scanner refuses to start with --insecure-ssl alone")
+	rootCmd.Flags().StringP("extra-ca", "f", "", "File path to CA cert in PEM format of PolicyServer endpoints. The file is read again when it changes, to support CA rotation")
+	rootCmd.Flags().String("ca-cert-dir", "", "directory whose .crt and .pem files contain CA certs in PEM format of PolicyServer endpoints, e.g. when they use different issuing CAs. The files that cannot be parsed are skipped")
+	rootCmd.Flags().StringP("client-cert", "", "", "File path to client cert in PEM format used for mTLS communication with the PolicyServer endpoints. Can be given as --client-cert-file too")
+	rootCmd.Flags().StringP("client-key", "", "", "File path to client key in PEM format used for mTLS communication with the PolicyServer endpoints. Can be given as --client-key-file too")
 	rootCmd.MarkFlagsRequiredTogether("client-cert", "client-key")
+	rootCmd.Flags().SetNormalizeFunc(normalizeFlagAliases)
 	rootCmd.Flags().BoolVar(&disableStore, "disable-store", false, "disable storing the results in the k8s cluster")
-	rootCmd.Flags().IntP("parallel-namespaces", "", defaultParallelNamespaces, "number of Namespaces to scan in parallel")
+	rootCmd.Flags().String("apply-mode", string(report.ApplyModeClientSide), fmt.Sprintf("how the reports are written to the k8s cluster. With server-side the API server merges the reports written by concurrent scans. Supported values are: %v", report.SupportedApplyModes()))
+	rootCmd.Flags().Bool("dry-run", false, "log the PolicyReports and ClusterPolicyReports that would be written to the k8s cluster, without creating, patching or deleting any of them")
+	rootCmd.Flags().Bool("record-timings", false, "add to every result the round-trip time, in milliseconds, of the request to the PolicyServer evaluating the policy, as the evaluation-duration-ms property. The retry backoffs and the rate limiting waits aren't counted, and the cached verdicts have no timing")
+	rootCmd.Flags().Bool("keep-old-reports", false, "keep the reports written by previous scans, instead of deleting the ones not updated by this scan. Reports of deleted resources are still garbage collected by Kubernetes")
+	rootCmd.Flags().Int("report-history", 0, fmt.Sprintf("number of scans whose summary is kept in the kubewarden.io/policyreport-history annotation of every report, from the oldest to the newest, to follow the trend of the results. At most %d scans are kept, to stay far below the size limit of the objects. The history is disabled when 0", report.MaxReportHistory))
+	rootCmd.Flags().Bool("only-failed", false, "write to the k8s cluster only the failing and errored results of the reports, to bound their size on clusters with many compliant resources. The summaries of the reports still count all the results, and --output-file gets all of them")
+	rootCmd.Flags().Bool("prune-stale-reports", false, "after scanning all the namespaces, delete the PolicyReports created by the audit scanner in the namespaces that no longer exist or are being deleted. The reports of namespaces that still exist are kept, even when excluded from the scan. PolicyReports created by others are kept")
+	rootCmd.Flags().String("resource-namespace-override", "", "namespace where a copy of the results of every ClusterPolicyReport is written as a PolicyReport too, for the users allowed to read only the namespaced reports. The copies are labeled with kubewarden.io/policyreport-mirror and aren't counted in the summary of the scan. Nothing is copied when empty")
+	rootCmd.Flags().String("s3-bucket", "", "bucket of an S3-compatible object store where the PolicyReports and ClusterPolicyReports of every scan are uploaded as a JSON document, in addition to being stored in the k8s cluster. The upload is disabled when empty")
+	rootCmd.Flags().String("s3-endpoint", defaultS3Endpoint, "host and optional port of the S3-compatible object store")
+	rootCmd.Flags().String("s3-region", "", "region of the --s3-bucket. It's discovered when empty")
+	rootCmd.Flags().String("s3-prefix", "", "prefix of the names of the uploaded objects, which are named <prefix>/<cluster-name>/<timestamp>.json")
+	rootCmd.Flags().String("s3-cluster-name", "", "name of the scanned cluster, used in the names of the uploaded objects")
+	rootCmd.Flags().String("s3-credentials-file", "", "AWS shared credentials file with the credentials of the object store. When empty, the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY environment variables are used")
+	rootCmd.Flags().Bool("s3-insecure", false, "connect to the object store with plain HTTP instead of HTTPS")
+	rootCmd.Flags().String("slack-webhook-url", "", "URL of a Slack incoming webhook the most severe failing results of every scan are posted to. Nothing is posted when empty")
+	rootCmd.Flags().Int("slack-max-results", defaultSlackMaxResults, "maximum number of failing results posted to Slack after every scan")
+	rootCmd.Flags().String("slack-min-severity", "", fmt.Sprintf("post to Slack only the failing results of policies with at least this severity, as with --min-severity. Supported values are: %v. All the failing results are posted when empty", report.SupportedSeverities()))
+	rootCmd.Flags().IntP("parallel-namespaces", "", defaultParallelNamespaces, "number of Namespaces to scan in parallel. Can be given as --namespace-concurrency too")
 	rootCmd.Flags().IntP("parallel-resources", "", defaultParallelResources, "number of resources to scan in parallel")
 	rootCmd.Flags().IntP("parallel-policies", "", defaultParallelPolicies, "number of policies to evaluate for a given resource in parallel")
-	rootCmd.Flags().IntP("page-size", "", defaultPageSize, "number of resources to fetch from the Kubernetes API server when paginating")
+	rootCmd.Flags().Bool("cache-verdicts", false, "reuse the verdict of a policy on a resource for the resources with the same content, e.g. the ones unchanged since the previous scan with --watch, instead of sending them again to the PolicyServers. The name is part of the content, since the policies can evaluate it, hence the resources with generated names, like the Pods of a ReplicaSet, don't share their verdicts within a scan. A verdict is dropped when the policy changes. The verdicts of the context aware policies, reading other resources of the cluster, are never cached")
+	rootCmd.Flags().Duration("verdict-cache-ttl", defaultVerdictCacheTTL, "with --cache-verdicts, time the verdicts of the policies are reused before the resources are sent again to the PolicyServers")
+	rootCmd.Flags().Duration("namespace-cache-ttl", defaultNamespaceCacheTTL, "time the namespaces fetched from the Kubernetes API server are reused before being fetched again. The cache is disabled when 0")
+	rootCmd.Flags().String("user-agent", version.UserAgent(), "User-Agent header of the requests sent to the Kubernetes API server and to the PolicyServers")
+	rootCmd.Flags().String("kubeconfig", "", "path of the kubeconfig file used to connect to the cluster. $KUBECONFIG, ~/.kube/config or the in-cluster configuration are used when empty")
+	rootCmd.Flags().String("kube-context", "", "context of the kubeconfig used to connect to the cluster, instead of the current one")
+	rootCmd.Flags().Float32("kube-api-qps", defaultKubeAPIQPS, "maximum number of requests per second sent to the Kubernetes API server")
+	rootCmd.Flags().Int("kube-api-burst", defaultKubeAPIBurst, "maximum number of requests sent at once to the Kubernetes API server")
+	rootCmd.Flags().IntP("page-size", "", defaultPageSize, "number of resources to fetch from the Kubernetes API server when paginating. Larger pages mean fewer API calls but bigger responses and more memory")
+	rootCmd.Flags().Duration("scan-timeout", 0, fmt.Sprintf("maximum duration of the whole scan, e.g. 30m. When it expires the scan stops, the reports gathered so far are written and the process exits with code %d. No timeout when 0", exitCodeScanTimeout))
+	rootCmd.Flags().Duration("startup-jitter", 0, "wait a random time up to this duration, e.g. 5m, before starting the scan, so that the scanners scheduled at the same time, e.g. by the CronJobs of many clusters, don't load shared PolicyServers all together. SIGINT and SIGTERM interrupt the wait. No wait when 0")
+	rootCmd.Flags().Bool("watch", false, "keep running and scan again every --interval, instead of exiting after the first scan. SIGINT and SIGTERM stop the scanner once the scan in progress completes")
+	rootCmd.Flags().Duration("interval", defaultWatchInterval, "with --watch, time between the start of two scans. A scan is skipped when the previous one is still running")
+	rootCmd.Flags().Bool("fail-on-violation", false, "exit with --violation-exit-code when the scan finds resources violating policies. Useful to use the scanner as a gate in CI")
+	rootCmd.Flags().Int("violation-exit-code", defaultExitCodeViolation, fmt.Sprintf("exit code used with --fail-on-violation when violations are found. It must differ from %d, used for operational errors, and %d, used when the scan times out", exitCodeError, exitCodeScanTimeout))
+	rootCmd.MarkFlagsMutuallyExclusive("watch", "fail-on-violation")
+	rootCmd.Flags().String("checkpoint-file", "", "record the progress of the scan in this file, to resume it with --resume-from when interrupted. The file is removed once the scan completes. Defaults to --resume-from")
+	rootCmd.Flags().String("resume-from", "", "resume the interrupted scan recorded in this checkpoint file, skipping the namespaces and the resource types already audited. A new scan is started when the file doesn't exist")
+	rootCmd.Flags().Bool("summary-report", false, fmt.Sprintf("write at the end of every scan the ClusterPolicyReport %s, holding a result for every policy with the number of resources that passed, failed, warned, errored or were skipped by it, so that dashboards can read a single object. Only for the scans of the whole cluster, it cannot be used together with --resume-from or with the flags restricting the resources audited or the policies evaluated, i.e. all the filters recorded in the reports but --extra-gvr and --only-failed", report.SummaryReportName))
+	rootCmd.Flags().String("policy-summary-file", "", "write the number of resources that passed, failed, warned, errored or were skipped by every policy, across all the namespaces and the cluster wide resources, to this file as a JSON array")
+	rootCmd.Flags().String("errors-file", "", "write the errors hit during the scan, e.g. PolicyServers not reachable or reports that could not be saved, to this file as a JSON array. The scan logs these errors and goes on")
+	rootCmd.Flags().String("metrics-address", "", "address, e.g. :8080, where Prometheus metrics about the scan are served on /metrics while the scan runs. Metrics are disabled when empty")
+	rootCmd.Flags().String("health-address", "", "address, e.g. :8081, where the liveness and readiness probes are served on /healthz and /readyz. Useful with --watch. The probes are disabled when empty")
+	rootCmd.Flags().Duration("health-stall-timeout", defaultHealthStallTimeout, "with --health-address, /healthz fails when a scan makes no progress, i.e. fetches no page of resources, for longer than this, because the scanner is considered stuck. Long scans are healthy as long as they make progress. Scans are never considered stuck when 0")
+	rootCmd.Flags().String("otel-endpoint", "", "URL of the OTLP/HTTP endpoint traces are exported to, e.g. http://otel-collector:4318. Tracing is disabled when empty")
+	rootCmd.Flags().Int("policy-server-max-retries", defaultPolicyServerRetries, "number of times a request to the PolicyServers failed because of a network error, a 5xx or a 429 status code is retried")
+	rootCmd.Flags().Duration("policy-server-retry-backoff", defaultPolicyServerBackoff, "initial time to wait before retrying a failed request to the PolicyServers. It doubles at every attempt, with jitter")
+	rootCmd.Flags().Float64("policy-server-qps", 0, "maximum number of requests per second sent to all the PolicyServers, shared by the parallel audits. Requests are not rate limited when 0")
+	rootCmd.Flags().Bool("compress-requests", false, "send the AdmissionReviews bigger than 4KiB gzipped, with the Content-Encoding: gzip header, to save bandwidth to remote PolicyServers. The PolicyServers, or the proxies in front of them, must accept compressed requests")
+	rootCmd.Flags().Int("max-resource-bytes", 0, "maximum size, in bytes, of the AdmissionReview of a resource sent to the PolicyServers, e.g. to stay below their request body limit. The bigger resources are not sent and their results are errored. The size is not limited when 0")
+	rootCmd.Flags().Int("policy-server-burst", defaultPolicyServerBurst, "with --policy-server-qps, maximum number of requests sent to the PolicyServers at once")
+	rootCmd.Flags().String("policy-server-token", "", "bearer token sent in the Authorization header of the requests to the PolicyServers")
+	rootCmd.Flags().String("policy-server-token-file", "", "file containing the bearer token sent in the Authorization header of the requests to the PolicyServers. The file is read again when it changes, to support token rotation")
+	rootCmd.MarkFlagsMutuallyExclusive("policy-server-token", "policy-server-token-file")
+	rootCmd.Flags().Bool("policy-server-keep-alive", false, "reuse the connections to the PolicyServers, instead of opening a new connection for every request. Reused connections send all the requests to the same PolicyServer replica")
+	rootCmd.Flags().Int("policy-server-max-idle-conns", defaultPolicyServerMaxIdleConns, "with --policy-server-keep-alive, maximum number of idle connections kept across all the PolicyServers")
+	rootCmd.Flags().Int("policy-server-max-idle-conns-per-host", defaultPolicyServerMaxIdleConnsPerHost, "with --policy-server-keep-alive, maximum number of idle connections kept for every PolicyServer")
+	rootCmd.Flags().Duration("policy-server-idle-conn-timeout", defaultPolicyServerIdleConnTimeout, "with --policy-server-keep-alive, time an idle connection to a PolicyServer is kept before being closed")
+	rootCmd.Flags().Duration("policy-server-timeout", defaultPolicyServerTimeout, "timeout of each request sent to the PolicyServers. Accepts Go duration strings, e.g. 30s or 2m")
+
+	rootCmd.AddCommand(newVersionCommand())
+	rootCmd.AddCommand(newPreflightCommand(rootCmd, &level, &logFormat))
+	// the scanner is not meant to be used interactively
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
 
 	return rootCmd
 }
@@ -170,30 +949,611 @@ There will be a ClusterPolicyReport with results for cluster-wide resources.`,
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute(rootCmd *cobra.Command) {
 	if err := rootCmd.Execute(); err != nil {
-		log.Fatal().Err(err).Msg("Error on cmd.Execute()")
+		code := exitCode(err)
+		log.Error().Err(err).Int("exit-code", code).Msg("Error on cmd.Execute()")
+		os.Exit(code)
+	}
+}
+
+// logScanSummary logs the totals of the scan, they match the ones of the written reports.
+// With --quiet the summary is logged without level, so that it's not filtered out.
+func logScanSummary(summary report.ScanSummary, quiet bool) {
+	event := log.Info()
+	if quiet {
+		event = log.Log()
+	}
+	event.Dict("dict", zerolog.Dict().
+		Int("namespaces", summary.Namespaces).
+		Int("resources", summary.Resources).
+		Int("policy-evaluations", summary.PolicyEvaluations).
+		Int("pass", summary.Pass).
+		Int("fail", summary.Fail).
+		Int("warn", summary.Warn).
+		Int("error", summary.Error).
+		Int("skip", summary.Skip).
+		Int("sample-size", summary.SampleSize),
+	).Msg("scan summary")
+}
+
+// normalizeFlagAliases maps the alternative names of some flags to their canonical name.
+func normalizeFlagAliases(_ *pflag.FlagSet, name string) pflag.NormalizedName {
+	switch name {
+	case "cluster-wide-only":
+		name = "cluster"
+	case "client-cert-file":
+		name = "client-cert"
+	case "client-key-file":
+		name = "client-key"
+	case "skip-namespace":
+		name = "ignore-namespaces"
+	case "namespace-concurrency":
+		name = "parallel-namespaces"
+	}
+
+	return pflag.NormalizedName(name)
+}
+
+// writeScanResults writes the reports retained by the store to outputFile, or
+// to stdout when no file is given.
+func writeScanResults(store *report.PolicyReportStore, outputFile string, format report.OutputFormat) error {
+	if outputFile == "" {
+		return store.Write(os.Stdout, format)
+	}
+	if err := store.WriteFile(outputFile, format); err != nil {
+		return err
+	}
+	log.Info().Str("output-file", outputFile).Str("output-format", string(format)).Msg("Scan results written")
+
+	return nil
+}
+
+// writePolicySummaries writes the totals of the results of every policy to
+// policySummaryFile.
+func writePolicySummaries(store *report.PolicyReportStore, policySummaryFile string) error {
+	if err := store.WritePolicySummariesFile(policySummaryFile); err != nil {
+		return err
+	}
+	log.Info().Str("policy-summary-file", policySummaryFile).Msg("Policy summaries written")
+
+	return nil
+}
+
+// newRESTConfig returns the configuration used to connect to the Kubernetes
+// API server, built from the --kubeconfig, --kube-context, --kube-api-qps,
+// --kube-api-burst and --user-agent flags.
+func newRESTConfig(cmd *cobra.Command) (*rest.Config, error) {
+	kubeAPIQPS, err := cmd.Flags().GetFloat32("kube-api-qps")
+	if err != nil {
+		return nil, err
+	}
+	if kubeAPIQPS <= 0 {
+		return nil, errors.New("--kube-api-qps must be positive")
+	}
+	kubeAPIBurst, err := cmd.Flags().GetInt("kube-api-burst")
+	if err != nil {
+		return nil, err
+	}
+	if kubeAPIBurst < 1 {
+		return nil, errors.New("--kube-api-burst must be at least 1")
+	}
+	userAgent, err := cmd.Flags().GetString("user-agent")
+	if err != nil {
+		return nil, err
 	}
+	kubeconfig, err := cmd.Flags().GetString("kubeconfig")
+	if err != nil {
+		return nil, err
+	}
+	kubeContext, err := cmd.Flags().GetString("kube-context")
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := k8s.LoadRESTConfig(kubeconfig, kubeContext)
+	if err != nil {
+		return nil, err
+	}
+	// the scan lists many resource types in many namespaces, the
+	// client-side rate limiter must not be the bottleneck
+	config.QPS = kubeAPIQPS
+	config.Burst = kubeAPIBurst
+	config.UserAgent = userAgent
+
+	return config, nil
 }
 
-func startScanner(namespace string, clusterWide bool, scanner *scanner.Scanner) error {
-	if clusterWide && namespace != "" {
-		log.Fatal().Msg("Cannot scan cluster wide and only a namespace at the same time")
+// newPoliciesClient returns the client fetching the policies to be evaluated,
+// built from the --kubewarden-namespace, --policy-server-url,
+// --policy-server-allow-http, --policy-server-name and --mode-filter flags.
+// The policy names, the kinds, the extra resources and the operation are the
+// ones already parsed by the caller from the --policy, --include-kind,
+// --exclude-kind, --extra-gvr and --audit-operation flags.
+func newPoliciesClient(cmd *cobra.Command, client client.Client, policyNames []string, includedKinds, excludedKinds []schema.GroupKind, extraGVRs []schema.GroupVersionResource, operation admissionv1.Operation) (*policies.Client, error) {
+	kubewardenNamespace, err := cmd.Flags().GetString("kubewarden-namespace")
+	if err != nil {
+		return nil, err
+	}
+	policyServerURLFlag, err := cmd.Flags().GetString("policy-server-url")
+	if err != nil {
+		return nil, err
+	}
+	policyServerAllowHTTP, err := cmd.Flags().GetBool("policy-server-allow-http")
+	if err != nil {
+		return nil, err
+	}
+	policyServerURL, err := parsePolicyServerURL(policyServerURLFlag, policyServerAllowHTTP)
+	if err != nil {
+		return nil, err
+	}
+	policyServerName, err := cmd.Flags().GetString("policy-server-name")
+	if err != nil {
+		return nil, err
+	}
+	if policyServerName != "" && policyServerURL == "" {
+		return nil, errors.New("--policy-server-name requires --policy-server-url")
+	}
+	modeFilter, err := cmd.Flags().GetString("mode-filter")
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(policies.SupportedModeFilters(), policies.ModeFilter(modeFilter)) {
+		return nil, fmt.Errorf("unsupported --mode-filter %q, supported values are: %v", modeFilter, policies.SupportedModeFilters())
+	}
+
+	return policies.NewClient(client, kubewardenNamespace, policyServerURL,
+		policies.WithPolicyNames(policyNames...),
+		policies.WithPolicyServerName(policyServerName),
+		policies.WithModeFilter(policies.ModeFilter(modeFilter)),
+		policies.WithKindFilter(includedKinds, excludedKinds),
+		policies.WithExtraGVRs(extraGVRs...),
+		policies.WithOperation(admissionregistrationv1.OperationType(operation)),
+	)
+}
+
+// newAdmissionConfig returns how the AdmissionRequests auditing the resources
+// are framed, built from the --audit-operation, --audit-user and --audit-group flags.
+func newAdmissionConfig(cmd *cobra.Command) (scanner.AdmissionConfig, error) {
+	operation, err := cmd.Flags().GetString("audit-operation")
+	if err != nil {
+		return scanner.AdmissionConfig{}, err
+	}
+	if !slices.Contains(scanner.SupportedAdmissionOperations(), admissionv1.Operation(operation)) {
+		return scanner.AdmissionConfig{}, fmt.Errorf("unsupported --audit-operation %q, supported values are: %v", operation, scanner.SupportedAdmissionOperations())
+	}
+	user, err := cmd.Flags().GetString("audit-user")
+	if err != nil {
+		return scanner.AdmissionConfig{}, err
+	}
+	groups, err := cmd.Flags().GetStringSlice("audit-group")
+	if err != nil {
+		return scanner.AdmissionConfig{}, err
+	}
+	if user == "" && len(groups) > 0 {
+		return scanner.AdmissionConfig{}, errors.New("--audit-group requires --audit-user")
+	}
+	apiVersion, err := cmd.Flags().GetString("admission-api-version")
+	if err != nil {
+		return scanner.AdmissionConfig{}, err
+	}
+	if !slices.Contains(scanner.SupportedAdmissionAPIVersions(), apiVersion) {
+		return scanner.AdmissionConfig{}, fmt.Errorf("unsupported --admission-api-version %q, supported values are: %v", apiVersion, scanner.SupportedAdmissionAPIVersions())
+	}
+
+	return scanner.AdmissionConfig{
+		Operation:  admissionv1.Operation(operation),
+		UserInfo:   authenticationv1.UserInfo{Username: user, Groups: groups},
+		APIVersion: apiVersion,
+	}, nil
+}
+
+// newTLSConfig returns the configuration of the connections to the
+// PolicyServers, built from the --insecure-ssl, --accept-insecure-tls,
+// --extra-ca, --ca-cert-dir, --client-cert and --client-key flags.
+func newTLSConfig(cmd *cobra.Command) (scanner.TLSConfig, error) {
+	insecureSSL, err := cmd.Flags().GetBool("insecure-ssl")
+	if err != nil {
+		return scanner.TLSConfig{}, err
+	}
+	acceptInsecureTLS, err := cmd.Flags().GetBool("accept-insecure-tls")
+	if err != nil {
+		return scanner.TLSConfig{}, err
+	}
+	if insecureSSL {
+		// a scan trusting any certificate must never be started by mistake
+		if !acceptInsecureTLS {
+			return scanner.TLSConfig{}, errors.New("--insecure-ssl disables the validation of the PolicyServers certificates, it requires --accept-insecure-tls too")
+		}
+		log.Warn().Str("flag", "--insecure-ssl").
+			Msg("connecting to PolicyServers endpoints without validating TLS connection")
+	}
+	caFile, err := cmd.Flags().GetString("extra-ca")
+	if err != nil {
+		return scanner.TLSConfig{}, err
+	}
+	caDir, err := cmd.Flags().GetString("ca-cert-dir")
+	if err != nil {
+		return scanner.TLSConfig{}, err
+	}
+	clientCertFile, err := cmd.Flags().GetString("client-cert")
+	if err != nil {
+		return scanner.TLSConfig{}, err
+	}
+	clientKeyFile, err := cmd.Flags().GetString("client-key")
+	if err != nil {
+		return scanner.TLSConfig{}, err
+	}
+
+	return scanner.TLSConfig{
+		Insecure:       insecureSSL,
+		CAFile:         caFile,
+		ClientCertFile: clientCertFile,
+		ClientKeyFile:  clientKeyFile,
+		CADir:          caDir,
+	}, nil
+}
+
+// writeScanErrors writes the non-fatal errors of the scan to errorsFile as a
+// JSON array, replacing the file if it exists. The array is empty when the
+// scan completed without errors.
+func writeScanErrors(scanErrors []scanner.ScanError, errorsFile string) error {
+	if scanErrors == nil {
+		scanErrors = []scanner.ScanError{}
+	}
+	data, err := json.MarshalIndent(scanErrors, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot serialize the scan errors: %w", err)
+	}
+	err = report.WriteFileAtomically(errorsFile, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("cannot write %s: %w", errorsFile, err)
+	}
+	log.Info().Str("errors-file", errorsFile).Int("errors", len(scanErrors)).Msg("Scan errors written")
+
+	return nil
+}
+
+// newS3Exporter returns the exporter uploading the results of the scans to the
+// bucket given with --s3-bucket, or nil when no bucket is given.
+func newS3Exporter(cmd *cobra.Command) (*export.S3Exporter, error) {
+	bucket, err := cmd.Flags().GetString("s3-bucket")
+	if err != nil {
+		return nil, err
+	}
+	if bucket == "" {
+		return nil, nil //nolint:nilnil // the upload is disabled
+	}
+	endpoint, err := cmd.Flags().GetString("s3-endpoint")
+	if err != nil {
+		return nil, err
+	}
+	region, err := cmd.Flags().GetString("s3-region")
+	if err != nil {
+		return nil, err
+	}
+	prefix, err := cmd.Flags().GetString("s3-prefix")
+	if err != nil {
+		return nil, err
+	}
+	clusterName, err := cmd.Flags().GetString("s3-cluster-name")
+	if err != nil {
+		return nil, err
+	}
+	credentialsFile, err := cmd.Flags().GetString("s3-credentials-file")
+	if err != nil {
+		return nil, err
+	}
+	insecure, err := cmd.Flags().GetBool("s3-insecure")
+	if err != nil {
+		return nil, err
+	}
+
+	return export.NewS3Exporter(export.S3Config{
+		Endpoint:        endpoint,
+		Insecure:        insecure,
+		Region:          region,
+		Bucket:          bucket,
+		Prefix:          prefix,
+		ClusterName:     clusterName,
+		CredentialsFile: credentialsFile,
+	})
+}
+
+// newSlackNotifier returns the notifier posting the failing results of the
+// scans to the webhook given with --slack-webhook-url, or nil when no webhook
+// is given.
+func newSlackNotifier(cmd *cobra.Command) (*notify.SlackNotifier, error) {
+	webhookURL, err := cmd.Flags().GetString("slack-webhook-url")
+	if err != nil {
+		return nil, err
+	}
+	if webhookURL == "" {
+		return nil, nil //nolint:nilnil // the notifications are disabled
+	}
+	maxResults, err := cmd.Flags().GetInt("slack-max-results")
+	if err != nil {
+		return nil, err
+	}
+	minSeverity, err := cmd.Flags().GetString("slack-min-severity")
+	if err != nil {
+		return nil, err
+	}
+	severityFilter, err := report.NewSeverityFilter(minSeverity, false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --slack-min-severity: %w", err)
+	}
+
+	return notify.NewSlackNotifier(webhookURL, maxResults, severityFilter)
+}
+
+// openReportStream opens the writer the reports are streamed to with the jsonl
+// format: outputFile, truncated if it exists, or stdout when no file is given.
+// The lines of the following scans are appended, when running with --watch.
+func openReportStream(outputFile string) (io.Writer, func(), error) {
+	if outputFile == "" {
+		return os.Stdout, func() {}, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(outputFile), outputDirPermissions); err != nil {
+		return nil, nil, fmt.Errorf("cannot create directory for %s: %w", outputFile, err)
+	}
+	file, err := os.Create(outputFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot create %s: %w", outputFile, err)
+	}
+	log.Info().Str("output-file", outputFile).Str("output-format", string(report.OutputFormatJSONLines)).Msg("Streaming scan results")
+
+	return file, func() {
+		if err := file.Close(); err != nil {
+			log.Error().Err(err).Str("output-file", outputFile).Msg("cannot close output file")
+		}
+	}, nil
+}
+
+// parseNamespaceSelector parses the label selector given with --namespace-selector.
+// An empty selector matches all the namespaces.
+func parseNamespaceSelector(selector string) (labels.Selector, error) {
+	if selector == "" {
+		return labels.Everything(), nil
+	}
+
+	labelSelector, err := metav1.ParseToLabelSelector(selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --namespace-selector %q: %w", selector, err)
+	}
+	namespaceSelector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --namespace-selector %q: %w", selector, err)
+	}
+
+	return namespaceSelector, nil
+}
+
+// parsePolicyServerURL validates the URL given with --policy-server-url and
+// removes its trailing slashes, as the paths of the policies are appended to it.
+// The URL must use the https scheme, unless allowHTTP is true.
+// An empty URL is returned as is: the PolicyServers are reached through their
+// Services.
+func parsePolicyServerURL(rawURL string, allowHTTP bool) (string, error) {
+	if rawURL == "" {
+		return "", nil
+	}
+
+	policyServerURL, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid --policy-server-url %q: %w", rawURL, err)
+	}
+	switch policyServerURL.Scheme {
+	case "https":
+	case "http":
+		if !allowHTTP {
+			return "", fmt.Errorf("invalid --policy-server-url %q: the http scheme requires --policy-server-allow-http", rawURL)
+		}
+	default:
+		return "", fmt.Errorf("invalid --policy-server-url %q: the URL must start with https://, e.g. https://localhost:3000", rawURL)
+	}
+	if policyServerURL.Host == "" {
+		return "", fmt.Errorf("invalid --policy-server-url %q: the URL must contain a host, e.g. https://localhost:3000", rawURL)
+	}
+	if policyServerURL.RawQuery != "" || policyServerURL.Fragment != "" {
+		return "", fmt.Errorf("invalid --policy-server-url %q: the URL cannot contain a query or a fragment", rawURL)
+	}
+	policyServerURL.Path = strings.TrimRight(policyServerURL.Path, "/")
+	policyServerURL.RawPath = ""
+
+	return policyServerURL.String(), nil
+}
+
+// parseSkipNamespaceRegexes compiles the regular expressions given with
+// --skip-namespace-regex. They are anchored, so that they match the whole
+// name of the namespaces.
+func parseSkipNamespaceRegexes(patterns []string) ([]*regexp.Regexp, error) {
+	regexes := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		// the pattern is compiled as given first, so that the errors don't show the anchors
+		if _, err := regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("invalid --skip-namespace-regex %q: %w", pattern, err)
+		}
+		regexes = append(regexes, regexp.MustCompile("^(?:"+pattern+")$"))
+	}
+
+	return regexes, nil
+}
+
+// kindFilter returns the kinds given with --include-kind and --exclude-kind.
+func kindFilter(cmd *cobra.Command) ([]schema.GroupKind, []schema.GroupKind, error) {
+	kinds := make([][]schema.GroupKind, 0, 2)
+	for _, flag := range []string{"include-kind", "exclude-kind"} {
+		values, err := cmd.Flags().GetStringSlice(flag)
+		if err != nil {
+			return nil, nil, err
+		}
+		parsed, err := parseKinds(flag, values)
+		if err != nil {
+			return nil, nil, err
+		}
+		kinds = append(kinds, parsed)
+	}
+
+	return kinds[0], kinds[1], nil
+}
+
+// parseKinds parses the kinds given with flag, in the form <kind>[.<group>],
+// e.g. Deployment or Deployment.apps.
+func parseKinds(flag string, values []string) ([]schema.GroupKind, error) {
+	kinds := make([]schema.GroupKind, 0, len(values))
+	for _, value := range values {
+		kind := schema.ParseGroupKind(value)
+		if kind.Kind == "" {
+			return nil, fmt.Errorf("invalid --%s %q: the kind cannot be empty", flag, value)
+		}
+		kinds = append(kinds, kind)
+	}
+
+	return kinds, nil
+}
+
+// partialScanFlag returns the first flag given auditing only part of the
+// resources of the cluster, or evaluating only part of the policies, so that
+// the totals of the summary report would be incomplete. It returns an empty
+// string when the scan covers the whole cluster. The flags given with their
+// default value, e.g. --mode-filter both, don't restrict the scan.
+func partialScanFlag(cmd *cobra.Command) string {
+	// the resumed scan skips what was audited before it was interrupted
+	names := append(slices.Clone(scanFilterFlags), "resume-from")
+	for _, name := range names {
+		if slices.Contains(fullScanFilterFlags, name) {
+			continue
+		}
+		if flag := cmd.Flags().Lookup(name); flag.Changed && flag.Value.String() != flag.DefValue {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// scanFilters returns the values of the scanFilterFlags given, keyed by flag name.
+func scanFilters(cmd *cobra.Command) map[string]string {
+	filters := map[string]string{}
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		if slices.Contains(scanFilterFlags, flag.Name) {
+			filters[flag.Name] = flag.Value.String()
+		}
+	})
+
+	return filters
+}
+
+// extraResources returns the resources given with --extra-gvr.
+func extraResources(cmd *cobra.Command) ([]schema.GroupVersionResource, error) {
+	values, err := cmd.Flags().GetStringSlice("extra-gvr")
+	if err != nil {
+		return nil, err
+	}
+	gvrs := make([]schema.GroupVersionResource, 0, len(values))
+	for _, value := range values {
+		gvr, err := parseGVR(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --extra-gvr %q: %w", value, err)
+		}
+		gvrs = append(gvrs, gvr)
+	}
+
+	return gvrs, nil
+}
+
+// parseGVR parses a resource in the form <group>/<version>/<resource>, or
+// <version>/<resource> for the core group.
+func parseGVR(value string) (schema.GroupVersionResource, error) {
+	parts := strings.Split(value, "/")
+	if len(parts) == 2 {
+		parts = append([]string{""}, parts...)
+	}
+	if len(parts) != 3 || parts[1] == "" || parts[2] == "" {
+		return schema.GroupVersionResource{}, errors.New("expected <group>/<version>/<resource> or <version>/<resource>")
+	}
+
+	return schema.GroupVersionResource{Group: parts[0], Version: parts[1], Resource: parts[2]}, nil
+}
+
+// parseRedactions parses the fields given with --redact-path.
+func parseRedactions(expressions []string) ([]scanner.Redaction, error) {
+	redactions := make([]scanner.Redaction, 0, len(expressions))
+	for _, expression := range expressions {
+		redaction, err := scanner.ParseRedaction(expression)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --redact-path %q: %w", expression, err)
+		}
+		redactions = append(redactions, redaction)
+	}
+
+	return redactions, nil
+}
+
+// startCheckpoint returns the ID of the next scan and makes the scanner record
+// its progress, when enabled. The scan recorded by the checkpoint file at
+// resumeFrom, if any, is resumed keeping its ID.
+func startCheckpoint(auditScanner *scanner.Scanner, resumeFrom string) (string, error) {
+	if resumeFrom != "" {
+		checkpoint, err := scanner.LoadCheckpoint(resumeFrom)
+		if err != nil {
+			return "", err
+		}
+		if checkpoint != nil {
+			log.Info().Dict("dict", zerolog.Dict().
+				Str("resume-from", resumeFrom).
+				Str("RunUID", checkpoint.RunUID).
+				Bool("cluster-wide-completed", checkpoint.ClusterWideCompleted).
+				Int("completed-namespaces", len(checkpoint.CompletedNamespaces)),
+			).Msg("resuming interrupted scan")
+			auditScanner.StartCheckpoint(*checkpoint)
+			return checkpoint.RunUID, nil
+		}
+		log.Info().Str("resume-from", resumeFrom).Msg("no interrupted scan to be resumed, starting a new scan")
 	}
 
 	runUID := uuid.New().String()
-	ctx := context.Background()
-	if clusterWide {
+	auditScanner.StartCheckpoint(scanner.Checkpoint{RunUID: runUID})
+
+	return runUID, nil
+}
+
+// startScanner runs a scan of a single namespace when namespace is given, of
+// the cluster wide resources when clusterWide is true, and of both the cluster
+// wide resources and all the namespaces when none of them is given.
+func startScanner(ctx context.Context, runUID, namespace string, clusterWide bool, scanner *scanner.Scanner) error {
+	ctx, span := tracing.Tracer().Start(ctx, "audit-scan", trace.WithAttributes(
+		attribute.String("run-uid", runUID),
+		attribute.String("namespace", namespace),
+		attribute.Bool("cluster-wide", clusterWide),
+	))
+	defer span.End()
+
+	if namespace == "" && clusterWide {
 		// only scan clusterwide
 		return scanner.ScanClusterWideResources(ctx, runUID)
 	}
-	if namespace != "" {
-		// only scan namespace
-		return scanner.ScanNamespace(ctx, namespace, runUID)
+
+	// scan the namespace, together with the cluster wide resources when
+	// requested, or by default the cluster wide resources and all the
+	// namespaces. The failures of the cluster wide scan, e.g. a resource type
+	// that cannot be listed, don't prevent the namespaces from being scanned
+	var clusterWideErr error
+	if clusterWide || namespace == "" {
+		clusterWideErr = scanner.ScanClusterWideResources(ctx, runUID)
+		if clusterWideErr != nil && ctx.Err() != nil {
+			return clusterWideErr
+		}
 	}
 
-	// neither clusterWide flag nor namespace was provided, default
-	// behaviour of scanning cluster wide and all ns
-	if err := scanner.ScanClusterWideResources(ctx, runUID); err != nil {
-		return err
+	var namespacesErr error
+	if namespace != "" {
+		namespacesErr = scanner.ScanNamespace(ctx, namespace, runUID)
+	} else {
+		namespacesErr = scanner.ScanAllNamespaces(ctx, runUID)
 	}
-	return scanner.ScanAllNamespaces(ctx, runUID)
+
+	return errors.Join(clusterWideErr, namespacesErr)
 }
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	logconfig "github.com/kubewarden/audit-scanner/internal/log"
+	"github.com/kubewarden/audit-scanner/internal/report"
+	"github.com/kubewarden/audit-scanner/internal/scanner"
+	"github.com/rs/zerolog"
+	zlog "github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteScanErrors(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "scan")
+	errorsFile := filepath.Join(dir, "errors.json")
+	scanErrors := []scanner.ScanError{{Kind: scanner.ScanErrorPolicyServer, Resource: "nginx", Message: "connection refused"}}
+
+	require.NoError(t, writeScanErrors(scanErrors, errorsFile))
+	// the errors of a scan replace the ones of the previous scan
+	require.NoError(t, writeScanErrors(nil, errorsFile))
+
+	content, err := os.ReadFile(errorsFile)
+	require.NoError(t, err)
+	writtenErrors := []scanner.ScanError{}
+	require.NoError(t, json.Unmarshal(content, &writtenErrors))
+	assert.Empty(t, writtenErrors)
+
+	// no temporary file is left behind
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestPartialScanFlag(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         []string
+		expectedFlag string
+	}{
+		{"whole cluster", nil, ""},
+		{"filters not narrowing the scan", []string{"--extra-gvr", "example.com/v1/widgets", "--only-failed"}, ""},
+		{"default values", []string{"--mode-filter", "both", "--audit-operation", "CREATE", "--ignore-namespaces", ""}, ""},
+		{"namespace", []string{"--namespace", "default"}, "namespace"},
+		{"cluster wide only alias", []string{"--cluster-wide-only"}, "cluster"},
+		{"resource selector", []string{"--resource-selector", "app=frontend"}, "resource-selector"},
+		{"field selector", []string{"--field-selector", "status.phase=Running"}, "field-selector"},
+		{"sample size", []string{"--sample-size", "10"}, "sample-size"},
+		{"namespace selector", []string{"--namespace-selector", "env=prod"}, "namespace-selector"},
+		{"ignored namespaces", []string{"--ignore-namespaces", "kube-system"}, "ignore-namespaces"},
+		{"skipped namespaces regex", []string{"--skip-namespace-regex", "pr-.*"}, "skip-namespace-regex"},
+		{"included kind", []string{"--include-kind", "Deployment.apps"}, "include-kind"},
+		{"excluded kind", []string{"--exclude-kind", "Event"}, "exclude-kind"},
+		{"skipped owned resources", []string{"--skip-owned-resources"}, "skip-owned-resources"},
+		{"skipped owner kind", []string{"--skip-owner-kind", "ReplicaSet.apps"}, "skip-owner-kind"},
+		{"policy", []string{"--policy", "privileged-pods"}, "policy"},
+		{"policy server name", []string{"--policy-server-name", "default"}, "policy-server-name"},
+		{"mode filter", []string{"--mode-filter", "protect"}, "mode-filter"},
+		{"audit operation", []string{"--audit-operation", "UPDATE"}, "audit-operation"},
+		{"min severity", []string{"--min-severity", "high"}, "min-severity"},
+		{"resumed scan", []string{"--resume-from", "checkpoint.json"}, "resume-from"},
+		{"first flag given", []string{"--policy", "privileged-pods", "--namespace", "default"}, "namespace"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rootCmd := NewRootCommand()
+			require.NoError(t, rootCmd.ParseFlags(test.args))
+
+			assert.Equal(t, test.expectedFlag, partialScanFlag(rootCmd))
+		})
+	}
+}
+
+func TestQuietLogs(t *testing.T) {
+	previousLogger, previousLevel := zlog.Logger, zerolog.GlobalLevel()
+	t.Cleanup(func() {
+		zlog.Logger = previousLogger
+		zerolog.SetGlobalLevel(previousLevel)
+	})
+
+	var buf bytes.Buffer
+	zlog.Logger = zerolog.New(&buf)
+	zerolog.SetGlobalLevel(zerolog.DebugLevel)
+	logconfig.SetQuiet()
+
+	zlog.Debug().Msg("debug message")
+	zlog.Info().Msg("info message")
+	zlog.Warn().Msg("warn message")
+	zlog.Error().Msg("error message")
+	logScanSummary(report.ScanSummary{Resources: 2, Pass: 1, Fail: 1}, true)
+
+	// the info and debug logs are dropped, the summary is still written
+	assert.Equal(t,
+		`{"level":"warn","message":"warn message"}`+"\n"+
+			`{"level":"error","message":"error message"}`+"\n"+
+			`{"dict":{"namespaces":0,"resources":2,"policy-evaluations":0,"pass":1,"fail":1,"warn":0,"error":0,"skip":0,"sample-size":0},"message":"scan summary"}`+"\n",
+		buf.String())
+}
@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/kubewarden/audit-scanner/internal/version"
+	"github.com/spf13/cobra"
+)
+
+// newVersionCommand returns the command printing the build information, the
+// same printed by --version.
+func newVersionCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "version",
+		Short: "Print the version, the git commit and the build date of the audit scanner",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			_, err := fmt.Fprintf(cmd.OutOrStdout(), "%s version %s\n", cmd.Root().Name(), version.String())
+			return err
+		},
+	}
+}
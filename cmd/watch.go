@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// watch runs scan immediately and then every interval, until ctx is done.
+// The scan in progress when ctx is done is completed before returning.
+// Scans never overlap: the ticks happening while a scan is still running are
+// skipped, instead of starting the missed scans one after the other.
+// Failed scans are logged and don't stop the loop.
+func watch(ctx context.Context, interval time.Duration, scan func() error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		log.Info().Msg("periodic scan started")
+		if err := scan(); err != nil {
+			log.Error().Err(err).Msg("periodic scan failed")
+		}
+
+		select {
+		case <-ticker.C:
+			log.Warn().Dur("interval", interval).Msg("the scan took longer than the interval, skipping a tick")
+		default:
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("stopping periodic scans")
+			return
+		case <-ticker.C:
+		}
+	}
+}
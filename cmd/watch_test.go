@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatch(t *testing.T) {
+	tests := []struct {
+		name         string
+		interval     time.Duration
+		scanDuration time.Duration
+		scanErr      error
+		// cancelAtScan is the scan cancelling the context, 0 cancels it before
+		// watch is called
+		cancelAtScan  int32
+		expectedScans int32
+	}{
+		{"cancelled context", time.Hour, 0, nil, 0, 1},
+		{"periodic scans", 5 * time.Millisecond, 0, nil, 3, 3},
+		{"scans slower than the interval", 5 * time.Millisecond, 20 * time.Millisecond, nil, 3, 3},
+		{"failed scans", 5 * time.Millisecond, 0, errors.New("scan failed"), 3, 3},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if test.cancelAtScan == 0 {
+				cancel()
+			}
+
+			var started, completed, running, maxRunning atomic.Int32
+			scan := func() error {
+				concurrent := running.Add(1)
+				defer running.Add(-1)
+				if concurrent > maxRunning.Load() {
+					maxRunning.Store(concurrent)
+				}
+				if started.Add(1) == test.cancelAtScan {
+					// the scan in progress is completed anyway
+					cancel()
+				}
+				time.Sleep(test.scanDuration)
+				completed.Add(1)
+				return test.scanErr
+			}
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				watch(ctx, test.interval, scan)
+			}()
+			select {
+			case <-done:
+			case <-time.After(10 * time.Second):
+				t.Fatal("watch didn't stop once the context was cancelled")
+			}
+
+			assert.Equal(t, test.expectedScans, started.Load())
+			assert.Equal(t, test.expectedScans, completed.Load())
+			// the ticks happening during a scan don't start overlapping scans
+			assert.Equal(t, int32(1), maxRunning.Load())
+		})
+	}
+}
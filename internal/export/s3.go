@@ -0,0 +1,119 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/kubewarden/audit-scanner/internal/report"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// defaultPartSize is the size of the parts of the multipart uploads, the
+	// results bigger than this are uploaded in multiple parts
+	defaultPartSize  = 16 * 1024 * 1024
+	objectTimeFormat = "20060102T150405Z"
+	jsonContentType  = "application/json"
+)
+
+// S3Config configures the upload of the scan results to an S3-compatible
+// object store.
+type S3Config struct {
+	// Endpoint is the host and optional port of the object store, e.g. s3.amazonaws.com
+	Endpoint string
+	// Insecure makes the uploads use plain HTTP instead of HTTPS
+	Insecure bool
+	// Region is the region of the bucket, it's discovered when empty
+	Region string
+	Bucket string
+	// Prefix is prepended to the name of the objects
+	Prefix string
+	// ClusterName identifies the cluster in the name of the objects, it's omitted when empty
+	ClusterName string
+	// CredentialsFile is an AWS shared credentials file. When empty, the
+	// credentials are read from the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY
+	// environment variables, or the MINIO_ACCESS_KEY and MINIO_SECRET_KEY ones.
+	CredentialsFile string
+}
+
+// S3Exporter uploads the results of the scans to an S3-compatible object store.
+type S3Exporter struct {
+	client      *minio.Client
+	bucket      string
+	prefix      string
+	clusterName string
+	partSize    uint64
+	now         func() time.Time
+}
+
+// NewS3Exporter returns an exporter uploading the results to the bucket given
+// in the config.
+func NewS3Exporter(config S3Config) (*S3Exporter, error) {
+	if config.Endpoint == "" {
+		return nil, errors.New("the S3 endpoint is required")
+	}
+	if config.Bucket == "" {
+		return nil, errors.New("the S3 bucket is required")
+	}
+
+	creds := credentials.NewChainCredentials([]credentials.Provider{
+		&credentials.EnvAWS{},
+		&credentials.EnvMinio{},
+	})
+	if config.CredentialsFile != "" {
+		creds = credentials.NewFileAWSCredentials(config.CredentialsFile, "")
+	}
+
+	client, err := minio.New(config.Endpoint, &minio.Options{
+		Creds:  creds,
+		Secure: !config.Insecure,
+		Region: config.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot create S3 client for %s: %w", config.Endpoint, err)
+	}
+
+	return &S3Exporter{
+		client:      client,
+		bucket:      config.Bucket,
+		prefix:      config.Prefix,
+		clusterName: config.ClusterName,
+		partSize:    defaultPartSize,
+		now:         time.Now,
+	}, nil
+}
+
+// Export uploads the reports retained by the store as a single JSON document,
+// the same written by report.PolicyReportStore.WriteJSON, and returns the name
+// of the object. Big documents are uploaded in multiple parts.
+func (e *S3Exporter) Export(ctx context.Context, store *report.PolicyReportStore) (string, error) {
+	var buf bytes.Buffer
+	if err := store.WriteJSON(&buf); err != nil {
+		return "", err
+	}
+
+	objectName := e.objectName()
+	info, err := e.client.PutObject(ctx, e.bucket, objectName, &buf, int64(buf.Len()), minio.PutObjectOptions{
+		ContentType: jsonContentType,
+		PartSize:    e.partSize,
+	})
+	if err != nil {
+		return "", fmt.Errorf("cannot upload %s to bucket %s: %w", objectName, e.bucket, err)
+	}
+	log.Info().Str("bucket", e.bucket).Str("object", objectName).Int64("size", info.Size).Msg("Scan results uploaded")
+
+	return objectName, nil
+}
+
+// objectName returns the name of the object of a scan completed now, in the
+// form prefix/cluster-name/timestamp.json, so that the objects of a cluster
+// are listed in chronological order.
+func (e *S3Exporter) objectName() string {
+	return path.Join(e.prefix, e.clusterName, e.now().UTC().Format(objectTimeFormat)+".json")
+}
@@ -0,0 +1,178 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kubewarden/audit-scanner/internal/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	wgpolicy "sigs.k8s.io/wg-policy-prototypes/policy-report/pkg/api/wgpolicyk8s.io/v1alpha2"
+)
+
+// fakeObjectStore implements the subset of the S3 API used to upload objects,
+// with and without multipart uploads.
+type fakeObjectStore struct {
+	mutex   sync.Mutex
+	objects map[string][]byte
+	parts   map[string][]byte
+	// multipartUploads counts the completed multipart uploads
+	multipartUploads int
+}
+
+func newFakeObjectStore() *fakeObjectStore {
+	return &fakeObjectStore{objects: map[string][]byte{}, parts: map[string][]byte{}}
+}
+
+func (s *fakeObjectStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := strings.TrimPrefix(r.URL.Path, "/")
+	query := r.URL.Query()
+	body, err := readBody(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		fmt.Fprintf(w, "<InitiateMultipartUploadResult><UploadId>upload-id</UploadId></InitiateMultipartUploadResult>")
+	case r.Method == http.MethodPut && query.Has("partNumber"):
+		s.parts[query.Get("partNumber")] = body
+		w.Header().Set("ETag", `"part-`+query.Get("partNumber")+`"`)
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		var object []byte
+		for i := 1; i <= len(s.parts); i++ {
+			object = append(object, s.parts[fmt.Sprint(i)]...)
+		}
+		s.objects[key] = object
+		s.multipartUploads++
+		fmt.Fprintf(w, `<CompleteMultipartUploadResult><Bucket>audit</Bucket><ETag>"object"</ETag></CompleteMultipartUploadResult>`)
+	case r.Method == http.MethodPut:
+		s.objects[key] = body
+		w.Header().Set("ETag", `"object"`)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+// readBody returns the payload of the request, decoding it when it's sent in
+// signed chunks, as done by the client over plain HTTP.
+func readBody(r *http.Request) ([]byte, error) {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return io.ReadAll(r.Body)
+	}
+
+	var payload []byte
+	reader := bufio.NewReader(r.Body)
+	for {
+		// every chunk is "<hex size>;chunk-signature=<signature>\r\n<data>\r\n"
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		sizeHex, _, _ := strings.Cut(strings.TrimSpace(header), ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return payload, nil
+		}
+		chunk := make([]byte, size+int64(len("\r\n")))
+		if _, err := io.ReadFull(reader, chunk); err != nil {
+			return nil, err
+		}
+		payload = append(payload, chunk[:size]...)
+	}
+}
+
+func newTestS3Exporter(t *testing.T, server *httptest.Server, prefix string) *S3Exporter {
+	t.Helper()
+	t.Setenv("AWS_ACCESS_KEY_ID", "access-key")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret-key")
+
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	exporter, err := NewS3Exporter(S3Config{
+		Endpoint:    serverURL.Host,
+		Insecure:    true,
+		Region:      "us-east-1",
+		Bucket:      "audit",
+		Prefix:      prefix,
+		ClusterName: "production",
+	})
+	require.NoError(t, err)
+	exporter.now = func() time.Time {
+		return time.Date(2025, time.March, 4, 10, 30, 0, 0, time.UTC)
+	}
+
+	return exporter
+}
+
+func TestS3Export(t *testing.T) {
+	objectStore := newFakeObjectStore()
+	server := httptest.NewServer(objectStore)
+	defer server.Close()
+	exporter := newTestS3Exporter(t, server, "scans")
+
+	store := report.NewPolicyReportStore(nil, report.WithInMemoryReports())
+	store.RecordClusterPolicyReport(&wgpolicy.ClusterPolicyReport{ObjectMeta: metav1.ObjectMeta{Name: "namespace-uid"}})
+
+	objectName, err := exporter.Export(context.Background(), store)
+	require.NoError(t, err)
+	assert.Equal(t, "scans/production/20250304T103000Z.json", objectName)
+
+	var expected bytes.Buffer
+	require.NoError(t, store.WriteJSON(&expected))
+	assert.Equal(t, expected.Bytes(), objectStore.objects["audit/"+objectName])
+	assert.Equal(t, 0, objectStore.multipartUploads)
+}
+
+func TestS3ExportMultipart(t *testing.T) {
+	objectStore := newFakeObjectStore()
+	server := httptest.NewServer(objectStore)
+	defer server.Close()
+	exporter := newTestS3Exporter(t, server, "")
+	exporter.partSize = 5 * 1024 * 1024
+
+	store := report.NewPolicyReportStore(nil, report.WithInMemoryReports())
+	store.RecordPolicyReport(&wgpolicy.PolicyReport{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-uid", Namespace: "default"},
+		Results: []*wgpolicy.PolicyReportResult{
+			{Policy: "policy", Description: strings.Repeat("x", 12*1024*1024)},
+		},
+	})
+
+	objectName, err := exporter.Export(context.Background(), store)
+	require.NoError(t, err)
+	assert.Equal(t, "production/20250304T103000Z.json", objectName)
+
+	var expected bytes.Buffer
+	require.NoError(t, store.WriteJSON(&expected))
+	assert.Equal(t, expected.Bytes(), objectStore.objects["audit/"+objectName])
+	assert.Equal(t, 1, objectStore.multipartUploads)
+	assert.Len(t, objectStore.parts, 3)
+}
+
+func TestNewS3ExporterValidation(t *testing.T) {
+	_, err := NewS3Exporter(S3Config{Bucket: "audit"})
+	require.ErrorContains(t, err, "endpoint")
+
+	_, err = NewS3Exporter(S3Config{Endpoint: "s3.example.com"})
+	require.ErrorContains(t, err, "bucket")
+}
@@ -0,0 +1,155 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	readHeaderTimeout = 5 * time.Second
+	shutdownTimeout   = 5 * time.Second
+)
+
+// Checker tracks the state of the scanner reported by the probes.
+// The scanner is ready once SetReady(true) is called, and healthy unless the
+// scan in progress made no progress for longer than the stall timeout, which
+// means it's stuck. The scans of large clusters can run for a long time, only
+// the lack of progress tells apart a stuck scan. It's safe for concurrent use.
+type Checker struct {
+	mutex sync.Mutex
+	ready bool
+	// lastProgress is when the scan in progress started or last made
+	// progress, it's zero between scans
+	lastProgress time.Time
+	stallTimeout time.Duration
+	now          func() time.Time
+}
+
+// NewChecker returns a Checker reporting the scanner as not healthy when a
+// scan makes no progress for longer than stallTimeout. The scans are never
+// considered stuck when stallTimeout is not positive.
+func NewChecker(stallTimeout time.Duration) *Checker {
+	return &Checker{
+		stallTimeout: stallTimeout,
+		now:          time.Now,
+	}
+}
+
+// SetReady sets whether the scanner is ready, e.g. false when shutting down.
+func (c *Checker) SetReady(ready bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.ready = ready
+}
+
+// ScanStarted records the start of a scan.
+func (c *Checker) ScanStarted() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.lastProgress = c.now()
+}
+
+// Progress records that the scan in progress made progress, e.g. it fetched a
+// page of resources. It's ignored between scans.
+func (c *Checker) Progress() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if !c.lastProgress.IsZero() {
+		c.lastProgress = c.now()
+	}
+}
+
+// ScanFinished records the end of the scan in progress.
+func (c *Checker) ScanFinished() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.lastProgress = time.Time{}
+}
+
+// Ready returns true when the scanner is ready.
+func (c *Checker) Ready() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.ready
+}
+
+// Healthy returns an error when the scan in progress is stuck.
+func (c *Checker) Healthy() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.stallTimeout <= 0 || c.lastProgress.IsZero() {
+		return nil
+	}
+	if stalled := c.now().Sub(c.lastProgress); stalled > c.stallTimeout {
+		return fmt.Errorf("the scan has made no progress for %s, longer than %s", stalled.Round(time.Second), c.stallTimeout)
+	}
+	return nil
+}
+
+// Handler returns an HTTP handler serving the liveness probe on /healthz and
+// the readiness probe on /readyz.
+func Handler(checker *Checker) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		if err := checker.Healthy(); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, _ *http.Request) {
+		if !checker.Ready() {
+			http.Error(w, "not ready", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+
+	return mux
+}
+
+// Serve exposes the probes of checker on address until ctx is done, then
+// shuts the server down gracefully.
+// The listener is opened before returning, so an invalid or busy address is
+// reported immediately. The returned channel receives the outcome of the
+// server once it has stopped.
+func Serve(ctx context.Context, address string, checker *Checker) (<-chan error, error) {
+	listener, err := (&net.ListenConfig{}).Listen(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("cannot listen on health address %s: %w", address, err)
+	}
+
+	server := &http.Server{
+		Handler:           Handler(checker),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+		// the parent context is done, use a fresh one to bound the shutdown
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Error().Err(err).Msg("error shutting down the health server")
+		}
+	}()
+	go func() {
+		log.Info().Str("address", listener.Addr().String()).Msg("serving health probes")
+		err := server.Serve(listener)
+		if errors.Is(err, http.ErrServerClosed) {
+			err = nil
+		}
+		done <- err
+		close(done)
+	}()
+
+	return done, nil
+}
@@ -0,0 +1,85 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func probe(t *testing.T, handler http.Handler, path string) int {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+	return recorder.Code
+}
+
+func TestReadiness(t *testing.T) {
+	checker := NewChecker(time.Hour)
+	handler := Handler(checker)
+
+	assert.Equal(t, http.StatusServiceUnavailable, probe(t, handler, "/readyz"))
+
+	checker.SetReady(true)
+	assert.Equal(t, http.StatusOK, probe(t, handler, "/readyz"))
+
+	// shutting down
+	checker.SetReady(false)
+	assert.Equal(t, http.StatusServiceUnavailable, probe(t, handler, "/readyz"))
+}
+
+func TestLiveness(t *testing.T) {
+	now := time.Date(2025, time.March, 4, 10, 0, 0, 0, time.UTC)
+	checker := NewChecker(time.Hour)
+	checker.now = func() time.Time { return now }
+	handler := Handler(checker)
+
+	// healthy between scans, regardless of how long they are apart
+	assert.Equal(t, http.StatusOK, probe(t, handler, "/healthz"))
+
+	// progress made before a scan is ignored
+	checker.Progress()
+	assert.NoError(t, checker.Healthy())
+
+	checker.ScanStarted()
+	now = now.Add(30 * time.Minute)
+	assert.Equal(t, http.StatusOK, probe(t, handler, "/healthz"))
+
+	// a long scan is healthy while it makes progress
+	for range 4 {
+		now = now.Add(50 * time.Minute)
+		checker.Progress()
+		assert.Equal(t, http.StatusOK, probe(t, handler, "/healthz"))
+	}
+
+	// the scan is stuck
+	now = now.Add(time.Hour + time.Minute)
+	assert.Equal(t, http.StatusServiceUnavailable, probe(t, handler, "/healthz"))
+
+	checker.ScanFinished()
+	assert.Equal(t, http.StatusOK, probe(t, handler, "/healthz"))
+
+	// scans are never stuck without a stall timeout
+	checker = NewChecker(0)
+	checker.ScanStarted()
+	assert.NoError(t, checker.Healthy())
+}
+
+func TestServe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done, err := Serve(ctx, "127.0.0.1:0", NewChecker(time.Hour))
+	require.NoError(t, err)
+
+	cancel()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("health server did not shut down")
+	}
+}
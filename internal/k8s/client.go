@@ -3,6 +3,9 @@ package k8s
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -13,6 +16,7 @@ import (
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/pager"
@@ -28,18 +32,54 @@ type Client struct {
 	skippedNs []string
 	// pageSize is the number of resources to fetch when paginating
 	pageSize int64
+	// namespaceCache keeps the namespaces already fetched, it's nil when caching is disabled
+	namespaceCache *namespaceCache
+	// pageFetched is called after every page of resources is fetched, it's nil when not needed
+	pageFetched func()
+}
+
+// ClientOption configures optional behaviour of a Client.
+type ClientOption func(*Client)
+
+// WithNamespaceCacheTTL makes the Client reuse the namespaces fetched from
+// the cluster for the given time, instead of fetching them again.
+// Namespaces are not cached when ttl is not positive.
+func WithNamespaceCacheTTL(ttl time.Duration) ClientOption {
+	return func(c *Client) {
+		if ttl > 0 {
+			c.namespaceCache = newNamespaceCache(ttl)
+		}
+	}
+}
+
+// WithPageFetched makes the Client call pageFetched after every page of
+// resources is fetched, successfully or not, e.g. to track the progress of the
+// scans listing many resources.
+func WithPageFetched(pageFetched func()) ClientOption {
+	return func(c *Client) {
+		c.pageFetched = pageFetched
+	}
 }
 
 // NewClient returns a new client.
-func NewClient(dynamicClient dynamic.Interface, clientset kubernetes.Interface, kubewardenNamespace string, skippedNs []string, pageSize int64) (*Client, error) {
+// pageSize is the number of resources fetched with every list request, it must be positive.
+func NewClient(dynamicClient dynamic.Interface, clientset kubernetes.Interface, kubewardenNamespace string, skippedNs []string, pageSize int64, opts ...ClientOption) (*Client, error) {
+	if pageSize <= 0 {
+		return nil, fmt.Errorf("invalid page size %d: it must be a positive number", pageSize)
+	}
 	skippedNs = append(skippedNs, kubewardenNamespace)
 
-	return &Client{
-		dynamicClient,
-		clientset,
-		skippedNs,
-		pageSize,
-	}, nil
+	client := &Client{
+		dynamicClient: dynamicClient,
+		clientset:     clientset,
+		skippedNs:     skippedNs,
+		pageSize:      pageSize,
+	}
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	return client, nil
 }
 
 func (f *Client) GetResources(gvr schema.GroupVersionResource, nsName string) (*pager.ListPager, error) {
@@ -50,6 +90,9 @@ func (f *Client) GetResources(gvr schema.GroupVersionResource, nsName string) (*
 		page++
 
 		resources, err := f.listResources(ctx, gvr, nsName, opts)
+		if f.pageFetched != nil {
+			f.pageFetched()
+		}
 		if apimachineryerrors.IsNotFound(err) {
 			log.Warn().
 				Dict("dict", zerolog.Dict().
@@ -104,9 +147,128 @@ func (f *Client) GetAuditedNamespaces(ctx context.Context) (*corev1.NamespaceLis
 	if err != nil {
 		return nil, fmt.Errorf("can't list namespaces: %w", err)
 	}
+	if f.namespaceCache != nil {
+		for i := range namespaceList.Items {
+			f.namespaceCache.add(&namespaceList.Items[i])
+		}
+	}
 	return namespaceList, nil
 }
 
+// SkippedNamespaces returns the names of the namespaces skipped from the audit,
+// including the Kubewarden one.
+func (f *Client) SkippedNamespaces() []string {
+	return slices.Clone(f.skippedNs)
+}
+
+// IsSkippedNamespace returns true if the namespace is skipped from the audit.
+func (f *Client) IsSkippedNamespace(nsName string) bool {
+	return slices.Contains(f.skippedNs, nsName)
+}
+
+// UnknownKinds returns the kinds not served by the cluster, in the order they
+// are given, to catch the typos in the kinds selected by the user. A kind
+// without a group is known when it's served by any group.
+func (f *Client) UnknownKinds(kinds []schema.GroupKind) ([]schema.GroupKind, error) {
+	if len(kinds) == 0 {
+		return nil, nil
+	}
+
+	resourceLists, err := f.serverResources()
+	if err != nil {
+		return nil, err
+	}
+	served := map[schema.GroupKind]struct{}{}
+	for _, resourceList := range resourceLists {
+		groupVersion, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			return nil, err
+		}
+		for _, resource := range resourceList.APIResources {
+			// subresources, e.g. deployments/scale, have the kind of their payload
+			if strings.Contains(resource.Name, "/") {
+				continue
+			}
+			served[schema.GroupKind{Group: groupVersion.Group, Kind: resource.Kind}] = struct{}{}
+			served[schema.GroupKind{Kind: resource.Kind}] = struct{}{}
+		}
+	}
+
+	var unknown []schema.GroupKind
+	for _, kind := range kinds {
+		if _, found := served[kind]; !found {
+			unknown = append(unknown, kind)
+		}
+	}
+
+	return unknown, nil
+}
+
+// UnknownResources returns the resources not served by the cluster, in the
+// order they are given, to catch the typos in the resources selected by the
+// user.
+func (f *Client) UnknownResources(gvrs []schema.GroupVersionResource) ([]schema.GroupVersionResource, error) {
+	if len(gvrs) == 0 {
+		return nil, nil
+	}
+
+	resourceLists, err := f.serverResources()
+	if err != nil {
+		return nil, err
+	}
+	served := map[schema.GroupVersionResource]struct{}{}
+	for _, resourceList := range resourceLists {
+		groupVersion, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			return nil, err
+		}
+		for _, resource := range resourceList.APIResources {
+			// subresources, e.g. deployments/scale, can't be listed
+			if strings.Contains(resource.Name, "/") {
+				continue
+			}
+			served[groupVersion.WithResource(resource.Name)] = struct{}{}
+		}
+	}
+
+	var unknown []schema.GroupVersionResource
+	for _, gvr := range gvrs {
+		if _, found := served[gvr]; !found {
+			unknown = append(unknown, gvr)
+		}
+	}
+
+	return unknown, nil
+}
+
+// serverResources returns the resources served by the cluster. The resources
+// of the groups whose discovery failed are left out.
+func (f *Client) serverResources() ([]*metav1.APIResourceList, error) {
+	_, resourceLists, err := f.clientset.Discovery().ServerGroupsAndResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, fmt.Errorf("cannot discover the resources served by the cluster: %w", err)
+	}
+
+	return resourceLists, nil
+}
+
+// GetNamespace gets the namespace with the given name.
+// When the namespace cache is enabled, the cached namespace is returned if it
+// has not expired yet. Namespaces not found are never cached, so deleted
+// namespaces are not served once their cache entry expires.
 func (f *Client) GetNamespace(ctx context.Context, nsName string) (*corev1.Namespace, error) {
-	return f.clientset.CoreV1().Namespaces().Get(ctx, nsName, metav1.GetOptions{})
+	if f.namespaceCache == nil {
+		return f.clientset.CoreV1().Namespaces().Get(ctx, nsName, metav1.GetOptions{})
+	}
+	if namespace, found := f.namespaceCache.get(nsName); found {
+		return namespace, nil
+	}
+
+	namespace, err := f.clientset.CoreV1().Namespaces().Get(ctx, nsName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	f.namespaceCache.add(namespace)
+
+	return namespace, nil
 }
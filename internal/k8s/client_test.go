@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	apimachineryerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -47,3 +49,151 @@ func TestGetResources(t *testing.T) {
 	assert.Len(t, unstructuredList.Items, pageSize+5)
 	assert.Equal(t, "PodList", unstructuredList.GetObjectKind().GroupVersionKind().Kind)
 }
+
+func TestGetResourcesPageSize(t *testing.T) {
+	const customPageSize = 3
+
+	k8sClient, err := NewClient(dynamicFake.NewSimpleDynamicClient(scheme.Scheme), fake.NewSimpleClientset(), "kubewarden", nil, customPageSize)
+	require.NoError(t, err)
+
+	pager, err := k8sClient.GetResources(schema.GroupVersionResource{
+		Group:    "",
+		Version:  "v1",
+		Resource: "pods",
+	}, "default")
+	require.NoError(t, err)
+
+	// the fake dynamic client ignores the limit of the list requests,
+	// hence the configuration of the pager is checked instead
+	assert.Equal(t, int64(customPageSize), pager.PageSize)
+}
+
+func TestGetResourcesPageFetched(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default"}}
+	pagesFetched := 0
+	k8sClient, err := NewClient(dynamicFake.NewSimpleDynamicClient(scheme.Scheme, pod), fake.NewSimpleClientset(), "kubewarden", nil, pageSize,
+		WithPageFetched(func() { pagesFetched++ }))
+	require.NoError(t, err)
+
+	pager, err := k8sClient.GetResources(schema.GroupVersionResource{
+		Group:    "",
+		Version:  "v1",
+		Resource: "pods",
+	}, "default")
+	require.NoError(t, err)
+
+	_, _, err = pager.List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, pagesFetched)
+}
+
+func TestNewClientInvalidPageSize(t *testing.T) {
+	_, err := NewClient(dynamicFake.NewSimpleDynamicClient(scheme.Scheme), fake.NewSimpleClientset(), "kubewarden", nil, 0)
+	require.Error(t, err)
+}
+
+func TestGetNamespaceCache(t *testing.T) {
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+	clientset := fake.NewSimpleClientset(namespace)
+
+	k8sClient, err := NewClient(dynamicFake.NewSimpleDynamicClient(scheme.Scheme), clientset, "kubewarden", nil, pageSize, WithNamespaceCacheTTL(time.Minute))
+	require.NoError(t, err)
+	now := time.Now()
+	k8sClient.namespaceCache.now = func() time.Time { return now }
+
+	countGets := func() int {
+		gets := 0
+		for _, action := range clientset.Actions() {
+			if action.Matches("get", "namespaces") {
+				gets++
+			}
+		}
+		return gets
+	}
+
+	for range 2 {
+		cachedNamespace, err := k8sClient.GetNamespace(context.Background(), "default")
+		require.NoError(t, err)
+		assert.Equal(t, "default", cachedNamespace.GetName())
+	}
+	assert.Equal(t, 1, countGets())
+
+	// once expired, the namespace is fetched again and deleted namespaces are not served
+	require.NoError(t, clientset.CoreV1().Namespaces().Delete(context.Background(), "default", metav1.DeleteOptions{}))
+	now = now.Add(time.Minute)
+	_, err = k8sClient.GetNamespace(context.Background(), "default")
+	require.True(t, apimachineryerrors.IsNotFound(err))
+	assert.Equal(t, 2, countGets())
+}
+
+func TestGetNamespaceWithoutCache(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}})
+
+	k8sClient, err := NewClient(dynamicFake.NewSimpleDynamicClient(scheme.Scheme), clientset, "kubewarden", nil, pageSize)
+	require.NoError(t, err)
+
+	for range 2 {
+		_, err := k8sClient.GetNamespace(context.Background(), "default")
+		require.NoError(t, err)
+	}
+	assert.Len(t, clientset.Actions(), 2)
+}
+
+func TestUnknownKinds(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	clientset.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{{Name: "pods", Kind: "Pod"}, {Name: "events", Kind: "Event"}},
+		},
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{{Name: "deployments", Kind: "Deployment"}, {Name: "deployments/scale", Kind: "Scale"}},
+		},
+	}
+
+	k8sClient, err := NewClient(dynamicFake.NewSimpleDynamicClient(scheme.Scheme), clientset, "kubewarden", nil, pageSize)
+	require.NoError(t, err)
+
+	unknown, err := k8sClient.UnknownKinds([]schema.GroupKind{
+		{Kind: "Pod"},
+		{Group: "apps", Kind: "Deployment"},
+		{Kind: "Deployment"},
+		{Kind: "Deplyoment"},
+		{Group: "batch", Kind: "Deployment"},
+		{Kind: "Scale"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []schema.GroupKind{{Kind: "Deplyoment"}, {Group: "batch", Kind: "Deployment"}, {Kind: "Scale"}}, unknown)
+}
+
+func TestUnknownResources(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	clientset.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{{Name: "pods", Kind: "Pod"}},
+		},
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{{Name: "deployments", Kind: "Deployment"}, {Name: "deployments/scale", Kind: "Scale"}},
+		},
+	}
+
+	k8sClient, err := NewClient(dynamicFake.NewSimpleDynamicClient(scheme.Scheme), clientset, "kubewarden", nil, pageSize)
+	require.NoError(t, err)
+
+	unknown, err := k8sClient.UnknownResources([]schema.GroupVersionResource{
+		{Version: "v1", Resource: "pods"},
+		{Group: "apps", Version: "v1", Resource: "deployments"},
+		{Group: "apps", Version: "v1beta1", Resource: "deployments"},
+		{Group: "apps", Version: "v1", Resource: "deployments/scale"},
+		{Group: "example.com", Version: "v1", Resource: "widgets"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []schema.GroupVersionResource{
+		{Group: "apps", Version: "v1beta1", Resource: "deployments"},
+		{Group: "apps", Version: "v1", Resource: "deployments/scale"},
+		{Group: "example.com", Version: "v1", Resource: "widgets"},
+	}, unknown)
+}
@@ -0,0 +1,52 @@
+package k8s
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	// registers the OIDC auth provider, used by kubeconfig files of clusters
+	// authenticating users through an identity provider. Exec credential
+	// plugins are supported by client-go out of the box
+	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
+)
+
+// LoadRESTConfig returns the configuration used to connect to the Kubernetes
+// API server, following the standard kubeconfig loading rules: the kubeconfig
+// file, when not empty, then the files listed in $KUBECONFIG, then
+// ~/.kube/config. The in-cluster configuration is used when none of them
+// exists.
+// kubeContext selects the context of the kubeconfig to use instead of the
+// current one, it must exist in the kubeconfig.
+// Unlike ctrl.GetConfigOrDie, it returns an error rather than exiting when no
+// configuration can be loaded.
+func LoadRESTConfig(kubeconfig, kubeContext string) (*rest.Config, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubeconfig
+
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{CurrentContext: kubeContext})
+	if kubeContext != "" {
+		rawConfig, err := clientConfig.RawConfig()
+		if err != nil {
+			return nil, fmt.Errorf("cannot load the kubeconfig: %w", err)
+		}
+		if _, found := rawConfig.Contexts[kubeContext]; !found {
+			return nil, fmt.Errorf("context %q not found in the kubeconfig, the available contexts are: %v",
+				kubeContext, slices.Sorted(maps.Keys(rawConfig.Contexts)))
+		}
+	}
+
+	config, err := clientConfig.ClientConfig()
+	if err != nil {
+		if clientcmd.IsEmptyConfig(err) {
+			return nil, errors.New("cannot find the configuration to connect to Kubernetes: the audit scanner is not running inside a cluster " +
+				"and no kubeconfig file has been found, set $KUBECONFIG or create ~/.kube/config")
+		}
+		return nil, fmt.Errorf("cannot load the configuration to connect to Kubernetes: %w", err)
+	}
+
+	return config, nil
+}
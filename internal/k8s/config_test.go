@@ -0,0 +1,75 @@
+package k8s
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: local
+  cluster:
+    server: https://127.0.0.1:6443
+- name: remote
+  cluster:
+    server: https://remote.example.com:6443
+users:
+- name: developer
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1
+      command: kubectl-oidc-login
+      args: ["get-token"]
+      interactiveMode: Never
+contexts:
+- name: remote
+  context:
+    cluster: remote
+    user: developer
+- name: local
+  context:
+    cluster: local
+    user: developer
+current-context: remote
+`
+
+func TestLoadRESTConfig(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(kubeconfig, []byte(testKubeconfig), 0o600))
+
+	config, err := LoadRESTConfig(kubeconfig, "")
+	require.NoError(t, err)
+	assert.Equal(t, "https://remote.example.com:6443", config.Host)
+	require.NotNil(t, config.ExecProvider)
+	assert.Equal(t, "kubectl-oidc-login", config.ExecProvider.Command)
+
+	config, err = LoadRESTConfig(kubeconfig, "local")
+	require.NoError(t, err)
+	assert.Equal(t, "https://127.0.0.1:6443", config.Host)
+
+	_, err = LoadRESTConfig(kubeconfig, "production")
+	require.ErrorContains(t, err, `context "production" not found in the kubeconfig, the available contexts are: [local remote]`)
+
+	// $KUBECONFIG is used when no kubeconfig file is given
+	t.Setenv("KUBECONFIG", kubeconfig)
+	config, err = LoadRESTConfig("", "")
+	require.NoError(t, err)
+	assert.Equal(t, "https://remote.example.com:6443", config.Host)
+}
+
+func TestLoadRESTConfigErrors(t *testing.T) {
+	// outside of a cluster and without kubeconfig files
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	t.Setenv("KUBECONFIG", filepath.Join(t.TempDir(), "missing"))
+	t.Setenv("HOME", t.TempDir())
+	_, err := LoadRESTConfig("", "")
+	require.ErrorContains(t, err, "cannot find the configuration to connect to Kubernetes")
+
+	_, err = LoadRESTConfig(filepath.Join(t.TempDir(), "missing"), "")
+	require.ErrorContains(t, err, "cannot load the configuration to connect to Kubernetes")
+}
@@ -0,0 +1,58 @@
+package k8s
+
+import (
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// namespaceCache keeps the namespaces fetched from the cluster for a limited
+// time, to avoid fetching the same namespace again and again.
+type namespaceCache struct {
+	mutex      sync.Mutex
+	ttl        time.Duration
+	namespaces map[string]cachedNamespace
+	// now returns the current time, it's replaced by tests
+	now func() time.Time
+}
+
+type cachedNamespace struct {
+	namespace *corev1.Namespace
+	expiresAt time.Time
+}
+
+func newNamespaceCache(ttl time.Duration) *namespaceCache {
+	return &namespaceCache{
+		ttl:        ttl,
+		namespaces: map[string]cachedNamespace{},
+		now:        time.Now,
+	}
+}
+
+// get returns a copy of the cached namespace, if it's cached and not expired.
+func (c *namespaceCache) get(name string) (*corev1.Namespace, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	cached, found := c.namespaces[name]
+	if !found {
+		return nil, false
+	}
+	if !c.now().Before(cached.expiresAt) {
+		delete(c.namespaces, name)
+		return nil, false
+	}
+
+	return cached.namespace.DeepCopy(), true
+}
+
+func (c *namespaceCache) add(namespace *corev1.Namespace) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.namespaces[namespace.GetName()] = cachedNamespace{
+		namespace: namespace.DeepCopy(),
+		expiresAt: c.now().Add(c.ttl),
+	}
+}
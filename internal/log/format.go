@@ -0,0 +1,58 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/rs/zerolog"
+	zlog "github.com/rs/zerolog/log"
+)
+
+const (
+	// FormatJSON writes every log entry as a JSON object on its own line.
+	FormatJSON = "json"
+	// FormatConsole writes the log entries as colorized, human-readable lines.
+	FormatConsole = "console"
+)
+
+// Format implements the Value interface (https://pkg.go.dev/github.com/spf13/pflag@v1.0.5#Value),
+// like Level, selecting the encoder of the logs.
+type Format struct {
+	value string
+}
+
+func GetSupportedFormats() [2]string {
+	return [2]string{FormatJSON, FormatConsole}
+}
+
+// SetZeroLogFormat makes the global zerolog logger, used by all the packages,
+// write the logs to stderr with the selected encoder.
+func (f *Format) SetZeroLogFormat() {
+	if f.String() == FormatConsole {
+		zlog.Logger = zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr}).With().Timestamp().Logger()
+	} else {
+		zlog.Logger = zerolog.New(os.Stderr).With().Timestamp().Logger()
+	}
+}
+
+func (f *Format) String() string {
+	if f.value == "" {
+		return FormatJSON
+	}
+	return f.value
+}
+
+func (f *Format) Set(format string) error {
+	supportedFormats := GetSupportedFormats()
+	if !slices.Contains(supportedFormats[:], format) {
+		return fmt.Errorf("supported values: %s", supportedFormats)
+	}
+	f.value = format
+
+	return nil
+}
+
+func (f *Format) Type() string {
+	return "string"
+}
@@ -25,6 +25,14 @@ func (l *Level) SetZeroLogLevel() {
 	}
 }
 
+// SetQuiet raises the level of the logs to warn, keeping only the warnings and
+// the errors, unless a higher level is set already.
+func SetQuiet() {
+	if zerolog.GlobalLevel() < zerolog.WarnLevel {
+		zerolog.SetGlobalLevel(zerolog.WarnLevel)
+	}
+}
+
 func (l *Level) String() string {
 	if l.value == "" {
 		return "info"
@@ -0,0 +1,96 @@
+package log
+
+import (
+	"flag"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	"github.com/rs/zerolog"
+	zlog "github.com/rs/zerolog/log"
+	"k8s.io/klog/v2"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// klogVerbosityTrace is the klog verbosity used at trace level, enabling all
+// the messages of the Kubernetes client libraries.
+const klogVerbosityTrace = 10
+
+// RedirectLibraryLogs makes the Kubernetes client libraries, which log with
+// klog and logr, write their logs with the global zerolog logger. This way
+// zerolog is the only logging library writing to stderr, and --loglevel and
+// --log-format govern all the logs. It must be called after the level of the
+// logs is set, the verbosity of klog is derived from it.
+func RedirectLibraryLogs() {
+	logger := logr.New(&zerologSink{})
+	klog.SetLogger(logger)
+	ctrllog.SetLogger(logger)
+
+	verbosity := 0
+	switch zerolog.GlobalLevel() {
+	case zerolog.TraceLevel:
+		verbosity = klogVerbosityTrace
+	case zerolog.DebugLevel:
+		verbosity = 1
+	default:
+	}
+	flags := flag.NewFlagSet("klog", flag.ContinueOnError)
+	klog.InitFlags(flags)
+	if err := flags.Set("v", strconv.Itoa(verbosity)); err != nil {
+		zlog.Warn().Err(err).Msg("cannot set the verbosity of the Kubernetes client libraries")
+	}
+}
+
+// zerologSink is a logr.LogSink writing to the global zerolog logger.
+// The logr verbosity 0 is mapped to info, 1 to debug and the higher ones to trace.
+type zerologSink struct {
+	name   string
+	values []any
+}
+
+func zerologLevel(level int) zerolog.Level {
+	switch level {
+	case 0:
+		return zerolog.InfoLevel
+	case 1:
+		return zerolog.DebugLevel
+	default:
+		return zerolog.TraceLevel
+	}
+}
+
+func (s *zerologSink) Init(logr.RuntimeInfo) {}
+
+func (s *zerologSink) Enabled(level int) bool {
+	return zerologLevel(level) >= zerolog.GlobalLevel()
+}
+
+func (s *zerologSink) Info(level int, msg string, keysAndValues ...any) {
+	s.write(zlog.WithLevel(zerologLevel(level)), msg, keysAndValues)
+}
+
+func (s *zerologSink) Error(err error, msg string, keysAndValues ...any) {
+	s.write(zlog.Error().Err(err), msg, keysAndValues)
+}
+
+func (s *zerologSink) write(event *zerolog.Event, msg string, keysAndValues []any) {
+	if s.name != "" {
+		event = event.Str("logger", s.name)
+	}
+	event.Fields(s.values).Fields(keysAndValues).Msg(msg)
+}
+
+func (s *zerologSink) WithValues(keysAndValues ...any) logr.LogSink {
+	values := make([]any, 0, len(s.values)+len(keysAndValues))
+	values = append(values, s.values...)
+	values = append(values, keysAndValues...)
+
+	return &zerologSink{name: s.name, values: values}
+}
+
+func (s *zerologSink) WithName(name string) logr.LogSink {
+	if s.name != "" {
+		name = s.name + "/" + name
+	}
+
+	return &zerologSink{name: name, values: s.values}
+}
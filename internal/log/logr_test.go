@@ -0,0 +1,36 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/rs/zerolog"
+	zlog "github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestZerologSink(t *testing.T) {
+	previousLogger, previousLevel := zlog.Logger, zerolog.GlobalLevel()
+	t.Cleanup(func() {
+		zlog.Logger = previousLogger
+		zerolog.SetGlobalLevel(previousLevel)
+	})
+
+	var buf bytes.Buffer
+	zlog.Logger = zerolog.New(&buf)
+	zerolog.SetGlobalLevel(zerolog.DebugLevel)
+
+	logger := logr.New(&zerologSink{}).WithName("client-go").WithValues("resource", "pods")
+	logger.Info("info message", "page", 1)
+	logger.V(1).Info("debug message")
+	logger.V(4).Info("trace message")
+	logger.Error(errors.New("boom"), "error message")
+
+	assert.Equal(t,
+		`{"level":"info","logger":"client-go","resource":"pods","page":1,"message":"info message"}`+"\n"+
+			`{"level":"debug","logger":"client-go","resource":"pods","message":"debug message"}`+"\n"+
+			`{"level":"error","error":"boom","logger":"client-go","resource":"pods","message":"error message"}`+"\n",
+		buf.String())
+}
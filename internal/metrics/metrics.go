@@ -0,0 +1,138 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	namespace = "audit_scanner"
+
+	// ResultPass is the result of a policy evaluation that accepted the resource.
+	ResultPass = "pass"
+	// ResultFail is the result of a policy evaluation that rejected the resource.
+	ResultFail = "fail"
+	// ResultError is the result of a policy evaluation that couldn't be completed.
+	ResultError = "error"
+
+	readHeaderTimeout = 5 * time.Second
+	shutdownTimeout   = 5 * time.Second
+)
+
+// Metrics holds the Prometheus collectors describing the progress and the
+// outcome of a scan. A nil *Metrics is valid and records nothing, so callers
+// don't need to check whether metrics are enabled.
+type Metrics struct {
+	resourcesScanned     prometheus.Counter
+	policyEvaluations    *prometheus.CounterVec
+	policyServerDuration prometheus.Histogram
+}
+
+// New creates the scan metrics and registers them with registerer.
+func New(registerer prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		resourcesScanned: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "resources_scanned_total",
+			Help:      "Number of resources audited.",
+		}),
+		policyEvaluations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "policy_evaluations_total",
+			Help:      "Number of policy evaluations, partitioned by result.",
+		}, []string{"result"}),
+		policyServerDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "policy_server_request_duration_seconds",
+			Help:      "Duration of the requests sent to the Policy Servers, retries included.",
+			Buckets:   prometheus.DefBuckets,
+		}),
+	}
+
+	for _, collector := range []prometheus.Collector{m.resourcesScanned, m.policyEvaluations, m.policyServerDuration} {
+		if err := registerer.Register(collector); err != nil {
+			return nil, fmt.Errorf("cannot register metric: %w", err)
+		}
+	}
+
+	return m, nil
+}
+
+// ResourceScanned records that a resource has been audited.
+func (m *Metrics) ResourceScanned() {
+	if m == nil {
+		return
+	}
+	m.resourcesScanned.Inc()
+}
+
+// PolicyEvaluated records the result of a policy evaluation.
+func (m *Metrics) PolicyEvaluated(result string) {
+	if m == nil {
+		return
+	}
+	m.policyEvaluations.WithLabelValues(result).Inc()
+}
+
+// ObservePolicyServerRequest records the duration of a request sent to a Policy Server.
+func (m *Metrics) ObservePolicyServerRequest(duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.policyServerDuration.Observe(duration.Seconds())
+}
+
+// Handler returns an HTTP handler serving the metrics gathered by gatherer on /metrics.
+func Handler(gatherer prometheus.Gatherer) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}))
+
+	return mux
+}
+
+// Serve exposes the metrics gathered by gatherer on the /metrics path of
+// address until ctx is done, then shuts the server down gracefully.
+// The listener is opened before returning, so an invalid or busy address is
+// reported immediately. The returned channel receives the outcome of the
+// server once it has stopped.
+func Serve(ctx context.Context, address string, gatherer prometheus.Gatherer) (<-chan error, error) {
+	listener, err := (&net.ListenConfig{}).Listen(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("cannot listen on metrics address %s: %w", address, err)
+	}
+
+	server := &http.Server{
+		Handler:           Handler(gatherer),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+		// the parent context is done, use a fresh one to bound the shutdown
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Error().Err(err).Msg("error shutting down the metrics server")
+		}
+	}()
+	go func() {
+		log.Info().Str("address", listener.Addr().String()).Msg("serving metrics")
+		err := server.Serve(listener)
+		if errors.Is(err, http.ErrServerClosed) {
+			err = nil
+		}
+		done <- err
+		close(done)
+	}()
+
+	return done, nil
+}
@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m, err := New(registry)
+	require.NoError(t, err)
+
+	m.ResourceScanned()
+	m.ResourceScanned()
+	m.PolicyEvaluated(ResultPass)
+	m.PolicyEvaluated(ResultFail)
+	m.PolicyEvaluated(ResultFail)
+	m.ObservePolicyServerRequest(100 * time.Millisecond)
+
+	assert.InDelta(t, 2, testutil.ToFloat64(m.resourcesScanned), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(m.policyEvaluations.WithLabelValues(ResultPass)), 0)
+	assert.InDelta(t, 2, testutil.ToFloat64(m.policyEvaluations.WithLabelValues(ResultFail)), 0)
+	assert.InDelta(t, 0, testutil.ToFloat64(m.policyEvaluations.WithLabelValues(ResultError)), 0)
+	assert.Equal(t, 1, testutil.CollectAndCount(m.policyServerDuration))
+}
+
+func TestNilMetrics(t *testing.T) {
+	var m *Metrics
+
+	assert.NotPanics(t, func() {
+		m.ResourceScanned()
+		m.PolicyEvaluated(ResultError)
+		m.ObservePolicyServerRequest(time.Second)
+	})
+}
+
+func TestNewRejectsDuplicateRegistration(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	_, err := New(registry)
+	require.NoError(t, err)
+
+	_, err = New(registry)
+	require.Error(t, err)
+}
+
+func TestServe(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	_, err := New(registry)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done, err := Serve(ctx, "127.0.0.1:0", registry)
+	require.NoError(t, err)
+
+	cancel()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("metrics server did not shut down")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m, err := New(registry)
+	require.NoError(t, err)
+	m.ResourceScanned()
+
+	recorder := httptest.NewRecorder()
+	Handler(registry).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "audit_scanner_resources_scanned_total 1")
+}
@@ -0,0 +1,215 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kubewarden/audit-scanner/internal/report"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	slackTimeout = 10 * time.Second
+	// slackMaxMessageLength is the maximum number of bytes of a message, Slack
+	// truncates messages longer than 4000 characters. The results not fitting
+	// are posted in further messages.
+	slackMaxMessageLength = 4000
+	// slackMaxResultMessageLength is the maximum number of bytes of the
+	// message of a single result, longer messages are truncated
+	slackMaxResultMessageLength = 200
+	// slackMaxErrorBodyLength is the maximum number of bytes of the responses
+	// of the webhook included in the errors
+	slackMaxErrorBodyLength = 512
+	ellipsis                = "…"
+)
+
+// SlackNotifier posts the failing results of a scan to a Slack channel
+// through an incoming webhook.
+type SlackNotifier struct {
+	webhookURL     string
+	maxResults     int
+	severityFilter *report.SeverityFilter
+	httpClient     *http.Client
+}
+
+// NewSlackNotifier returns a notifier posting at most maxResults failing
+// results to the incoming webhook, the most severe first. Only the results
+// included by severityFilter are posted, a nil filter includes all of them.
+func NewSlackNotifier(webhookURL string, maxResults int, severityFilter *report.SeverityFilter) (*SlackNotifier, error) {
+	if maxResults <= 0 {
+		return nil, fmt.Errorf("invalid maximum number of results %d: it must be a positive number", maxResults)
+	}
+
+	return &SlackNotifier{
+		webhookURL:     webhookURL,
+		maxResults:     maxResults,
+		severityFilter: severityFilter,
+		httpClient:     &http.Client{Timeout: slackTimeout},
+	}, nil
+}
+
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+// Notify posts the failing results, split into as many messages as needed to
+// respect the size limits of Slack. Nothing is posted when no result is
+// included by the severity filter.
+func (n *SlackNotifier) Notify(ctx context.Context, failedResults []report.FailedResult) error {
+	results := n.filterResults(failedResults)
+	if len(results) == 0 {
+		return nil
+	}
+
+	header := fmt.Sprintf("*Kubewarden audit scan*: %d failing results", len(results))
+	if len(results) > n.maxResults {
+		header = fmt.Sprintf("*Kubewarden audit scan*: %d most severe of %d failing results", n.maxResults, len(results))
+		results = results[:n.maxResults]
+	}
+	lines := make([]string, 0, len(results))
+	for _, result := range results {
+		lines = append(lines, formatSlackResult(result))
+	}
+
+	pages := paginate(lines, slackMaxMessageLength-len(header)-len(" (page 999/999)\n"))
+	for i, page := range pages {
+		text := header + "\n" + page
+		if len(pages) > 1 {
+			text = fmt.Sprintf("%s (page %d/%d)\n%s", header, i+1, len(pages), page)
+		}
+		if err := n.post(ctx, slackMessage{Text: text}); err != nil {
+			return err
+		}
+	}
+	log.Info().Int("results", len(results)).Int("messages", len(pages)).Msg("Failing results posted to Slack")
+
+	return nil
+}
+
+// filterResults returns the results included by the severity filter, sorted
+// by decreasing severity. Policy violations come before the policies that
+// could not be evaluated with the same severity.
+func (n *SlackNotifier) filterResults(failedResults []report.FailedResult) []report.FailedResult {
+	results := []report.FailedResult{}
+	for _, failedResult := range failedResults {
+		if n.severityFilter.IncludesResult(&failedResult.Result) {
+			results = append(results, failedResult)
+		}
+	}
+
+	slices.SortStableFunc(results, func(a, b report.FailedResult) int {
+		if c := report.CompareSeverity(string(b.Result.Severity), string(a.Result.Severity)); c != 0 {
+			return c
+		}
+		return strings.Compare(string(b.Result.Result), string(a.Result.Result))
+	})
+
+	return results
+}
+
+func (n *SlackNotifier) post(ctx context.Context, message slackMessage) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("cannot encode Slack message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("cannot create Slack request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := n.httpClient.Do(req)
+	if err != nil {
+		// the URL of the webhook is a secret, it must not be logged
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			urlErr.URL = "<webhook>"
+		}
+		return fmt.Errorf("cannot post message to Slack: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		resBody, _ := io.ReadAll(io.LimitReader(res.Body, slackMaxErrorBodyLength))
+		return fmt.Errorf("cannot post message to Slack, status code %d: %s", res.StatusCode, resBody)
+	}
+
+	return nil
+}
+
+// formatSlackResult formats the result as a line of the message, with the
+// namespace, the resource, the policy, the result and the message.
+func formatSlackResult(failedResult report.FailedResult) string {
+	resource := "unknown resource"
+	if scope := failedResult.Resource; scope != nil {
+		resource = scope.Kind + "/" + scope.Name
+		if scope.Namespace != "" {
+			resource = scope.Namespace + "/" + resource
+		}
+	}
+
+	line := fmt.Sprintf("• `%s` %s: *%s*", escapeSlack(resource), escapeSlack(failedResult.Result.Policy), failedResult.Result.Result)
+	if failedResult.Result.Severity != "" {
+		line += fmt.Sprintf(" (%s)", failedResult.Result.Severity)
+	}
+	if message := truncate(failedResult.Result.Description, slackMaxResultMessageLength); message != "" {
+		line += " " + escapeSlack(message)
+	}
+
+	return line
+}
+
+// paginate joins the lines into pages of at most maxLength characters.
+// Lines longer than maxLength are truncated.
+func paginate(lines []string, maxLength int) []string {
+	pages := []string{}
+	var page strings.Builder
+	for _, line := range lines {
+		line = truncate(line, maxLength)
+		if page.Len() > 0 && page.Len()+len("\n")+len(line) > maxLength {
+			pages = append(pages, page.String())
+			page.Reset()
+		}
+		if page.Len() > 0 {
+			page.WriteString("\n")
+		}
+		page.WriteString(line)
+	}
+	if page.Len() > 0 {
+		pages = append(pages, page.String())
+	}
+
+	return pages
+}
+
+// truncate shortens the text to maxLength bytes, without splitting
+// characters, ending it with an ellipsis. Line breaks are replaced by spaces.
+func truncate(text string, maxLength int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if len(text) <= maxLength {
+		return text
+	}
+
+	cut := max(maxLength-len(ellipsis), 0)
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+
+	return text[:cut] + ellipsis
+}
+
+// escapeSlack escapes the characters having a special meaning in Slack messages.
+func escapeSlack(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
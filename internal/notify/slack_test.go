@@ -0,0 +1,121 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kubewarden/audit-scanner/internal/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	wgpolicy "sigs.k8s.io/wg-policy-prototypes/policy-report/pkg/api/wgpolicyk8s.io/v1alpha2"
+)
+
+func newSlackServer(t *testing.T, messages *[]string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message slackMessage
+		if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		*messages = append(*messages, message.Text)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func newFailedResult(name, policy string, severity wgpolicy.PolicyResultSeverity, message string) report.FailedResult {
+	return report.FailedResult{
+		Resource: &corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: name},
+		Result: wgpolicy.PolicyReportResult{
+			Policy:      policy,
+			Result:      "fail",
+			Severity:    severity,
+			Description: message,
+		},
+	}
+}
+
+func TestSlackNotify(t *testing.T) {
+	var messages []string
+	server := newSlackServer(t, &messages)
+
+	severityFilter, err := report.NewSeverityFilter("medium", false)
+	require.NoError(t, err)
+	notifier, err := NewSlackNotifier(server.URL, 2, severityFilter)
+	require.NoError(t, err)
+
+	err = notifier.Notify(context.Background(), []report.FailedResult{
+		newFailedResult("low", "low-policy", "low", "not important"),
+		newFailedResult("medium", "medium-policy", "medium", "the image uses <latest>"),
+		newFailedResult("critical", "critical-policy", "critical", "privileged\ncontainer"),
+		newFailedResult("high", "high-policy", "high", ""),
+	})
+	require.NoError(t, err)
+
+	require.Len(t, messages, 1)
+	assert.Equal(t, "*Kubewarden audit scan*: 2 most severe of 3 failing results\n"+
+		"• `default/Pod/critical` critical-policy: *fail* (critical) privileged container\n"+
+		"• `default/Pod/high` high-policy: *fail* (high)",
+		messages[0])
+}
+
+func TestSlackNotifyPaginates(t *testing.T) {
+	var messages []string
+	server := newSlackServer(t, &messages)
+
+	notifier, err := NewSlackNotifier(server.URL, 100, nil)
+	require.NoError(t, err)
+
+	failedResults := []report.FailedResult{}
+	for i := range 100 {
+		failedResults = append(failedResults, newFailedResult(fmt.Sprintf("pod-%d", i), "policy", "", strings.Repeat("x", 1000)))
+	}
+	require.NoError(t, notifier.Notify(context.Background(), failedResults))
+
+	require.Greater(t, len(messages), 1)
+	lines := 0
+	for i, message := range messages {
+		assert.LessOrEqual(t, len(message), slackMaxMessageLength)
+		assert.True(t, strings.HasPrefix(message, fmt.Sprintf("*Kubewarden audit scan*: 100 failing results (page %d/%d)\n", i+1, len(messages))))
+		lines += strings.Count(message, "\n•")
+	}
+	assert.Equal(t, 100, lines, "every result must be posted")
+}
+
+func TestSlackNotifyNothingToReport(t *testing.T) {
+	var messages []string
+	server := newSlackServer(t, &messages)
+
+	severityFilter, err := report.NewSeverityFilter("critical", false)
+	require.NoError(t, err)
+	notifier, err := NewSlackNotifier(server.URL, 10, severityFilter)
+	require.NoError(t, err)
+
+	require.NoError(t, notifier.Notify(context.Background(), []report.FailedResult{
+		newFailedResult("pod", "policy", "low", "not important"),
+	}))
+	assert.Empty(t, messages)
+}
+
+func TestSlackNotifyError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, "no_service")
+	}))
+	defer server.Close()
+
+	notifier, err := NewSlackNotifier(server.URL, 10, nil)
+	require.NoError(t, err)
+
+	err = notifier.Notify(context.Background(), []report.FailedResult{newFailedResult("pod", "policy", "", "")})
+	require.ErrorContains(t, err, "status code 404: no_service")
+}
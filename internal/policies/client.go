@@ -28,6 +28,107 @@ type Client struct {
 	// FQDN of the policy server to query. If not empty, it will query on port 3000.
 	// Useful for out-of-cluster debugging
 	policyServerURL string
+	// policyServerName restricts policyServerURL to the policies run by the
+	// PolicyServer with this name, the URLs of the other PolicyServers are
+	// resolved as usual. It applies to all the policies when it's empty
+	policyServerName string
+	// policyNames restricts the audited policies to the ones with these names.
+	// All the policies are audited when it's empty
+	policyNames map[string]struct{}
+	// modeFilter restricts the audited policies to the ones in a mode
+	modeFilter ModeFilter
+	// includedKinds restricts the audited resources to the ones of these
+	// kinds. All the kinds are audited when it's empty
+	includedKinds []schema.GroupKind
+	// excludedKinds are never audited, even when they are among includedKinds
+	excludedKinds []schema.GroupKind
+	// extraGVRs are audited by the policies with rules matching them, wildcards
+	// included, besides the resources targeted explicitly by their rules
+	extraGVRs []schema.GroupVersionResource
+	// operation is the operation of the AdmissionRequests sent to the policies,
+	// only the policies with rules for it are audited
+	operation admissionregistrationv1.OperationType
+}
+
+// ModeFilter selects the policies to be audited by their mode.
+type ModeFilter string
+
+const (
+	// ModeFilterBoth audits the policies in protect and in monitor mode.
+	ModeFilterBoth ModeFilter = "both"
+	// ModeFilterProtect audits only the policies in protect mode.
+	ModeFilterProtect ModeFilter = "protect"
+	// ModeFilterMonitor audits only the policies in monitor mode.
+	ModeFilterMonitor ModeFilter = "monitor"
+)
+
+// SupportedModeFilters returns the mode filters accepted by WithModeFilter.
+func SupportedModeFilters() []ModeFilter {
+	return []ModeFilter{ModeFilterBoth, ModeFilterProtect, ModeFilterMonitor}
+}
+
+// ClientOption configures optional behaviour of a Client.
+type ClientOption func(*Client)
+
+// WithPolicyNames restricts the policies returned by the Client to the ones
+// with the given names. The other policies are counted as skipped.
+func WithPolicyNames(names ...string) ClientOption {
+	return func(c *Client) {
+		if len(names) == 0 {
+			return
+		}
+		c.policyNames = make(map[string]struct{}, len(names))
+		for _, name := range names {
+			c.policyNames[name] = struct{}{}
+		}
+	}
+}
+
+// WithPolicyServerName restricts the PolicyServer URL given to NewClient to the
+// policies run by the PolicyServer with the given name. The policies run by the
+// other PolicyServers are sent to their in-cluster Services.
+func WithPolicyServerName(name string) ClientOption {
+	return func(c *Client) {
+		c.policyServerName = name
+	}
+}
+
+// WithModeFilter restricts the policies returned by the Client to the ones in
+// the mode selected by the filter. The other policies are counted as skipped.
+// The policies in both modes are returned by default.
+func WithModeFilter(modeFilter ModeFilter) ClientOption {
+	return func(c *Client) {
+		c.modeFilter = modeFilter
+	}
+}
+
+// WithKindFilter restricts the resources audited by the policies to the ones
+// of the included kinds, all the kinds when none is given, and not of the
+// excluded kinds. A kind without a group matches the kinds with that name in
+// all the groups. The resources of the other kinds are never listed.
+func WithKindFilter(included, excluded []schema.GroupKind) ClientOption {
+	return func(c *Client) {
+		c.includedKinds = included
+		c.excludedKinds = excluded
+	}
+}
+
+// WithExtraGVRs adds the given resources to the ones audited by the policies
+// whose rules match them, e.g. a custom resource targeted only by a wildcard
+// rule, which would be skipped otherwise. The kind filter still applies.
+func WithExtraGVRs(gvrs ...schema.GroupVersionResource) ClientOption {
+	return func(c *Client) {
+		c.extraGVRs = gvrs
+	}
+}
+
+// WithOperation sets the operation of the AdmissionRequests the resources are
+// audited with, CREATE by default. Only the policies with rules for this
+// operation are returned, the other ones are counted as skipped.
+func WithOperation(operation admissionregistrationv1.OperationType) ClientOption {
+	return func(c *Client) {
+		c.operation = operation
+	}
 }
 
 // Policies represents a collection of auditable policies.
@@ -49,16 +150,89 @@ type Policy struct {
 }
 
 // NewClient returns a policy Client.
-func NewClient(client client.Client, kubewardenNamespace string, policyServerURL string) (*Client, error) {
-	if policyServerURL != "" {
-		log.Info().Msg(fmt.Sprintf("querying PolicyServers at %s for debugging purposes. Don't forget to start `kubectl port-forward` if needed", policyServerURL))
-	}
-
-	return &Client{
+func NewClient(client client.Client, kubewardenNamespace string, policyServerURL string, opts ...ClientOption) (*Client, error) {
+	policiesClient := &Client{
 		client:              client,
 		kubewardenNamespace: kubewardenNamespace,
 		policyServerURL:     policyServerURL,
-	}, nil
+		modeFilter:          ModeFilterBoth,
+		operation:           admissionregistrationv1.Create,
+	}
+	for _, opt := range opts {
+		opt(policiesClient)
+	}
+	if !slices.Contains(SupportedModeFilters(), policiesClient.modeFilter) {
+		return nil, fmt.Errorf("unsupported mode filter %q, supported values are: %v", policiesClient.modeFilter, SupportedModeFilters())
+	}
+
+	switch {
+	case policyServerURL != "" && policiesClient.policyServerName != "":
+		log.Info().Msg(fmt.Sprintf("querying PolicyServer %s at %s for debugging purposes. Don't forget to start `kubectl port-forward` if needed", policiesClient.policyServerName, policyServerURL))
+	case policyServerURL != "":
+		log.Info().Msg(fmt.Sprintf("querying PolicyServers at %s for debugging purposes. Don't forget to start `kubectl port-forward` if needed", policyServerURL))
+	}
+
+	return policiesClient, nil
+}
+
+// MissingPolicyNames returns the names given with WithPolicyNames that don't
+// match any policy in the cluster, sorted alphabetically.
+func (f *Client) MissingPolicyNames(ctx context.Context) ([]string, error) {
+	if len(f.policyNames) == 0 {
+		return nil, nil
+	}
+
+	var policies []policiesv1.Policy
+
+	clusterAdmissionPolicies, err := f.listClusterAdmissionPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, policy := range clusterAdmissionPolicies {
+		policies = append(policies, &policy)
+	}
+
+	clusterAdmissionPolicyGroups, err := f.listClusterAdmissionPolicyGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, policy := range clusterAdmissionPolicyGroups {
+		policies = append(policies, &policy)
+	}
+
+	// an empty namespace lists the namespaced policies of all the namespaces
+	allNamespaces := &corev1.Namespace{}
+	admissionPolicies, err := f.listAdmissionPolicies(ctx, allNamespaces)
+	if err != nil {
+		return nil, err
+	}
+	for _, policy := range admissionPolicies {
+		policies = append(policies, &policy)
+	}
+
+	admissionPolicyGroups, err := f.listAdmissionPolicyGroups(ctx, allNamespaces)
+	if err != nil {
+		return nil, err
+	}
+	for _, policy := range admissionPolicyGroups {
+		policies = append(policies, &policy)
+	}
+
+	missing := make(map[string]struct{}, len(f.policyNames))
+	for name := range f.policyNames {
+		missing[name] = struct{}{}
+	}
+	for _, policy := range policies {
+		delete(missing, policy.GetName())
+	}
+
+	missingNames := make([]string, 0, len(missing))
+	for name := range missing {
+		missingNames = append(missingNames, name)
+	}
+	slices.Sort(missingNames)
+
+	return missingNames, nil
 }
 
 // GetPoliciesByNamespace gets all the auditable policies for a given namespace.
@@ -240,8 +414,36 @@ func (f *Client) groupPoliciesByGVR(ctx context.Context, policies []policiesv1.P
 	erroredPolicies := map[string]struct{}{}
 
 	for _, policy := range policies {
+		if !f.isPolicySelected(policy) {
+			skippedPolicies[policy.GetUniqueName()] = struct{}{}
+			log.
+				Debug().
+				Str("policy", policy.GetUniqueName()).
+				Msg("the policy is not among the selected policies, skipping...")
+
+			continue
+		}
+
+		if !f.isPolicyModeSelected(policy) {
+			skippedPolicies[policy.GetUniqueName()] = struct{}{}
+			log.
+				Debug().
+				Str("policy", policy.GetUniqueName()).
+				Str("mode", string(policy.GetPolicyMode())).
+				Msg("the policy mode is not the selected one, skipping...")
+
+			continue
+		}
+
+		extraGVRs, err := f.getExtraGroupVersionResources(policy.GetRules(), namespaced)
+		if err != nil {
+			erroredPolicies[policy.GetUniqueName()] = struct{}{}
+			log.Error().Err(err).Str("policy", policy.GetUniqueName()).Msg("failed to obtain the extra GroupVersion resources matched by the policy, skipping as error...")
+			continue
+		}
+
 		rules := filterWildcardRules(policy.GetRules())
-		if len(rules) == 0 {
+		if len(rules) == 0 && len(extraGVRs) == 0 {
 			skippedPolicies[policy.GetUniqueName()] = struct{}{}
 			log.
 				Debug().
@@ -251,13 +453,14 @@ func (f *Client) groupPoliciesByGVR(ctx context.Context, policies []policiesv1.P
 			continue
 		}
 
-		rules = filterNonCreateOperations(rules)
-		if len(rules) == 0 {
+		rules = filterRulesByOperation(rules, f.operation)
+		if len(rules) == 0 && len(extraGVRs) == 0 {
 			skippedPolicies[policy.GetUniqueName()] = struct{}{}
 			log.
 				Debug().
 				Str("policy", policy.GetUniqueName()).
-				Msg("the policy does not have rules with a CREATE operation, skipping...")
+				Str("operation", string(f.operation)).
+				Msg("the policy does not have rules with the audited operation, skipping...")
 
 			continue
 		}
@@ -268,6 +471,11 @@ func (f *Client) groupPoliciesByGVR(ctx context.Context, policies []policiesv1.P
 			log.Error().Err(err).Str("policy", policy.GetUniqueName()).Msg("failed to obtain unknown GroupVersion resources. The policy may be misconfigured, skipping as error...")
 			continue
 		}
+		for _, gvr := range extraGVRs {
+			if !slices.Contains(groupVersionResources, gvr) {
+				groupVersionResources = append(groupVersionResources, gvr)
+			}
+		}
 
 		if len(groupVersionResources) == 0 {
 			log.
@@ -319,6 +527,30 @@ func (f *Client) groupPoliciesByGVR(ctx context.Context, policies []policiesv1.P
 	}, nil
 }
 
+// isPolicySelected checks if the policy is among the ones selected with WithPolicyNames.
+func (f *Client) isPolicySelected(policy policiesv1.Policy) bool {
+	if len(f.policyNames) == 0 {
+		return true
+	}
+	_, found := f.policyNames[policy.GetName()]
+
+	return found
+}
+
+// isPolicyModeSelected checks if the policy is in the mode selected with WithModeFilter.
+// The policies without a mode are in protect mode, the default one.
+func (f *Client) isPolicyModeSelected(policy policiesv1.Policy) bool {
+	if f.modeFilter == ModeFilterBoth {
+		return true
+	}
+	mode := policy.GetPolicyMode()
+	if mode == "" {
+		mode = policiesv1.PolicyMode(ModeFilterProtect)
+	}
+
+	return string(mode) == string(f.modeFilter)
+}
+
 func addPolicyToMap(policiesByGVR map[schema.GroupVersionResource][]*Policy, gvr schema.GroupVersionResource, policy *Policy) {
 	value, found := policiesByGVR[gvr]
 	if !found {
@@ -352,16 +584,11 @@ func (f *Client) getGroupVersionResources(rules []admissionregistrationv1.RuleWi
 	for _, rule := range rules {
 		gvrs := getRuleGVRs(rule)
 		for _, gvr := range gvrs {
-			isNamespaced, err := f.isNamespacedResource(gvr)
+			selected, err := f.isGVRSelected(gvr, namespaced)
 			if err != nil {
 				return nil, err
 			}
-			if namespaced && !isNamespaced {
-				// continue if resource is clusterwide
-				continue
-			}
-			if !namespaced && isNamespaced {
-				// continue if resource is namespaced
+			if !selected {
 				continue
 			}
 
@@ -372,6 +599,48 @@ func (f *Client) getGroupVersionResources(rules []admissionregistrationv1.RuleWi
 	return groupVersionResources, nil
 }
 
+// getExtraGroupVersionResources returns the resources given with WithExtraGVRs
+// that are matched by the rules, wildcards included, and are in the scope.
+func (f *Client) getExtraGroupVersionResources(rules []admissionregistrationv1.RuleWithOperations, namespaced bool) ([]schema.GroupVersionResource, error) {
+	var groupVersionResources []schema.GroupVersionResource
+
+	for _, gvr := range f.extraGVRs {
+		if !RulesMatch(rules, gvr, f.operation) {
+			continue
+		}
+		selected, err := f.isGVRSelected(gvr, namespaced)
+		if err != nil {
+			return nil, err
+		}
+		if !selected {
+			continue
+		}
+
+		groupVersionResources = append(groupVersionResources, gvr)
+	}
+
+	return groupVersionResources, nil
+}
+
+// isGVRSelected checks if the given resource is in the scope, namespaced or
+// cluster-wide, and if its kind is selected with WithKindFilter.
+func (f *Client) isGVRSelected(gvr schema.GroupVersionResource, namespaced bool) (bool, error) {
+	isNamespaced, err := f.isNamespacedResource(gvr)
+	if err != nil {
+		return false, err
+	}
+	if namespaced && !isNamespaced {
+		// skip the resource if it's clusterwide
+		return false, nil
+	}
+	if !namespaced && isNamespaced {
+		// skip the resource if it's namespaced
+		return false, nil
+	}
+
+	return f.isKindSelected(gvr)
+}
+
 // isNamespacedResource checks if the given resource is namespaced or not.
 func (f *Client) isNamespacedResource(gvr schema.GroupVersionResource) (bool, error) {
 	gvk, err := f.client.RESTMapper().KindFor(gvr)
@@ -387,6 +656,26 @@ func (f *Client) isNamespacedResource(gvr schema.GroupVersionResource) (bool, er
 	return mapping.Scope.Name() == meta.RESTScopeNameNamespace, nil
 }
 
+// isKindSelected checks if the kind of the given resource is selected with WithKindFilter.
+func (f *Client) isKindSelected(gvr schema.GroupVersionResource) (bool, error) {
+	if len(f.includedKinds) == 0 && len(f.excludedKinds) == 0 {
+		return true, nil
+	}
+	gvk, err := f.client.RESTMapper().KindFor(gvr)
+	if err != nil {
+		return false, err
+	}
+
+	matches := func(kind schema.GroupKind) bool {
+		return kind.Kind == gvk.Kind && (kind.Group == "" || kind.Group == gvk.Group)
+	}
+	if slices.ContainsFunc(f.excludedKinds, matches) {
+		return false, nil
+	}
+
+	return len(f.includedKinds) == 0 || slices.ContainsFunc(f.includedKinds, matches), nil
+}
+
 func (f *Client) getPolicyServerURLRunningPolicy(ctx context.Context, policy policiesv1.Policy) (*url.URL, error) {
 	policyServer, err := f.getPolicyServerByName(ctx, policy.GetPolicyServer())
 	if err != nil {
@@ -400,7 +689,7 @@ func (f *Client) getPolicyServerURLRunningPolicy(ctx context.Context, policy pol
 		return nil, errors.New("policy server service does not have a port")
 	}
 	var urlStr string
-	if f.policyServerURL != "" {
+	if f.overridesPolicyServerURL(policyServer.GetName()) {
 		url, err := url.Parse(f.policyServerURL)
 		if err != nil {
 			log.Fatal().Msg("incorrect URL for policy-server")
@@ -416,6 +705,12 @@ func (f *Client) getPolicyServerURLRunningPolicy(ctx context.Context, policy pol
 	return url, nil
 }
 
+// overridesPolicyServerURL returns true when the policies run by the given
+// PolicyServer must be sent to the URL given to NewClient.
+func (f *Client) overridesPolicyServerURL(policyServerName string) bool {
+	return f.policyServerURL != "" && (f.policyServerName == "" || f.policyServerName == policyServerName)
+}
+
 func (f *Client) getPolicyServerByName(ctx context.Context, policyServerName string) (*policiesv1.PolicyServer, error) {
 	var policyServer policiesv1.PolicyServer
 
@@ -456,16 +751,38 @@ func filterWildcardRules(rules []admissionregistrationv1.RuleWithOperations) []a
 	return filteredRules
 }
 
-// filterNonCreateOperations filters out rules that do not contain a CREATE operation.
-func filterNonCreateOperations(rules []admissionregistrationv1.RuleWithOperations) []admissionregistrationv1.RuleWithOperations {
+// filterRulesByOperation filters out rules that do not contain the given operation.
+func filterRulesByOperation(rules []admissionregistrationv1.RuleWithOperations, operation admissionregistrationv1.OperationType) []admissionregistrationv1.RuleWithOperations {
 	filteredRules := []admissionregistrationv1.RuleWithOperations{}
 	for _, rule := range rules {
-		for _, operation := range rule.Operations {
-			if operation == admissionregistrationv1.Create {
-				filteredRules = append(filteredRules, rule)
-			}
+		if ruleMatchesOperation(rule, operation) {
+			filteredRules = append(filteredRules, rule)
 		}
 	}
 
 	return filteredRules
 }
+
+// RulesMatch returns true if at least one of the rules targets the given resource and operation.
+// Wildcards in the rules are honored.
+func RulesMatch(rules []admissionregistrationv1.RuleWithOperations, gvr schema.GroupVersionResource, operation admissionregistrationv1.OperationType) bool {
+	for _, rule := range rules {
+		if ruleMatchesOperation(rule, operation) &&
+			matchesOrWildcard(rule.APIGroups, gvr.Group) &&
+			matchesOrWildcard(rule.APIVersions, gvr.Version) &&
+			matchesOrWildcard(rule.Resources, gvr.Resource) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func ruleMatchesOperation(rule admissionregistrationv1.RuleWithOperations, operation admissionregistrationv1.OperationType) bool {
+	return slices.Contains(rule.Operations, operation) ||
+		slices.Contains(rule.Operations, admissionregistrationv1.OperationAll)
+}
+
+func matchesOrWildcard(values []string, value string) bool {
+	return slices.Contains(values, value) || slices.Contains(values, "*")
+}
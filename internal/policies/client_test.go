@@ -505,3 +505,637 @@ func TestGetClusterWidePolicies(t *testing.T) {
 
 	assert.EqualValues(t, expectedPolicies, policies)
 }
+
+func TestRulesMatch(t *testing.T) {
+	podsGVR := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+
+	tests := []struct {
+		name      string
+		rule      admissionregistrationv1.RuleWithOperations
+		operation admissionregistrationv1.OperationType
+		expected  bool
+	}{
+		{
+			"exact match",
+			admissionregistrationv1.RuleWithOperations{
+				Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.Create},
+				Rule:       admissionregistrationv1.Rule{APIGroups: []string{""}, APIVersions: []string{"v1"}, Resources: []string{"pods"}},
+			},
+			admissionregistrationv1.Create,
+			true,
+		},
+		{
+			"wildcards",
+			admissionregistrationv1.RuleWithOperations{
+				Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.OperationAll},
+				Rule:       admissionregistrationv1.Rule{APIGroups: []string{"*"}, APIVersions: []string{"*"}, Resources: []string{"*"}},
+			},
+			admissionregistrationv1.Create,
+			true,
+		},
+		{
+			"different group",
+			admissionregistrationv1.RuleWithOperations{
+				Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.Create},
+				Rule:       admissionregistrationv1.Rule{APIGroups: []string{"apps"}, APIVersions: []string{"v1"}, Resources: []string{"pods"}},
+			},
+			admissionregistrationv1.Create,
+			false,
+		},
+		{
+			"different operation",
+			admissionregistrationv1.RuleWithOperations{
+				Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.Delete},
+				Rule:       admissionregistrationv1.Rule{APIGroups: []string{""}, APIVersions: []string{"v1"}, Resources: []string{"pods"}},
+			},
+			admissionregistrationv1.Create,
+			false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, RulesMatch([]admissionregistrationv1.RuleWithOperations{test.rule}, podsGVR, test.operation))
+		})
+	}
+}
+
+func TestFilterRulesByOperation(t *testing.T) {
+	rules := []admissionregistrationv1.RuleWithOperations{
+		{Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.Create}},
+		{Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.OperationAll}},
+		{Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.Update, admissionregistrationv1.Delete}},
+	}
+
+	assert.Len(t, filterRulesByOperation(rules, admissionregistrationv1.Create), 2)
+	assert.Equal(t, rules[1:], filterRulesByOperation(rules, admissionregistrationv1.Update))
+}
+
+func TestGetPoliciesFilteredByName(t *testing.T) {
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test",
+		},
+	}
+
+	policyServer := &policiesv1.PolicyServer{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+	}
+
+	policyServerService := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				"app": "kubewarden-policy-server-default",
+			},
+			Name:      "policy-server-default",
+			Namespace: "kubewarden",
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{
+					Name: "http",
+					Port: 443,
+				},
+			},
+		},
+	}
+
+	selectedPolicy := testutils.
+		NewClusterAdmissionPolicyFactory().
+		Name("selected").
+		Rule(admissionregistrationv1.Rule{
+			APIGroups:   []string{""},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"pods"},
+		}).
+		Status(policiesv1.PolicyStatusActive).
+		Build()
+
+	otherPolicy := testutils.
+		NewClusterAdmissionPolicyFactory().
+		Name("other").
+		Rule(admissionregistrationv1.Rule{
+			APIGroups:   []string{""},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"pods"},
+		}).
+		Status(policiesv1.PolicyStatusActive).
+		Build()
+
+	selectedAdmissionPolicy := testutils.
+		NewAdmissionPolicyFactory().
+		Name("selected-namespaced").
+		Namespace("test").
+		Rule(admissionregistrationv1.Rule{
+			APIGroups:   []string{""},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"pods"},
+		}).
+		Status(policiesv1.PolicyStatusActive).
+		Build()
+
+	client, err := testutils.NewFakeClient(
+		namespace,
+		policyServer,
+		policyServerService,
+		selectedPolicy,
+		otherPolicy,
+		selectedAdmissionPolicy,
+	)
+	require.NoError(t, err)
+
+	policiesClient, err := NewClient(client, "kubewarden", "", WithPolicyNames("selected", "selected-namespaced", "missing"))
+	require.NoError(t, err)
+
+	policies, err := policiesClient.GetPoliciesByNamespace(context.Background(), namespace)
+	require.NoError(t, err)
+
+	podsGVR := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+	require.Len(t, policies.PoliciesByGVR[podsGVR], 2)
+	assert.Equal(t, "selected", policies.PoliciesByGVR[podsGVR][0].GetName())
+	assert.Equal(t, "selected-namespaced", policies.PoliciesByGVR[podsGVR][1].GetName())
+	assert.Equal(t, 2, policies.PolicyNum)
+	// the policies not selected are counted as skipped
+	assert.Equal(t, 1, policies.SkippedNum)
+
+	missingPolicyNames, err := policiesClient.MissingPolicyNames(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"missing"}, missingPolicyNames)
+}
+
+func TestGetPoliciesWithPolicyServerURL(t *testing.T) {
+	newPolicyServer := func(name string) (*policiesv1.PolicyServer, *corev1.Service) {
+		policyServer := &policiesv1.PolicyServer{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+		}
+		service := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					"app": policyServer.AppLabel(),
+				},
+				Name:      "policy-server-" + name,
+				Namespace: "kubewarden",
+			},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{
+					{
+						Name: "http",
+						Port: 443,
+					},
+				},
+			},
+		}
+
+		return policyServer, service
+	}
+	defaultPolicyServer, defaultService := newPolicyServer("default")
+	debugPolicyServer, debugService := newPolicyServer("debug")
+
+	namespacesRule := admissionregistrationv1.Rule{
+		APIGroups:   []string{""},
+		APIVersions: []string{"v1"},
+		Resources:   []string{"namespaces"},
+	}
+	defaultPolicy := testutils.NewClusterAdmissionPolicyFactory().Name("on-default").Rule(namespacesRule).Build()
+	debugPolicy := testutils.NewClusterAdmissionPolicyFactory().Name("on-debug").PolicyServer("debug").Rule(namespacesRule).Build()
+
+	client, err := testutils.NewFakeClient(
+		defaultPolicyServer,
+		defaultService,
+		debugPolicyServer,
+		debugService,
+		defaultPolicy,
+		debugPolicy,
+	)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name                 string
+		opts                 []ClientOption
+		expectedPolicyServer map[string]string
+	}{
+		{
+			"the URL applies to all the PolicyServers",
+			nil,
+			map[string]string{
+				"on-default": "https://localhost:3000/audit/clusterwide-on-default",
+				"on-debug":   "https://localhost:3000/audit/clusterwide-on-debug",
+			},
+		},
+		{
+			"the URL applies to the named PolicyServer only",
+			[]ClientOption{WithPolicyServerName("debug")},
+			map[string]string{
+				"on-default": "https://policy-server-default.kubewarden.svc:443/audit/clusterwide-on-default",
+				"on-debug":   "https://localhost:3000/audit/clusterwide-on-debug",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			policiesClient, err := NewClient(client, "kubewarden", "https://localhost:3000", test.opts...)
+			require.NoError(t, err)
+
+			policies, err := policiesClient.GetClusterWidePolicies(context.Background())
+			require.NoError(t, err)
+
+			namespacesGVR := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "namespaces"}
+			policyServers := map[string]string{}
+			for _, policy := range policies.PoliciesByGVR[namespacesGVR] {
+				policyServers[policy.GetName()] = policy.PolicyServer.String()
+			}
+			assert.Equal(t, test.expectedPolicyServer, policyServers)
+		})
+	}
+}
+
+func TestGetPoliciesFilteredByMode(t *testing.T) {
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test",
+		},
+	}
+
+	policyServer := &policiesv1.PolicyServer{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+	}
+
+	policyServerService := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				"app": "kubewarden-policy-server-default",
+			},
+			Name:      "policy-server-default",
+			Namespace: "kubewarden",
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{
+					Name: "http",
+					Port: 443,
+				},
+			},
+		},
+	}
+
+	podsRule := admissionregistrationv1.Rule{
+		APIGroups:   []string{""},
+		APIVersions: []string{"v1"},
+		Resources:   []string{"pods"},
+	}
+	protectPolicy := testutils.
+		NewClusterAdmissionPolicyFactory().
+		Name("protect").
+		Rule(podsRule).
+		Mode("protect").
+		Build()
+	// the policies without a mode are in protect mode
+	defaultModePolicy := testutils.
+		NewClusterAdmissionPolicyFactory().
+		Name("default-mode").
+		Rule(podsRule).
+		Mode("").
+		Build()
+	monitorPolicy := testutils.
+		NewClusterAdmissionPolicyFactory().
+		Name("monitor").
+		Rule(podsRule).
+		Mode("monitor").
+		Build()
+	monitorAdmissionPolicy := testutils.
+		NewAdmissionPolicyFactory().
+		Name("monitor-namespaced").
+		Namespace("test").
+		Rule(podsRule).
+		Mode("monitor").
+		Build()
+
+	client, err := testutils.NewFakeClient(
+		namespace,
+		policyServer,
+		policyServerService,
+		protectPolicy,
+		defaultModePolicy,
+		monitorPolicy,
+		monitorAdmissionPolicy,
+	)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name             string
+		modeFilter       ModeFilter
+		expectedPolicies []string
+		expectedSkipped  int
+	}{
+		{"both modes", ModeFilterBoth, []string{"default-mode", "monitor", "monitor-namespaced", "protect"}, 0},
+		{"protect mode", ModeFilterProtect, []string{"default-mode", "protect"}, 2},
+		{"monitor mode", ModeFilterMonitor, []string{"monitor", "monitor-namespaced"}, 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			policiesClient, err := NewClient(client, "kubewarden", "", WithModeFilter(test.modeFilter))
+			require.NoError(t, err)
+
+			policies, err := policiesClient.GetPoliciesByNamespace(context.Background(), namespace)
+			require.NoError(t, err)
+
+			podsGVR := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+			policyNames := []string{}
+			for _, policy := range policies.PoliciesByGVR[podsGVR] {
+				policyNames = append(policyNames, policy.GetName())
+			}
+			assert.ElementsMatch(t, test.expectedPolicies, policyNames)
+			assert.Equal(t, len(test.expectedPolicies), policies.PolicyNum)
+			// the policies in the other mode are counted as skipped
+			assert.Equal(t, test.expectedSkipped, policies.SkippedNum)
+		})
+	}
+
+	_, err = NewClient(client, "kubewarden", "", WithModeFilter("audit"))
+	require.Error(t, err)
+}
+
+func TestGetPoliciesSkipsBackgroundAuditDisabled(t *testing.T) {
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test",
+		},
+	}
+
+	policyServer := &policiesv1.PolicyServer{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+	}
+
+	policyServerService := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				"app": "kubewarden-policy-server-default",
+			},
+			Name:      "policy-server-default",
+			Namespace: "kubewarden",
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{
+					Name: "http",
+					Port: 443,
+				},
+			},
+		},
+	}
+
+	podsRule := admissionregistrationv1.Rule{
+		APIGroups:   []string{""},
+		APIVersions: []string{"v1"},
+		Resources:   []string{"pods"},
+	}
+	namespacesRule := admissionregistrationv1.Rule{
+		APIGroups:   []string{""},
+		APIVersions: []string{"v1"},
+		Resources:   []string{"namespaces"},
+	}
+
+	client, err := testutils.NewFakeClient(
+		namespace,
+		policyServer,
+		policyServerService,
+		testutils.NewClusterAdmissionPolicyFactory().Name("audited").Rule(podsRule).Rule(namespacesRule).Build(),
+		testutils.NewClusterAdmissionPolicyFactory().Name("not-audited").Rule(podsRule).Rule(namespacesRule).BackgroundAudit(false).Build(),
+		testutils.NewAdmissionPolicyFactory().Name("audited-namespaced").Namespace("test").Rule(podsRule).Build(),
+		testutils.NewAdmissionPolicyFactory().Name("not-audited-namespaced").Namespace("test").Rule(podsRule).BackgroundAudit(false).Build(),
+	)
+	require.NoError(t, err)
+
+	policiesClient, err := NewClient(client, "kubewarden", "")
+	require.NoError(t, err)
+
+	policyNames := func(policies []*Policy) []string {
+		names := []string{}
+		for _, policy := range policies {
+			names = append(names, policy.GetName())
+		}
+		return names
+	}
+
+	namespacedPolicies, err := policiesClient.GetPoliciesByNamespace(context.Background(), namespace)
+	require.NoError(t, err)
+	podsGVR := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+	assert.ElementsMatch(t, []string{"audited", "audited-namespaced"}, policyNames(namespacedPolicies.PoliciesByGVR[podsGVR]))
+	assert.Equal(t, 2, namespacedPolicies.PolicyNum)
+	assert.Equal(t, 2, namespacedPolicies.SkippedNum)
+
+	clusterWidePolicies, err := policiesClient.GetClusterWidePolicies(context.Background())
+	require.NoError(t, err)
+	namespacesGVR := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "namespaces"}
+	assert.Equal(t, []string{"audited"}, policyNames(clusterWidePolicies.PoliciesByGVR[namespacesGVR]))
+	assert.Equal(t, 1, clusterWidePolicies.PolicyNum)
+	assert.Equal(t, 1, clusterWidePolicies.SkippedNum)
+}
+
+func TestGetPoliciesFilteredByKind(t *testing.T) {
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test",
+		},
+	}
+
+	policyServer := &policiesv1.PolicyServer{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+	}
+
+	policyServerService := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				"app": "kubewarden-policy-server-default",
+			},
+			Name:      "policy-server-default",
+			Namespace: "kubewarden",
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{
+					Name: "http",
+					Port: 443,
+				},
+			},
+		},
+	}
+
+	policy := testutils.
+		NewClusterAdmissionPolicyFactory().
+		Name("pods-and-deployments").
+		Rule(admissionregistrationv1.Rule{
+			APIGroups:   []string{""},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"pods"},
+		}).
+		Rule(admissionregistrationv1.Rule{
+			APIGroups:   []string{"apps"},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"deployments"},
+		}).
+		Build()
+
+	client, err := testutils.NewFakeClient(
+		namespace,
+		policyServer,
+		policyServerService,
+		policy,
+	)
+	require.NoError(t, err)
+
+	podsGVR := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+	deploymentsGVR := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	tests := []struct {
+		name         string
+		included     []schema.GroupKind
+		excluded     []schema.GroupKind
+		expectedGVRs []schema.GroupVersionResource
+	}{
+		{"no filter", nil, nil, []schema.GroupVersionResource{podsGVR, deploymentsGVR}},
+		{"included kind", []schema.GroupKind{{Kind: "Pod"}}, nil, []schema.GroupVersionResource{podsGVR}},
+		{"included kind of another group", []schema.GroupKind{{Group: "batch", Kind: "Deployment"}}, nil, []schema.GroupVersionResource{}},
+		{"excluded kind", nil, []schema.GroupKind{{Group: "apps", Kind: "Deployment"}}, []schema.GroupVersionResource{podsGVR}},
+		{"excluded kind wins", []schema.GroupKind{{Kind: "Pod"}, {Kind: "Deployment"}}, []schema.GroupKind{{Kind: "Pod"}}, []schema.GroupVersionResource{deploymentsGVR}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			policiesClient, err := NewClient(client, "kubewarden", "", WithKindFilter(test.included, test.excluded))
+			require.NoError(t, err)
+
+			policies, err := policiesClient.GetPoliciesByNamespace(context.Background(), namespace)
+			require.NoError(t, err)
+
+			gvrs := []schema.GroupVersionResource{}
+			for gvr := range policies.PoliciesByGVR {
+				gvrs = append(gvrs, gvr)
+			}
+			assert.ElementsMatch(t, test.expectedGVRs, gvrs)
+			// the policies are neither skipped nor errored, their resources aren't audited
+			assert.Zero(t, policies.SkippedNum)
+			assert.Zero(t, policies.ErroredNum)
+		})
+	}
+}
+
+func TestGetPoliciesWithExtraGVRs(t *testing.T) {
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test",
+		},
+	}
+
+	policyServer := &policiesv1.PolicyServer{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+	}
+
+	policyServerService := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				"app": "kubewarden-policy-server-default",
+			},
+			Name:      "policy-server-default",
+			Namespace: "kubewarden",
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{
+					Name: "http",
+					Port: 443,
+				},
+			},
+		},
+	}
+
+	// the wildcard rules can't be listed, the policy is skipped unless extra resources are given
+	wildcardPolicy := testutils.
+		NewClusterAdmissionPolicyFactory().
+		Name("all-core-resources").
+		Rule(admissionregistrationv1.Rule{
+			APIGroups:   []string{""},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"*"},
+		}).
+		Build()
+	deploymentsPolicy := testutils.
+		NewClusterAdmissionPolicyFactory().
+		Name("deployments").
+		Rule(admissionregistrationv1.Rule{
+			APIGroups:   []string{"apps"},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"deployments"},
+		}).
+		Build()
+
+	client, err := testutils.NewFakeClient(
+		namespace,
+		policyServer,
+		policyServerService,
+		wildcardPolicy,
+		deploymentsPolicy,
+	)
+	require.NoError(t, err)
+
+	podsGVR := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+	deploymentsGVR := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	tests := []struct {
+		name             string
+		extraGVRs        []schema.GroupVersionResource
+		expectedPolicies map[schema.GroupVersionResource][]string
+		expectedSkipped  int
+	}{
+		{
+			"no extra resources",
+			nil,
+			map[schema.GroupVersionResource][]string{deploymentsGVR: {"clusterwide-deployments"}},
+			1,
+		},
+		{
+			"extra resource matched by a wildcard rule",
+			[]schema.GroupVersionResource{podsGVR},
+			map[schema.GroupVersionResource][]string{podsGVR: {"clusterwide-all-core-resources"}, deploymentsGVR: {"clusterwide-deployments"}},
+			0,
+		},
+		{
+			"extra resource already targeted",
+			[]schema.GroupVersionResource{deploymentsGVR},
+			map[schema.GroupVersionResource][]string{deploymentsGVR: {"clusterwide-deployments"}},
+			1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			policiesClient, err := NewClient(client, "kubewarden", "", WithExtraGVRs(test.extraGVRs...))
+			require.NoError(t, err)
+
+			policies, err := policiesClient.GetPoliciesByNamespace(context.Background(), namespace)
+			require.NoError(t, err)
+
+			policyNames := map[schema.GroupVersionResource][]string{}
+			for gvr, gvrPolicies := range policies.PoliciesByGVR {
+				for _, policy := range gvrPolicies {
+					policyNames[gvr] = append(policyNames[gvr], policy.GetUniqueName())
+				}
+			}
+			assert.Equal(t, test.expectedPolicies, policyNames)
+			assert.Equal(t, test.expectedSkipped, policies.SkippedNum)
+			assert.Zero(t, policies.ErroredNum)
+		})
+	}
+}
@@ -6,8 +6,20 @@ const (
 	propertyPolicyUID             = "policy-uid"
 	propertyPolicyName            = "policy-name"
 	propertyPolicyNamespace       = "policy-namespace"
+	propertyEvaluationDuration    = "evaluation-duration-ms"
+	propertyWarnings              = "warnings"
+	// warningsSeparator separates the warnings joined in the warnings property
+	warningsSeparator = "\n"
 )
 
+// defaultRejectionMessage is the message of the results of policies rejecting
+// a resource without giving a reason.
+const defaultRejectionMessage = "the resource was rejected by the policy"
+
+// evaluationErrorMessage prefixes the message of the results of policies that
+// could not be evaluated.
+const evaluationErrorMessage = "the policy could not be evaluated"
+
 const (
 	// Status specifies state of a policy result.
 	statusPass  = "pass"
@@ -39,4 +51,11 @@ const (
 	labelApp                      = "kubewarden"
 	labelPolicyReportVersion      = "kubewarden.io/policyreport-version"
 	labelPolicyReportVersionValue = "v2"
+	// labelSampleSize is set on the reports written by a sampled scan, to the
+	// maximum number of resources of every type it audited
+	labelSampleSize = "kubewarden.io/policyreport-sample-size"
+	// labelMirroredReport is set on the PolicyReports holding a copy of the
+	// results of a ClusterPolicyReport, see NewMirroredPolicyReport
+	labelMirroredReport      = "kubewarden.io/policyreport-mirror"
+	labelMirroredReportValue = "true"
 )
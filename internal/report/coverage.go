@@ -0,0 +1,87 @@
+package report
+
+import (
+	"encoding/json"
+	"maps"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	wgpolicy "sigs.k8s.io/wg-policy-prototypes/policy-report/pkg/api/wgpolicyk8s.io/v1alpha2"
+)
+
+const (
+	// annotationScanFilters holds the filters restricting the scan that wrote
+	// the report, as a JSON object keyed by flag name. It's an empty object
+	// when the scan wasn't restricted.
+	annotationScanFilters = "kubewarden.io/scan-filters"
+	// annotationScanTimestamp is the time the report was written, in RFC 3339.
+	annotationScanTimestamp = "kubewarden.io/scan-timestamp"
+	// annotationScanResources is the number of resources audited by the scan,
+	// set on the summary report only, since the other reports are written
+	// before the scan completes.
+	annotationScanResources = "kubewarden.io/scan-resources"
+)
+
+// WithScanCoverage makes the store record on every report written to the
+// cluster the filters restricting the scan, e.g. the selectors and the
+// sampling, and the time the report was written. The summary report records
+// the number of resources audited too. This way the consumers of the reports
+// can tell the resources audited and found compliant from the ones the scan
+// didn't cover.
+func WithScanCoverage(filters map[string]string) StoreOption {
+	return func(s *PolicyReportStore) {
+		s.scanCoverage = true
+		s.scanFilters = maps.Clone(filters)
+	}
+}
+
+// storedAnnotations returns the annotations of a report written to the
+// cluster, built from the ones of the report currently stored.
+func (s *PolicyReportStore) storedAnnotations(current client.Object, labels map[string]string, summary wgpolicy.PolicyReportSummary) map[string]string {
+	annotations := current.GetAnnotations()
+	if s.historyDepth > 0 {
+		annotations = s.withHistory(current, labels, summary)
+	}
+	if s.scanCoverage {
+		annotations = s.withScanCoverage(annotations)
+	}
+
+	return annotations
+}
+
+// withScanCoverage returns the annotations with the coverage of the scan set.
+func (s *PolicyReportStore) withScanCoverage(annotations map[string]string) map[string]string {
+	filters := s.scanFilters
+	if filters == nil {
+		filters = map[string]string{}
+	}
+	value, err := json.Marshal(filters)
+	if err != nil {
+		log.Warn().Err(err).Msg("cannot serialize the scan filters")
+		return annotations
+	}
+
+	annotations = maps.Clone(annotations)
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[annotationScanFilters] = string(value)
+	annotations[annotationScanTimestamp] = time.Now().UTC().Format(time.RFC3339)
+
+	return annotations
+}
+
+// summaryAnnotations returns the annotations of the summary report, built
+// from the ones of the summary report currently stored.
+func (s *PolicyReportStore) summaryAnnotations(current client.Object, scanSummary ScanSummary) map[string]string {
+	if !s.scanCoverage {
+		return current.GetAnnotations()
+	}
+
+	annotations := s.withScanCoverage(current.GetAnnotations())
+	annotations[annotationScanResources] = strconv.Itoa(scanSummary.Resources)
+
+	return annotations
+}
@@ -0,0 +1,64 @@
+package report
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	testutils "github.com/kubewarden/audit-scanner/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	wgpolicy "sigs.k8s.io/wg-policy-prototypes/policy-report/pkg/api/wgpolicyk8s.io/v1alpha2"
+)
+
+func TestScanCoverage(t *testing.T) {
+	resource := unstructured.Unstructured{}
+	resource.SetUID("uid")
+	resource.SetName("test-pod")
+	resource.SetNamespace("namespace")
+	resource.SetAPIVersion("v1")
+	resource.SetKind("Pod")
+
+	tests := []struct {
+		name            string
+		filters         map[string]string
+		expectedFilters string
+	}{
+		{"complete scan", nil, `{}`},
+		{"filtered scan", map[string]string{"resource-selector": "app=frontend", "sample-size": "10"}, `{"resource-selector":"app=frontend","sample-size":"10"}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fakeClient, err := testutils.NewFakeClient()
+			require.NoError(t, err)
+			// the coverage is recorded together with the history
+			store := NewPolicyReportStore(fakeClient, WithScanCoverage(test.filters), WithReportHistory(2))
+
+			policyReport := NewPolicyReport("run-uid", resource)
+			store.RecordPolicyReport(policyReport)
+			require.NoError(t, store.CreateOrPatchPolicyReport(context.TODO(), policyReport))
+
+			storedPolicyReport := &wgpolicy.PolicyReport{}
+			err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: "uid", Namespace: "namespace"}, storedPolicyReport)
+			require.NoError(t, err)
+			annotations := storedPolicyReport.GetAnnotations()
+			assert.JSONEq(t, test.expectedFilters, annotations[annotationScanFilters])
+			timestamp, err := time.Parse(time.RFC3339, annotations[annotationScanTimestamp])
+			require.NoError(t, err)
+			assert.WithinDuration(t, time.Now(), timestamp, time.Minute)
+			assert.Len(t, ReportHistory(storedPolicyReport), 1)
+			// the number of resources is known only once the scan completes
+			assert.NotContains(t, annotations, annotationScanResources)
+
+			require.NoError(t, store.CreateOrPatchSummaryReport(context.TODO(), "run-uid"))
+			summaryReport := &wgpolicy.ClusterPolicyReport{}
+			err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: SummaryReportName}, summaryReport)
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedFilters, summaryReport.GetAnnotations()[annotationScanFilters])
+			assert.Equal(t, "1", summaryReport.GetAnnotations()[annotationScanResources])
+		})
+	}
+}
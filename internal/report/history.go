@@ -0,0 +1,109 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"time"
+
+	auditConstants "github.com/kubewarden/audit-scanner/internal/constants"
+	"github.com/rs/zerolog/log"
+	apimachineryerrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	wgpolicy "sigs.k8s.io/wg-policy-prototypes/policy-report/pkg/api/wgpolicyk8s.io/v1alpha2"
+)
+
+// annotationReportHistory holds the summaries of the last scans of the
+// resource, as a JSON array ordered from the oldest to the newest.
+const annotationReportHistory = "kubewarden.io/policyreport-history"
+
+// MaxReportHistory is the maximum number of scans kept in the history of a
+// report. Every entry takes about 150 bytes, the cap keeps the annotations far
+// below the size limit of the objects stored in etcd.
+const MaxReportHistory = 50
+
+// HistoryEntry is the summary of a scan of a resource, as kept in the history
+// of its report.
+type HistoryEntry struct {
+	RunUID    string    `json:"runUID"`
+	Timestamp time.Time `json:"timestamp"`
+	Pass      int       `json:"pass"`
+	Fail      int       `json:"fail"`
+	Warn      int       `json:"warn"`
+	Error     int       `json:"error"`
+	Skip      int       `json:"skip"`
+}
+
+// WithReportHistory makes the store keep the summaries of the last depth scans
+// in an annotation of every report, instead of overwriting the previous
+// results without a trace. The depth is capped at MaxReportHistory.
+func WithReportHistory(depth int) StoreOption {
+	return func(s *PolicyReportStore) {
+		s.historyDepth = min(depth, MaxReportHistory)
+	}
+}
+
+// ReportHistory returns the history of the report, parsed from its annotation.
+// A malformed history is ignored, as if the report had none.
+func ReportHistory(report client.Object) []HistoryEntry {
+	value, found := report.GetAnnotations()[annotationReportHistory]
+	if !found {
+		return nil
+	}
+	history := []HistoryEntry{}
+	if err := json.Unmarshal([]byte(value), &history); err != nil {
+		log.Debug().Err(err).Str("report-name", report.GetName()).Msg("ignoring malformed report history")
+		return nil
+	}
+
+	return history
+}
+
+// withHistory returns the annotations of the current report with the summary
+// of the new report appended to its history, trimmed to the history depth.
+// When the current report was written by the same scan, e.g. because the scan
+// was resumed, its entry is replaced instead.
+func (s *PolicyReportStore) withHistory(current client.Object, labels map[string]string, summary wgpolicy.PolicyReportSummary) map[string]string {
+	history := ReportHistory(current)
+	entry := HistoryEntry{
+		RunUID:    labels[auditConstants.AuditScannerRunUIDLabel],
+		Timestamp: time.Now().UTC().Truncate(time.Second),
+		Pass:      summary.Pass,
+		Fail:      summary.Fail,
+		Warn:      summary.Warn,
+		Error:     summary.Error,
+		Skip:      summary.Skip,
+	}
+	if len(history) > 0 && history[len(history)-1].RunUID == entry.RunUID {
+		history = history[:len(history)-1]
+	}
+	history = append(history, entry)
+	if len(history) > s.historyDepth {
+		history = history[len(history)-s.historyDepth:]
+	}
+
+	annotations := maps.Clone(current.GetAnnotations())
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	value, err := json.Marshal(history)
+	if err != nil {
+		log.Warn().Err(err).Str("report-name", current.GetName()).Msg("cannot serialize the report history")
+		return current.GetAnnotations()
+	}
+	annotations[annotationReportHistory] = string(value)
+
+	return annotations
+}
+
+// currentReport fetches the report stored in the cluster into report, so that
+// its history can be carried over by the server-side apply patches.
+// A missing report is left empty.
+func (s *PolicyReportStore) currentReport(ctx context.Context, report client.Object) error {
+	err := s.client.Get(ctx, client.ObjectKeyFromObject(report), report)
+	if apimachineryerrors.IsNotFound(err) {
+		return nil
+	}
+
+	return err
+}
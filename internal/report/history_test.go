@@ -0,0 +1,113 @@
+package report
+
+import (
+	"context"
+	"testing"
+
+	testutils "github.com/kubewarden/audit-scanner/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	wgpolicy "sigs.k8s.io/wg-policy-prototypes/policy-report/pkg/api/wgpolicyk8s.io/v1alpha2"
+)
+
+func TestReportHistory(t *testing.T) {
+	resource := unstructured.Unstructured{}
+	resource.SetUID("uid")
+	resource.SetName("test-pod")
+	resource.SetNamespace("namespace")
+	resource.SetAPIVersion("v1")
+	resource.SetKind("Pod")
+
+	fakeClient, err := testutils.NewFakeClient()
+	require.NoError(t, err)
+	store := NewPolicyReportStore(fakeClient, WithReportHistory(2))
+
+	for i, runUID := range []string{"run-1", "run-2", "run-3", "run-3"} {
+		policyReport := NewPolicyReport(runUID, resource)
+		policyReport.Summary.Fail = i
+		require.NoError(t, store.CreateOrPatchPolicyReport(context.TODO(), policyReport))
+	}
+
+	storedPolicyReport := &wgpolicy.PolicyReport{}
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: "uid", Namespace: "namespace"}, storedPolicyReport)
+	require.NoError(t, err)
+
+	// the oldest scan is dropped, the repeated write of the last scan replaces its entry
+	history := ReportHistory(storedPolicyReport)
+	require.Len(t, history, 2)
+	assert.Equal(t, "run-2", history[0].RunUID)
+	assert.Equal(t, 1, history[0].Fail)
+	assert.Equal(t, "run-3", history[1].RunUID)
+	assert.Equal(t, 3, history[1].Fail)
+	assert.False(t, history[1].Timestamp.IsZero())
+}
+
+func TestReportHistoryDisabled(t *testing.T) {
+	resource := unstructured.Unstructured{}
+	resource.SetUID("uid")
+	resource.SetAPIVersion("v1")
+	resource.SetKind("Namespace")
+	resource.SetName("test-namespace")
+
+	fakeClient, err := testutils.NewFakeClient()
+	require.NoError(t, err)
+	store := NewPolicyReportStore(fakeClient)
+
+	require.NoError(t, store.CreateOrPatchClusterPolicyReport(context.TODO(), NewClusterPolicyReport("run-1", resource)))
+
+	storedClusterPolicyReport := &wgpolicy.ClusterPolicyReport{}
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: "uid"}, storedClusterPolicyReport)
+	require.NoError(t, err)
+	assert.NotContains(t, storedClusterPolicyReport.GetAnnotations(), annotationReportHistory)
+}
+
+func TestReportHistoryServerSideApply(t *testing.T) {
+	resource := unstructured.Unstructured{}
+	resource.SetUID("uid")
+	resource.SetAPIVersion("v1")
+	resource.SetKind("Namespace")
+	resource.SetName("test-namespace")
+
+	previousClusterPolicyReport := NewClusterPolicyReport("run-1", resource)
+	previousClusterPolicyReport.SetAnnotations(map[string]string{
+		annotationReportHistory: `[{"runUID":"run-1","timestamp":"2024-01-01T00:00:00Z","pass":1,"fail":0,"warn":0,"error":0,"skip":0}]`,
+	})
+	fakeClient, err := testutils.NewFakeClient(previousClusterPolicyReport)
+	require.NoError(t, err)
+
+	// the fake client doesn't support server-side apply, the patches are inspected instead
+	var appliedObject client.Object
+	applyingClient := interceptor.NewClient(fakeClient.(client.WithWatch), interceptor.Funcs{
+		Patch: func(_ context.Context, _ client.WithWatch, obj client.Object, _ client.Patch, _ ...client.PatchOption) error {
+			appliedObject = obj
+			return nil
+		},
+	})
+	store := NewPolicyReportStore(applyingClient, WithApplyMode(ApplyModeServerSide), WithReportHistory(5))
+
+	clusterPolicyReport := NewClusterPolicyReport("run-2", resource)
+	clusterPolicyReport.Summary.Fail = 1
+	require.NoError(t, store.CreateOrPatchClusterPolicyReport(context.TODO(), clusterPolicyReport))
+
+	require.NotNil(t, appliedObject)
+	history := ReportHistory(appliedObject)
+	require.Len(t, history, 2)
+	assert.Equal(t, "run-1", history[0].RunUID)
+	assert.Equal(t, 1, history[0].Pass)
+	assert.Equal(t, "run-2", history[1].RunUID)
+	assert.Equal(t, 1, history[1].Fail)
+}
+
+func TestReportHistoryMalformed(t *testing.T) {
+	policyReport := &wgpolicy.PolicyReport{ObjectMeta: metav1.ObjectMeta{
+		Name:        "uid",
+		Annotations: map[string]string{annotationReportHistory: "{"},
+	}}
+
+	assert.Empty(t, ReportHistory(policyReport))
+}
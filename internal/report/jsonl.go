@@ -0,0 +1,87 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	wgpolicy "sigs.k8s.io/wg-policy-prototypes/policy-report/pkg/api/wgpolicyk8s.io/v1alpha2"
+)
+
+// WriteJSONLines writes the retained ClusterPolicyReports and PolicyReports to
+// w, one JSON object per line, in the order returned by Reports.
+// Every object carries its apiVersion and kind, so that the ClusterPolicyReports
+// can be told apart from the PolicyReports.
+func (s *PolicyReportStore) WriteJSONLines(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	scanResult := s.Reports()
+
+	for i := range scanResult.ClusterPolicyReports {
+		if err := encodeClusterPolicyReportLine(encoder, &scanResult.ClusterPolicyReports[i]); err != nil {
+			return err
+		}
+	}
+	for i := range scanResult.PolicyReports {
+		if err := encodePolicyReportLine(encoder, &scanResult.PolicyReports[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// StreamErr returns the first error that occurred writing the reports to the
+// writer given with WithReportStream, if any. The reports recorded after the
+// error are not written.
+func (s *PolicyReportStore) StreamErr() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.streamErr
+}
+
+// streamPolicyReport writes the PolicyReport to the report stream, if any.
+// It must be called with the mutex held, so that the lines written by
+// concurrent scan workers are not interleaved.
+func (s *PolicyReportStore) streamPolicyReport(policyReport *wgpolicy.PolicyReport) {
+	if s.stream == nil || s.streamErr != nil {
+		return
+	}
+	s.streamErr = encodePolicyReportLine(s.stream, policyReport)
+}
+
+// streamClusterPolicyReport writes the ClusterPolicyReport to the report
+// stream, if any. It must be called with the mutex held.
+func (s *PolicyReportStore) streamClusterPolicyReport(clusterPolicyReport *wgpolicy.ClusterPolicyReport) {
+	if s.stream == nil || s.streamErr != nil {
+		return
+	}
+	s.streamErr = encodeClusterPolicyReportLine(s.stream, clusterPolicyReport)
+}
+
+func encodePolicyReportLine(encoder *json.Encoder, policyReport *wgpolicy.PolicyReport) error {
+	line := policyReport.DeepCopy()
+	line.TypeMeta = metav1.TypeMeta{
+		APIVersion: wgpolicy.SchemeGroupVersion.String(),
+		Kind:       "PolicyReport",
+	}
+	if err := encoder.Encode(line); err != nil {
+		return fmt.Errorf("cannot encode PolicyReport %s/%s to JSON: %w", policyReport.GetNamespace(), policyReport.GetName(), err)
+	}
+
+	return nil
+}
+
+func encodeClusterPolicyReportLine(encoder *json.Encoder, clusterPolicyReport *wgpolicy.ClusterPolicyReport) error {
+	line := clusterPolicyReport.DeepCopy()
+	line.TypeMeta = metav1.TypeMeta{
+		APIVersion: wgpolicy.SchemeGroupVersion.String(),
+		Kind:       "ClusterPolicyReport",
+	}
+	if err := encoder.Encode(line); err != nil {
+		return fmt.Errorf("cannot encode ClusterPolicyReport %s to JSON: %w", clusterPolicyReport.GetName(), err)
+	}
+
+	return nil
+}
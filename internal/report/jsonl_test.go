@@ -0,0 +1,76 @@
+package report
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	wgpolicy "sigs.k8s.io/wg-policy-prototypes/policy-report/pkg/api/wgpolicyk8s.io/v1alpha2"
+)
+
+func TestReportStream(t *testing.T) {
+	var buf bytes.Buffer
+	store := NewPolicyReportStore(nil, WithReportStream(&buf))
+
+	resource := unstructured.Unstructured{}
+	resource.SetUID("pod-uid")
+	resource.SetNamespace("namespace")
+	store.RecordPolicyReport(NewPolicyReport("runUID", resource))
+	// every report is written as soon as it is recorded
+	assert.Equal(t, 1, bytes.Count(buf.Bytes(), []byte("\n")))
+
+	clusterResource := unstructured.Unstructured{}
+	clusterResource.SetUID("namespace-uid")
+	store.RecordClusterPolicyReport(NewClusterPolicyReport("runUID", clusterResource))
+
+	require.NoError(t, store.StreamErr())
+	assert.Empty(t, store.Reports().PolicyReports, "streamed reports must not be retained")
+
+	scanner := bufio.NewScanner(&buf)
+	require.True(t, scanner.Scan())
+	var policyReport wgpolicy.PolicyReport
+	require.NoError(t, json.Unmarshal(scanner.Bytes(), &policyReport))
+	assert.Equal(t, "PolicyReport", policyReport.Kind)
+	assert.Equal(t, wgpolicy.SchemeGroupVersion.String(), policyReport.APIVersion)
+	assert.Equal(t, "pod-uid", policyReport.GetName())
+
+	require.True(t, scanner.Scan())
+	var clusterPolicyReport wgpolicy.ClusterPolicyReport
+	require.NoError(t, json.Unmarshal(scanner.Bytes(), &clusterPolicyReport))
+	assert.Equal(t, "ClusterPolicyReport", clusterPolicyReport.Kind)
+	assert.Equal(t, "namespace-uid", clusterPolicyReport.GetName())
+
+	assert.False(t, scanner.Scan())
+}
+
+type failingWriter struct {
+	writes int
+}
+
+func (w *failingWriter) Write([]byte) (int, error) {
+	w.writes++
+	return 0, errors.New("disk full")
+}
+
+func TestReportStreamError(t *testing.T) {
+	writer := &failingWriter{}
+	store := NewPolicyReportStore(nil, WithReportStream(writer))
+
+	resource := unstructured.Unstructured{}
+	resource.SetUID("uid")
+	store.RecordPolicyReport(NewPolicyReport("runUID", resource))
+	store.RecordClusterPolicyReport(NewClusterPolicyReport("runUID", resource))
+
+	require.ErrorContains(t, store.StreamErr(), "disk full")
+	assert.Equal(t, 1, writer.writes, "the reports following an error must not be written")
+	// the summary of the scan is still computed
+	assert.Equal(t, 2, store.ScanSummary().Resources)
+
+	store.Reset()
+	assert.NoError(t, store.StreamErr())
+}
@@ -0,0 +1,144 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	corev1 "k8s.io/api/core/v1"
+	wgpolicy "sigs.k8s.io/wg-policy-prototypes/policy-report/pkg/api/wgpolicyk8s.io/v1alpha2"
+)
+
+const (
+	junitSuitesName = "kubewarden-audit-scanner"
+	// junitClusterWideSuite is the name of the test suite of the cluster wide
+	// resources, it cannot clash with a namespace name since it has a space
+	junitClusterWideSuite = "cluster wide resources"
+)
+
+// The following types model the JUnit XML format as understood by the most
+// common CI systems.
+// https://github.com/testmoapp/junitxml
+
+type junitTestSuites struct {
+	XMLName    xml.Name         `xml:"testsuites"`
+	Name       string           `xml:"name,attr"`
+	Tests      int              `xml:"tests,attr"`
+	Failures   int              `xml:"failures,attr"`
+	Errors     int              `xml:"errors,attr"`
+	Skipped    int              `xml:"skipped,attr"`
+	TestSuites []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Error     *junitFailure `xml:"error,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+// WriteJUnit writes the results of the retained reports to w as a JUnit XML
+// document. Every policy evaluation is a test case, named after the policy
+// and with the evaluated resource as class name, grouped in a test suite per
+// namespace. The cluster wide resources have their own test suite.
+// Failing results are failures, errored results are errors and skipped results
+// are skipped test cases.
+func (s *PolicyReportStore) WriteJUnit(w io.Writer) error {
+	scanResult := s.Reports()
+
+	testSuites := junitTestSuites{Name: junitSuitesName, TestSuites: []junitTestSuite{}}
+	if len(scanResult.ClusterPolicyReports) > 0 {
+		testSuite := junitTestSuite{Name: junitClusterWideSuite}
+		for _, clusterPolicyReport := range scanResult.ClusterPolicyReports {
+			testSuite.addTestCases(clusterPolicyReport.Scope, clusterPolicyReport.Results)
+		}
+		testSuites.addTestSuite(testSuite)
+	}
+	// the PolicyReports are sorted by namespace, hence every namespace is
+	// a contiguous run of reports
+	for i := 0; i < len(scanResult.PolicyReports); {
+		namespace := scanResult.PolicyReports[i].GetNamespace()
+		testSuite := junitTestSuite{Name: namespace}
+		for ; i < len(scanResult.PolicyReports) && scanResult.PolicyReports[i].GetNamespace() == namespace; i++ {
+			testSuite.addTestCases(scanResult.PolicyReports[i].Scope, scanResult.PolicyReports[i].Results)
+		}
+		testSuites.addTestSuite(testSuite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("cannot encode reports to JUnit: %w", err)
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(testSuites); err != nil {
+		return fmt.Errorf("cannot encode reports to JUnit: %w", err)
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return fmt.Errorf("cannot encode reports to JUnit: %w", err)
+	}
+
+	return nil
+}
+
+func (t *junitTestSuites) addTestSuite(testSuite junitTestSuite) {
+	t.Tests += testSuite.Tests
+	t.Failures += testSuite.Failures
+	t.Errors += testSuite.Errors
+	t.Skipped += testSuite.Skipped
+	t.TestSuites = append(t.TestSuites, testSuite)
+}
+
+func (t *junitTestSuite) addTestCases(scope *corev1.ObjectReference, results []*wgpolicy.PolicyReportResult) {
+	resourceName := qualifiedResourceName(scope)
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		testCase := junitTestCase{Name: result.Policy, ClassName: resourceName}
+		switch result.Result {
+		case statusFail:
+			message := result.Description
+			if message == "" {
+				message = fmt.Sprintf("%s violates policy %s", resourceName, result.Policy)
+			}
+			testCase.Failure = &junitFailure{Message: message, Type: string(statusFail), Text: message}
+			t.Failures++
+		case statusError:
+			message := result.Description
+			if message == "" {
+				message = fmt.Sprintf("policy %s could not be evaluated against %s", result.Policy, resourceName)
+			}
+			testCase.Error = &junitFailure{Message: message, Type: string(statusError), Text: message}
+			t.Errors++
+		case statusSkip:
+			testCase.Skipped = &junitSkipped{Message: result.Description}
+			t.Skipped++
+		case statusWarn:
+			// warnings don't fail the test case, their message is kept as output
+			testCase.SystemOut = result.Description
+		}
+		t.Tests++
+		t.TestCases = append(t.TestCases, testCase)
+	}
+}
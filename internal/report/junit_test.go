@@ -0,0 +1,115 @@
+package report
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	wgpolicy "sigs.k8s.io/wg-policy-prototypes/policy-report/pkg/api/wgpolicyk8s.io/v1alpha2"
+)
+
+func TestWriteJUnit(t *testing.T) {
+	store := NewPolicyReportStore(nil, WithInMemoryReports())
+
+	store.RecordPolicyReport(&wgpolicy.PolicyReport{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-uid", Namespace: "default"},
+		Scope: &corev1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       "Pod",
+			Namespace:  "default",
+			Name:       "nginx",
+			UID:        "pod-uid",
+		},
+		Results: []*wgpolicy.PolicyReportResult{
+			{Policy: "namespaced-default-privileged", Result: statusFail, Description: "privileged container"},
+			{Policy: "namespaced-default-capabilities", Result: statusPass},
+			{Policy: "namespaced-default-unreachable", Result: statusError},
+		},
+	})
+	store.RecordPolicyReport(&wgpolicy.PolicyReport{
+		ObjectMeta: metav1.ObjectMeta{Name: "deployment-uid", Namespace: "apps"},
+		Scope: &corev1.ObjectReference{
+			APIVersion: "apps/v1",
+			Kind:       "Deployment",
+			Namespace:  "apps",
+			Name:       "frontend",
+			UID:        "deployment-uid",
+		},
+		Results: []*wgpolicy.PolicyReportResult{
+			{Policy: "namespaced-apps-replicas", Result: statusWarn, Description: "single replica"},
+			{Policy: "namespaced-apps-skipped", Result: statusSkip},
+		},
+	})
+	store.RecordClusterPolicyReport(&wgpolicy.ClusterPolicyReport{
+		ObjectMeta: metav1.ObjectMeta{Name: "ns-uid"},
+		Scope: &corev1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       "Namespace",
+			Name:       "default",
+			UID:        "ns-uid",
+		},
+		Results: []*wgpolicy.PolicyReportResult{
+			{Policy: "clusterwide-labels", Result: statusFail},
+		},
+	})
+
+	var buf bytes.Buffer
+	require.NoError(t, store.Write(&buf, OutputFormatJUnit))
+	assert.Contains(t, buf.String(), xml.Header)
+
+	var testSuites junitTestSuites
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &testSuites))
+
+	assert.Equal(t, 6, testSuites.Tests)
+	assert.Equal(t, 2, testSuites.Failures)
+	assert.Equal(t, 1, testSuites.Errors)
+	assert.Equal(t, 1, testSuites.Skipped)
+
+	suiteNames := []string{}
+	for _, testSuite := range testSuites.TestSuites {
+		suiteNames = append(suiteNames, testSuite.Name)
+	}
+	assert.Equal(t, []string{"cluster wide resources", "apps", "default"}, suiteNames)
+
+	clusterWide := testSuites.TestSuites[0]
+	require.Len(t, clusterWide.TestCases, 1)
+	assert.Equal(t, "clusterwide-labels", clusterWide.TestCases[0].Name)
+	assert.Equal(t, "v1/Namespace/default", clusterWide.TestCases[0].ClassName)
+	require.NotNil(t, clusterWide.TestCases[0].Failure)
+	assert.NotEmpty(t, clusterWide.TestCases[0].Failure.Message)
+
+	apps := testSuites.TestSuites[1]
+	assert.Equal(t, 2, apps.Tests)
+	require.Len(t, apps.TestCases, 2)
+	assert.Nil(t, apps.TestCases[0].Failure)
+	assert.Equal(t, "single replica", apps.TestCases[0].SystemOut)
+	assert.NotNil(t, apps.TestCases[1].Skipped)
+
+	defaultSuite := testSuites.TestSuites[2]
+	assert.Equal(t, 3, defaultSuite.Tests)
+	assert.Equal(t, 1, defaultSuite.Failures)
+	assert.Equal(t, 1, defaultSuite.Errors)
+	require.Len(t, defaultSuite.TestCases, 3)
+	assert.Equal(t, "v1/Pod/default/nginx", defaultSuite.TestCases[0].ClassName)
+	assert.Equal(t, "privileged container", defaultSuite.TestCases[0].Failure.Message)
+	assert.Nil(t, defaultSuite.TestCases[1].Failure)
+	assert.Nil(t, defaultSuite.TestCases[1].Error)
+	require.NotNil(t, defaultSuite.TestCases[2].Error)
+	assert.NotEmpty(t, defaultSuite.TestCases[2].Error.Message)
+}
+
+func TestWriteJUnitEmptyScan(t *testing.T) {
+	store := NewPolicyReportStore(nil, WithInMemoryReports())
+
+	var buf bytes.Buffer
+	require.NoError(t, store.WriteJUnit(&buf))
+
+	var testSuites junitTestSuites
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &testSuites))
+	assert.Equal(t, 0, testSuites.Tests)
+	assert.Empty(t, testSuites.TestSuites)
+}
@@ -0,0 +1,200 @@
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	wgpolicy "sigs.k8s.io/wg-policy-prototypes/policy-report/pkg/api/wgpolicyk8s.io/v1alpha2"
+)
+
+const outputDirPermissions = 0o755
+
+// OutputFormat is the format used to write the results of a scan.
+type OutputFormat string
+
+const (
+	// OutputFormatJSON writes the PolicyReports and ClusterPolicyReports as JSON.
+	OutputFormatJSON OutputFormat = "json"
+	// OutputFormatSARIF writes the failing and errored results as a SARIF 2.1.0 log.
+	OutputFormatSARIF OutputFormat = "sarif"
+	// OutputFormatTable writes the failing and errored results and the summary
+	// of the scan as human-readable tables.
+	OutputFormatTable OutputFormat = "table"
+	// OutputFormatJSONLines writes every PolicyReport and ClusterPolicyReport
+	// as a JSON object on its own line.
+	OutputFormatJSONLines OutputFormat = "jsonl"
+	// OutputFormatJUnit writes every policy evaluation as a JUnit XML test case.
+	OutputFormatJUnit OutputFormat = "junit"
+)
+
+// SupportedOutputFormats returns the output formats accepted by Write.
+func SupportedOutputFormats() []OutputFormat {
+	return []OutputFormat{OutputFormatJSON, OutputFormatSARIF, OutputFormatTable, OutputFormatJSONLines, OutputFormatJUnit}
+}
+
+// ScanResult is the combined result of a scan, as written by WriteJSON.
+type ScanResult struct {
+	ClusterPolicyReports []wgpolicy.ClusterPolicyReport `json:"clusterPolicyReports"`
+	PolicyReports        []wgpolicy.PolicyReport        `json:"policyReports"`
+}
+
+// Reports returns a snapshot of the reports retained so far, sorted by
+// namespace and name so that the output doesn't depend on the scan order.
+// The slices are never nil, so an empty scan is serialized as empty arrays.
+func (s *PolicyReportStore) Reports() ScanResult {
+	s.mutex.Lock()
+	result := ScanResult{
+		ClusterPolicyReports: slices.Clone(s.clusterPolicyReports),
+		PolicyReports:        slices.Clone(s.policyReports),
+	}
+	s.mutex.Unlock()
+
+	if result.ClusterPolicyReports == nil {
+		result.ClusterPolicyReports = []wgpolicy.ClusterPolicyReport{}
+	}
+	if result.PolicyReports == nil {
+		result.PolicyReports = []wgpolicy.PolicyReport{}
+	}
+	slices.SortFunc(result.ClusterPolicyReports, func(a, b wgpolicy.ClusterPolicyReport) int {
+		return strings.Compare(a.GetName(), b.GetName())
+	})
+	slices.SortFunc(result.PolicyReports, func(a, b wgpolicy.PolicyReport) int {
+		if c := strings.Compare(a.GetNamespace(), b.GetNamespace()); c != 0 {
+			return c
+		}
+		return strings.Compare(a.GetName(), b.GetName())
+	})
+
+	return result
+}
+
+// FailedResult is a result with status fail or error, together with the
+// resource it refers to.
+type FailedResult struct {
+	// Resource is the scope of the report the result belongs to
+	Resource *corev1.ObjectReference
+	Result   wgpolicy.PolicyReportResult
+}
+
+// GetFailedResults returns the results with status fail or error of all the
+// retained ClusterPolicyReports and PolicyReports. The results of the
+// ClusterPolicyReports come first, then the ones of the PolicyReports, both in
+// the order returned by Reports.
+func (s *PolicyReportStore) GetFailedResults() []FailedResult {
+	scanResult := s.Reports()
+	failedResults := []FailedResult{}
+
+	addFailedResults := func(scope *corev1.ObjectReference, results []*wgpolicy.PolicyReportResult) {
+		for _, result := range results {
+			if result != nil && (result.Result == statusFail || result.Result == statusError) {
+				failedResults = append(failedResults, FailedResult{Resource: scope, Result: *result})
+			}
+		}
+	}
+	for _, clusterPolicyReport := range scanResult.ClusterPolicyReports {
+		addFailedResults(clusterPolicyReport.Scope, clusterPolicyReport.Results)
+	}
+	for _, policyReport := range scanResult.PolicyReports {
+		addFailedResults(policyReport.Scope, policyReport.Results)
+	}
+
+	return failedResults
+}
+
+// qualifiedResourceName returns the identity of the resource in the form
+// apiVersion/kind/namespace/name, omitting the namespace for cluster-wide
+// resources.
+func qualifiedResourceName(scope *corev1.ObjectReference) string {
+	if scope == nil {
+		return ""
+	}
+
+	parts := []string{scope.APIVersion, scope.Kind}
+	if scope.Namespace != "" {
+		parts = append(parts, scope.Namespace)
+	}
+	parts = append(parts, scope.Name)
+
+	return strings.Join(parts, "/")
+}
+
+// WriteJSON writes the retained ClusterPolicyReports and PolicyReports as a
+// single JSON document to w.
+func (s *PolicyReportStore) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(s.Reports()); err != nil {
+		return fmt.Errorf("cannot encode reports to JSON: %w", err)
+	}
+
+	return nil
+}
+
+// Write writes the retained reports to w using the given format.
+func (s *PolicyReportStore) Write(w io.Writer, format OutputFormat) error {
+	switch format {
+	case OutputFormatJSON:
+		return s.WriteJSON(w)
+	case OutputFormatSARIF:
+		return s.WriteSARIF(w)
+	case OutputFormatTable:
+		return s.WriteTable(w)
+	case OutputFormatJSONLines:
+		return s.WriteJSONLines(w)
+	case OutputFormatJUnit:
+		return s.WriteJUnit(w)
+	default:
+		return fmt.Errorf("unsupported output format %q", format)
+	}
+}
+
+// WriteFile writes the retained reports to the file at path using the given
+// format, creating the parent directories when needed. The file is written to
+// a temporary file first and then renamed, so readers never observe a
+// partially written file and an existing file is replaced as a whole.
+func (s *PolicyReportStore) WriteFile(path string, format OutputFormat) error {
+	return WriteFileAtomically(path, func(w io.Writer) error {
+		return s.Write(w, format)
+	})
+}
+
+// WriteFileAtomically writes the file at path with write, creating the parent
+// directories when needed. The content is written to a temporary file first
+// and then renamed, so that readers, and the scans killed while writing, never
+// leave a partially written file.
+func WriteFileAtomically(path string, write func(io.Writer) error) (err error) {
+	dir := filepath.Dir(path)
+	if err = os.MkdirAll(dir, outputDirPermissions); err != nil {
+		return fmt.Errorf("cannot create directory %s: %w", dir, err)
+	}
+
+	tmpFile, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("cannot create temporary file in %s: %w", dir, err)
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, os.Remove(tmpFile.Name()))
+		}
+	}()
+
+	if err = write(tmpFile); err != nil {
+		return errors.Join(err, tmpFile.Close())
+	}
+	if err = tmpFile.Close(); err != nil {
+		return fmt.Errorf("cannot close temporary file %s: %w", tmpFile.Name(), err)
+	}
+	if err = os.Rename(tmpFile.Name(), path); err != nil {
+		return fmt.Errorf("cannot rename %s to %s: %w", tmpFile.Name(), path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	testutils "github.com/kubewarden/audit-scanner/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	wgpolicy "sigs.k8s.io/wg-policy-prototypes/policy-report/pkg/api/wgpolicyk8s.io/v1alpha2"
+)
+
+func TestWriteJSONEmptyScan(t *testing.T) {
+	store := NewPolicyReportStore(nil, WithInMemoryReports())
+
+	var buf bytes.Buffer
+	require.NoError(t, store.WriteJSON(&buf))
+
+	assert.JSONEq(t, `{"clusterPolicyReports": [], "policyReports": []}`, buf.String())
+}
+
+func TestRecordReportsRetainsOnlyWithOption(t *testing.T) {
+	store := NewPolicyReportStore(nil)
+
+	resource := unstructured.Unstructured{}
+	resource.SetUID("uid")
+	store.RecordPolicyReport(NewPolicyReport("runUID", resource))
+	store.RecordClusterPolicyReport(NewClusterPolicyReport("runUID", resource))
+
+	assert.Empty(t, store.Reports().PolicyReports)
+	assert.Empty(t, store.Reports().ClusterPolicyReports)
+}
+
+func TestWriteJSONFile(t *testing.T) {
+	fakeClient, err := testutils.NewFakeClient()
+	require.NoError(t, err)
+	store := NewPolicyReportStore(fakeClient, WithInMemoryReports())
+
+	for _, uid := range []string{"uid-b", "uid-a"} {
+		resource := unstructured.Unstructured{}
+		resource.SetUID(types.UID(uid))
+		resource.SetNamespace("namespace")
+		store.RecordPolicyReport(NewPolicyReport("runUID", resource))
+	}
+	clusterResource := unstructured.Unstructured{}
+	clusterResource.SetUID("cluster-uid")
+	store.RecordClusterPolicyReport(NewClusterPolicyReport("runUID", clusterResource))
+
+	path := filepath.Join(t.TempDir(), "nested", "dir", "report.json")
+	// an existing, longer file must be replaced entirely
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, bytes.Repeat([]byte("x"), 1<<16), 0o600))
+
+	require.NoError(t, store.WriteFile(path, OutputFormatJSON))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var result ScanResult
+	require.NoError(t, json.Unmarshal(content, &result))
+
+	require.Len(t, result.PolicyReports, 2)
+	assert.Equal(t, "uid-a", result.PolicyReports[0].GetName())
+	assert.Equal(t, "uid-b", result.PolicyReports[1].GetName())
+	require.Len(t, result.ClusterPolicyReports, 1)
+	assert.Equal(t, "cluster-uid", result.ClusterPolicyReports[0].GetName())
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "temporary files must not be left behind")
+}
+
+func TestGetFailedResults(t *testing.T) {
+	store := NewPolicyReportStore(nil, WithInMemoryReports())
+	assert.Empty(t, store.GetFailedResults())
+
+	store.RecordPolicyReport(&wgpolicy.PolicyReport{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-uid", Namespace: "default"},
+		Scope:      &corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "pod"},
+		Results: []*wgpolicy.PolicyReportResult{
+			{Policy: "pass-policy", Result: statusPass},
+			{Policy: "fail-policy", Result: statusFail},
+			nil,
+		},
+	})
+	store.RecordClusterPolicyReport(&wgpolicy.ClusterPolicyReport{
+		ObjectMeta: metav1.ObjectMeta{Name: "namespace-uid"},
+		Scope:      &corev1.ObjectReference{Kind: "Namespace", Name: "default"},
+		Results: []*wgpolicy.PolicyReportResult{
+			{Policy: "error-policy", Result: statusError},
+			{Policy: "skip-policy", Result: statusSkip},
+		},
+	})
+
+	failedResults := store.GetFailedResults()
+	require.Len(t, failedResults, 2)
+	assert.Equal(t, "error-policy", failedResults[0].Result.Policy)
+	assert.Equal(t, "Namespace", failedResults[0].Resource.Kind)
+	assert.Equal(t, "fail-policy", failedResults[1].Result.Policy)
+	assert.Equal(t, "pod", failedResults[1].Resource.Name)
+}
@@ -1,6 +1,10 @@
 package report
 
 import (
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/kubewarden/audit-scanner/internal/constants"
@@ -51,6 +55,7 @@ func NewPolicyReport(runUID string, resource unstructured.Unstructured) *wgpolic
 }
 
 // AddResultToPolicyReport adds a result to a PolicyReport and updates the summary.
+// The result replaces the one of the same policy, if any.
 func AddResultToPolicyReport(
 	policyReport *wgpolicy.PolicyReport,
 	policy policiesv1.Policy,
@@ -59,15 +64,7 @@ func AddResultToPolicyReport(
 ) *wgpolicy.PolicyReportResult {
 	now := metav1.Timestamp{Seconds: time.Now().Unix()}
 	result := newPolicyReportResult(policy, admissionReview, errored, now)
-	switch result.Result {
-	case statusFail:
-		policyReport.Summary.Fail++
-	case statusError:
-		policyReport.Summary.Error++
-	case statusPass:
-		policyReport.Summary.Pass++
-	}
-	policyReport.Results = append(policyReport.Results, result)
+	policyReport.Results = addResult(policyReport.Results, &policyReport.Summary, result)
 
 	return result
 }
@@ -109,6 +106,7 @@ func NewClusterPolicyReport(runUID string, resource unstructured.Unstructured) *
 }
 
 // AddResultToClusterPolicyReport adds a result to a ClusterPolicyReport and updates the summary.
+// The result replaces the one of the same policy, if any.
 func AddResultToClusterPolicyReport(
 	policyReport *wgpolicy.ClusterPolicyReport,
 	policy policiesv1.Policy,
@@ -117,17 +115,86 @@ func AddResultToClusterPolicyReport(
 ) *wgpolicy.PolicyReportResult {
 	now := metav1.Timestamp{Seconds: time.Now().Unix()}
 	result := newPolicyReportResult(policy, admissionReview, errored, now)
-	switch result.Result {
+	policyReport.Results = addResult(policyReport.Results, &policyReport.Summary, result)
+
+	return result
+}
+
+// NewMirroredPolicyReport creates a PolicyReport in namespace holding a copy of
+// the results of a ClusterPolicyReport, for the users allowed to read only the
+// namespaced reports. It's labeled as a mirror, so that its results aren't
+// mistaken for the ones of a namespaced resource.
+func NewMirroredPolicyReport(clusterPolicyReport *wgpolicy.ClusterPolicyReport, namespace string) *wgpolicy.PolicyReport {
+	labels := maps.Clone(clusterPolicyReport.GetLabels())
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[labelMirroredReport] = labelMirroredReportValue
+
+	results := make([]*wgpolicy.PolicyReportResult, 0, len(clusterPolicyReport.Results))
+	for _, result := range clusterPolicyReport.Results {
+		results = append(results, result.DeepCopy())
+	}
+
+	return &wgpolicy.PolicyReport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      clusterPolicyReport.GetName(),
+			Namespace: namespace,
+			Labels:    labels,
+			// the mirror is garbage collected together with the cluster-wide resource
+			OwnerReferences: clusterPolicyReport.GetOwnerReferences(),
+		},
+		Scope:   clusterPolicyReport.Scope.DeepCopy(),
+		Summary: clusterPolicyReport.Summary,
+		Results: results,
+	}
+}
+
+// addResult adds a result to the results of a report and updates its summary.
+// A report holds the results of a single resource, hence a policy matching the
+// resource through more than one rule, e.g. overlapping GroupVersionResources,
+// has its previous result replaced, so that the last one wins and it's counted
+// once in the summary.
+func addResult(results []*wgpolicy.PolicyReportResult, summary *wgpolicy.PolicyReportSummary, result *wgpolicy.PolicyReportResult) []*wgpolicy.PolicyReportResult {
+	updateSummary(summary, result.Result, 1)
+	for i, r := range results {
+		if r.Policy == result.Policy {
+			updateSummary(summary, r.Result, -1)
+			results[i] = result
+			return results
+		}
+	}
+
+	return append(results, result)
+}
+
+// updateSummary adds delta to the count of the summary matching status.
+func updateSummary(summary *wgpolicy.PolicyReportSummary, status wgpolicy.PolicyResult, delta int) {
+	switch status {
 	case statusFail:
-		policyReport.Summary.Fail++
+		summary.Fail += delta
 	case statusError:
-		policyReport.Summary.Error++
+		summary.Error += delta
 	case statusPass:
-		policyReport.Summary.Pass++
+		summary.Pass += delta
 	}
-	policyReport.Results = append(policyReport.Results, result)
+}
 
-	return result
+// SetEvaluationDuration records in the result how long the Policy Server took
+// to evaluate the policy, in milliseconds.
+func SetEvaluationDuration(result *wgpolicy.PolicyReportResult, duration time.Duration) {
+	if result.Properties == nil {
+		result.Properties = map[string]string{}
+	}
+	result.Properties[propertyEvaluationDuration] = strconv.FormatInt(duration.Milliseconds(), 10)
+}
+
+// SetEvaluationError sets the message of an errored result to the error that
+// prevented the evaluation of the policy, e.g. the Policy Server being
+// unreachable, so that it can be told apart from the policy rejecting the
+// resource.
+func SetEvaluationError(result *wgpolicy.PolicyReportResult, err error) {
+	result.Description = fmt.Sprintf("%s: %s", evaluationErrorMessage, err)
 }
 
 func newPolicyReportResult(policy policiesv1.Policy, admissionReview *admissionv1.AdmissionReview, errored bool, timestamp metav1.Timestamp) *wgpolicy.PolicyReportResult {
@@ -149,18 +216,31 @@ func newPolicyReportResult(policy policiesv1.Policy, admissionReview *admissionv
 		message = admissionReview.Response.Result.Message
 	}
 
+	result := computePolicyResult(errored, admissionReview)
+	if result == statusFail && message == "" {
+		message = defaultRejectionMessage
+	}
+
+	properties := computeProperties(policy)
+	// policies can return warnings, even when they accept the resource
+	if admissionReview != nil &&
+		admissionReview.Response != nil &&
+		len(admissionReview.Response.Warnings) > 0 {
+		properties[propertyWarnings] = strings.Join(admissionReview.Response.Warnings, warningsSeparator)
+	}
+
 	return &wgpolicy.PolicyReportResult{
 		Source:          policyReportSource,
 		Policy:          policy.GetUniqueName(),
 		Category:        category,
-		Severity:        computePolicyResultSeverity(policy),           // either info for monitor or empty
-		Timestamp:       timestamp,                                     // time the result was computed
-		Result:          computePolicyResult(errored, admissionReview), // pass, fail, error
+		Severity:        computePolicyResultSeverity(policy), // either info for monitor or empty
+		Timestamp:       timestamp,                           // time the result was computed
+		Result:          result,                              // pass, fail, error
 		Scored:          true,
 		SubjectSelector: &metav1.LabelSelector{},
 		// This field is marshalled to `message`
 		Description: message,
-		Properties:  computeProperties(policy),
+		Properties:  properties,
 	}
 }
 
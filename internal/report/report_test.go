@@ -7,6 +7,7 @@ import (
 	"github.com/kubewarden/audit-scanner/internal/constants"
 	policiesv1 "github.com/kubewarden/kubewarden-controller/api/policies/v1"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	admissionv1 "k8s.io/api/admission/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -119,6 +120,35 @@ func TestAddResultToPolicyReport(t *testing.T) {
 	}
 }
 
+func TestAddResultToPolicyReportSamePolicy(t *testing.T) {
+	policy := &policiesv1.AdmissionPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "namespace"}}
+	otherPolicy := &policiesv1.AdmissionPolicy{ObjectMeta: metav1.ObjectMeta{Name: "other-policy", Namespace: "namespace"}}
+	rejected := &admissionv1.AdmissionReview{Response: &admissionv1.AdmissionResponse{Allowed: false}}
+	allowed := &admissionv1.AdmissionReview{Response: &admissionv1.AdmissionResponse{Allowed: true}}
+
+	policyReport := NewPolicyReport("runUID", unstructured.Unstructured{})
+	AddResultToPolicyReport(policyReport, policy, rejected, false)
+	AddResultToPolicyReport(policyReport, otherPolicy, rejected, false)
+	// the policy matches the resource again, e.g. through another rule
+	AddResultToPolicyReport(policyReport, policy, allowed, false)
+
+	require.Len(t, policyReport.Results, 2)
+	assert.Equal(t, policy.GetUniqueName(), policyReport.Results[0].Policy)
+	assert.Equal(t, wgpolicy.PolicyResult(statusPass), policyReport.Results[0].Result)
+	assert.Equal(t, otherPolicy.GetUniqueName(), policyReport.Results[1].Policy)
+	assert.Equal(t, 1, policyReport.Summary.Pass)
+	assert.Equal(t, 1, policyReport.Summary.Fail)
+
+	clusterPolicyReport := NewClusterPolicyReport("runUID", unstructured.Unstructured{})
+	AddResultToClusterPolicyReport(clusterPolicyReport, policy, allowed, false)
+	AddResultToClusterPolicyReport(clusterPolicyReport, policy, nil, true)
+
+	require.Len(t, clusterPolicyReport.Results, 1)
+	assert.Equal(t, wgpolicy.PolicyResult(statusError), clusterPolicyReport.Results[0].Result)
+	assert.Equal(t, 0, clusterPolicyReport.Summary.Pass)
+	assert.Equal(t, 1, clusterPolicyReport.Summary.Error)
+}
+
 func TestNewClusterPolicyReport(t *testing.T) {
 	resource := unstructured.Unstructured{}
 	resource.SetUID("uid")
@@ -172,6 +202,35 @@ func TestAddResultToClusterPolicyReport(t *testing.T) {
 	assert.Equal(t, 0, clusterPolicyReport.Summary.Error)
 }
 
+func TestNewMirroredPolicyReport(t *testing.T) {
+	resource := unstructured.Unstructured{}
+	resource.SetUID("uid")
+	resource.SetName("test-namespace")
+	resource.SetAPIVersion("v1")
+	resource.SetKind("Namespace")
+	admissionReview := &admissionv1.AdmissionReview{
+		Response: &admissionv1.AdmissionResponse{Allowed: false},
+	}
+	clusterPolicyReport := NewClusterPolicyReport("runUID", resource)
+	AddResultToClusterPolicyReport(clusterPolicyReport, &policiesv1.ClusterAdmissionPolicy{}, admissionReview, false)
+
+	mirroredPolicyReport := NewMirroredPolicyReport(clusterPolicyReport, "compliance")
+
+	assert.Equal(t, "uid", mirroredPolicyReport.ObjectMeta.Name)
+	assert.Equal(t, "compliance", mirroredPolicyReport.ObjectMeta.Namespace)
+	assert.Equal(t, "runUID", mirroredPolicyReport.ObjectMeta.Labels[constants.AuditScannerRunUIDLabel])
+	assert.Equal(t, "true", mirroredPolicyReport.ObjectMeta.Labels[labelMirroredReport])
+	assert.Equal(t, clusterPolicyReport.ObjectMeta.OwnerReferences, mirroredPolicyReport.ObjectMeta.OwnerReferences)
+	assert.Equal(t, clusterPolicyReport.Scope, mirroredPolicyReport.Scope)
+	assert.Equal(t, clusterPolicyReport.Summary, mirroredPolicyReport.Summary)
+	assert.Equal(t, clusterPolicyReport.Results, mirroredPolicyReport.Results)
+
+	// the ClusterPolicyReport is left untouched
+	assert.NotContains(t, clusterPolicyReport.ObjectMeta.Labels, labelMirroredReport)
+	mirroredPolicyReport.Results[0].Result = statusPass
+	assert.Equal(t, wgpolicy.PolicyResult(statusFail), clusterPolicyReport.Results[0].Result)
+}
+
 func TestNewPolicyReportResult(t *testing.T) {
 	now := metav1.Timestamp{Seconds: time.Now().Unix()}
 
@@ -223,6 +282,39 @@ func TestNewPolicyReportResult(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Validating policy, allowed response with warnings",
+			policy: &policiesv1.ClusterAdmissionPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					UID:             "policy-uid",
+					ResourceVersion: "1",
+					Name:            "policy-name",
+				},
+			},
+			admissionReview: &admissionv1.AdmissionReview{
+				Response: &admissionv1.AdmissionResponse{
+					Allowed:  true,
+					Warnings: []string{"the image uses the latest tag", "the image is not signed"},
+				},
+			},
+			errored: false,
+			expectedResult: &wgpolicy.PolicyReportResult{
+				Source:          policyReportSource,
+				Policy:          "clusterwide-policy-name",
+				Result:          statusPass,
+				Timestamp:       now,
+				Scored:          true,
+				SubjectSelector: &metav1.LabelSelector{},
+				Description:     "",
+				Properties: map[string]string{
+					propertyPolicyUID:             "policy-uid",
+					propertyPolicyResourceVersion: "1",
+					propertyPolicyName:            "policy-name",
+					typeValidating:                valueTypeTrue,
+					propertyWarnings:              "the image uses the latest tag\nthe image is not signed",
+				},
+			},
+		},
 		{
 			name: "Mutating policy, rejected response",
 			policy: &policiesv1.AdmissionPolicy{
@@ -266,6 +358,37 @@ func TestNewPolicyReportResult(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Validating policy, rejected response without a message",
+			policy: &policiesv1.ClusterAdmissionPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					UID:             "policy-uid",
+					ResourceVersion: "1",
+					Name:            "policy-name",
+				},
+			},
+			admissionReview: &admissionv1.AdmissionReview{
+				Response: &admissionv1.AdmissionResponse{
+					Allowed: false,
+				},
+			},
+			errored: false,
+			expectedResult: &wgpolicy.PolicyReportResult{
+				Source:          policyReportSource,
+				Policy:          "clusterwide-policy-name",
+				Result:          statusFail,
+				Timestamp:       now,
+				Scored:          true,
+				SubjectSelector: &metav1.LabelSelector{},
+				Description:     defaultRejectionMessage,
+				Properties: map[string]string{
+					propertyPolicyUID:             "policy-uid",
+					propertyPolicyResourceVersion: "1",
+					propertyPolicyName:            "policy-name",
+					typeValidating:                valueTypeTrue,
+				},
+			},
+		},
 		{
 			name: "Validating policy in monitor mode, response error",
 			policy: &policiesv1.AdmissionPolicy{
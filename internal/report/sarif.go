@@ -0,0 +1,214 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	wgpolicy "sigs.k8s.io/wg-policy-prototypes/policy-report/pkg/api/wgpolicyk8s.io/v1alpha2"
+)
+
+const (
+	sarifVersion        = "2.1.0"
+	sarifSchema         = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifToolName       = "kubewarden-audit-scanner"
+	sarifToolURI        = "https://github.com/kubewarden/audit-scanner"
+	sarifLevelError     = "error"
+	sarifLevelWarning   = "warning"
+	sarifLogicalKind    = "resource"
+	sarifResourceScheme = "k8s://"
+)
+
+// The following types model the subset of the SARIF 2.1.0 specification
+// used by the audit scanner.
+// https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string            `json:"id"`
+	ShortDescription *sarifMessage     `json:"shortDescription,omitempty"`
+	Properties       map[string]string `json:"properties,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID    string            `json:"ruleId"`
+	Level     string            `json:"level"`
+	Message   sarifMessage      `json:"message"`
+	Locations []sarifLocation   `json:"locations"`
+	Props     map[string]string `json:"properties,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// WriteSARIF writes the failing and errored results of the retained reports
+// to w as a SARIF 2.1.0 log. Every policy becomes a rule, identified by the
+// policy name, and every result is located at the evaluated resource.
+func (s *PolicyReportStore) WriteSARIF(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(newSARIFLog(s.GetFailedResults())); err != nil {
+		return fmt.Errorf("cannot encode reports to SARIF: %w", err)
+	}
+
+	return nil
+}
+
+func newSARIFLog(failedResults []FailedResult) sarifLog {
+	rules := map[string]sarifRule{}
+	results := []sarifResult{}
+
+	for _, failedResult := range failedResults {
+		result := &failedResult.Result
+		level, ok := sarifLevel(result.Result)
+		if !ok {
+			continue
+		}
+		if _, found := rules[result.Policy]; !found {
+			rules[result.Policy] = newSARIFRule(result)
+		}
+		results = append(results, newSARIFResult(failedResult.Resource, result, level))
+	}
+
+	sortedRules := make([]sarifRule, 0, len(rules))
+	for _, rule := range rules {
+		sortedRules = append(sortedRules, rule)
+	}
+	slices.SortFunc(sortedRules, func(a, b sarifRule) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	return sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs: []sarifRun{
+			{
+				Tool: sarifTool{Driver: sarifDriver{
+					Name:           sarifToolName,
+					InformationURI: sarifToolURI,
+					Rules:          sortedRules,
+				}},
+				Results: results,
+			},
+		},
+	}
+}
+
+// sarifLevel maps the result of a policy evaluation to a SARIF level.
+// Policy violations are reported as errors, while policies that could not be
+// evaluated are reported as warnings. Other results are not reported.
+func sarifLevel(result wgpolicy.PolicyResult) (string, bool) {
+	switch result {
+	case statusFail:
+		return sarifLevelError, true
+	case statusError:
+		return sarifLevelWarning, true
+	default:
+		return "", false
+	}
+}
+
+func newSARIFRule(result *wgpolicy.PolicyReportResult) sarifRule {
+	rule := sarifRule{ID: result.Policy}
+	properties := map[string]string{}
+	if result.Category != "" {
+		properties["category"] = result.Category
+	}
+	if result.Severity != "" {
+		properties["severity"] = string(result.Severity)
+	}
+	if len(properties) > 0 {
+		rule.Properties = properties
+	}
+	if name := result.Properties[propertyPolicyName]; name != "" {
+		rule.ShortDescription = &sarifMessage{Text: fmt.Sprintf("Kubewarden policy %s", name)}
+	}
+
+	return rule
+}
+
+func newSARIFResult(scope *corev1.ObjectReference, result *wgpolicy.PolicyReportResult, level string) sarifResult {
+	resourceName := qualifiedResourceName(scope)
+	var name string
+	if scope != nil {
+		name = scope.Name
+	}
+
+	message := result.Description
+	if message == "" {
+		if result.Result == statusError {
+			message = fmt.Sprintf("policy %s could not be evaluated against %s", result.Policy, resourceName)
+		} else {
+			message = fmt.Sprintf("%s violates policy %s", resourceName, result.Policy)
+		}
+	}
+
+	sarifResult := sarifResult{
+		RuleID:  result.Policy,
+		Level:   level,
+		Message: sarifMessage{Text: message},
+		Locations: []sarifLocation{
+			{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: sarifResourceScheme + resourceName},
+				},
+				LogicalLocations: []sarifLogicalLocation{
+					{
+						Name:               name,
+						FullyQualifiedName: resourceName,
+						Kind:               sarifLogicalKind,
+					},
+				},
+			},
+		},
+	}
+	if scope != nil && scope.UID != "" {
+		sarifResult.Props = map[string]string{"resource-uid": string(scope.UID)}
+	}
+
+	return sarifResult
+}
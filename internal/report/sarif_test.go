@@ -0,0 +1,97 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	wgpolicy "sigs.k8s.io/wg-policy-prototypes/policy-report/pkg/api/wgpolicyk8s.io/v1alpha2"
+)
+
+func TestWriteSARIF(t *testing.T) {
+	store := NewPolicyReportStore(nil, WithInMemoryReports())
+
+	store.RecordPolicyReport(&wgpolicy.PolicyReport{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-uid", Namespace: "default"},
+		Scope: &corev1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       "Pod",
+			Namespace:  "default",
+			Name:       "nginx",
+			UID:        "pod-uid",
+		},
+		Results: []*wgpolicy.PolicyReportResult{
+			{Policy: "namespaced-default-privileged", Result: statusFail, Description: "privileged container"},
+			{Policy: "namespaced-default-capabilities", Result: statusPass},
+			{Policy: "namespaced-default-unreachable", Result: statusError},
+		},
+	})
+	store.RecordClusterPolicyReport(&wgpolicy.ClusterPolicyReport{
+		ObjectMeta: metav1.ObjectMeta{Name: "ns-uid"},
+		Scope: &corev1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       "Namespace",
+			Name:       "default",
+			UID:        "ns-uid",
+		},
+		Results: []*wgpolicy.PolicyReportResult{
+			{Policy: "clusterwide-labels", Result: statusFail, Severity: severityHigh, Category: "Resource validation"},
+		},
+	})
+
+	var buf bytes.Buffer
+	require.NoError(t, store.Write(&buf, OutputFormatSARIF))
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+	run := log.Runs[0]
+
+	ruleIDs := []string{}
+	for _, rule := range run.Tool.Driver.Rules {
+		ruleIDs = append(ruleIDs, rule.ID)
+	}
+	assert.Equal(t, []string{"clusterwide-labels", "namespaced-default-privileged", "namespaced-default-unreachable"}, ruleIDs)
+	assert.Equal(t, map[string]string{"category": "Resource validation", "severity": "high"}, run.Tool.Driver.Rules[0].Properties)
+
+	require.Len(t, run.Results, 3)
+
+	assert.Equal(t, "clusterwide-labels", run.Results[0].RuleID)
+	assert.Equal(t, "error", run.Results[0].Level)
+	assert.Equal(t, "v1/Namespace/default", run.Results[0].Locations[0].LogicalLocations[0].FullyQualifiedName)
+
+	assert.Equal(t, "namespaced-default-privileged", run.Results[1].RuleID)
+	assert.Equal(t, "error", run.Results[1].Level)
+	assert.Equal(t, "privileged container", run.Results[1].Message.Text)
+	assert.Equal(t, "k8s://v1/Pod/default/nginx", run.Results[1].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, "nginx", run.Results[1].Locations[0].LogicalLocations[0].Name)
+
+	assert.Equal(t, "namespaced-default-unreachable", run.Results[2].RuleID)
+	assert.Equal(t, "warning", run.Results[2].Level)
+	assert.NotEmpty(t, run.Results[2].Message.Text)
+}
+
+func TestWriteSARIFEmptyScan(t *testing.T) {
+	store := NewPolicyReportStore(nil, WithInMemoryReports())
+
+	var buf bytes.Buffer
+	require.NoError(t, store.WriteSARIF(&buf))
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	require.Len(t, log.Runs, 1)
+	assert.Empty(t, log.Runs[0].Results)
+	assert.NotNil(t, log.Runs[0].Tool.Driver.Rules)
+}
+
+func TestWriteUnsupportedFormat(t *testing.T) {
+	store := NewPolicyReportStore(nil, WithInMemoryReports())
+
+	require.Error(t, store.Write(&bytes.Buffer{}, OutputFormat("xml")))
+}
@@ -0,0 +1,99 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+
+	policiesv1 "github.com/kubewarden/kubewarden-controller/api/policies/v1"
+	wgpolicy "sigs.k8s.io/wg-policy-prototypes/policy-report/pkg/api/wgpolicyk8s.io/v1alpha2"
+)
+
+// severityRanks orders the severities policies can be annotated with,
+// from the lowest to the highest. Policies without a severity, or with an
+// unknown one, rank below all of them.
+func severityRanks() map[string]int {
+	return map[string]int{
+		severityInfo:     1,
+		severityLow:      2,
+		severityMedium:   3,
+		severityHigh:     4,
+		severityCritical: 5,
+	}
+}
+
+// SupportedSeverities returns the severities accepted by NewSeverityFilter, from the lowest to the highest.
+func SupportedSeverities() []string {
+	return []string{severityInfo, severityLow, severityMedium, severityHigh, severityCritical}
+}
+
+// SeverityFilter selects the results to be reported by their severity, i.e.
+// the severity of the policy that produced them, or info when the policy is in
+// monitor mode. A nil *SeverityFilter includes every result.
+type SeverityFilter struct {
+	minRank      int
+	includeUnset bool
+}
+
+// NewSeverityFilter returns a filter including the results of the policies
+// whose severity is at least minSeverity. The results of policies without a
+// severity are treated as the lowest severity and are included only when
+// includeUnset is true.
+// An empty minSeverity returns a nil filter, which includes every result.
+func NewSeverityFilter(minSeverity string, includeUnset bool) (*SeverityFilter, error) {
+	if minSeverity == "" {
+		return nil, nil //nolint:nilnil // a nil filter includes every result
+	}
+
+	minRank := severityRank(minSeverity)
+	if minRank == 0 {
+		return nil, fmt.Errorf("unknown severity %q, supported values are: %v", minSeverity, SupportedSeverities())
+	}
+
+	return &SeverityFilter{minRank: minRank, includeUnset: includeUnset}, nil
+}
+
+// Includes returns true if the results of the policy have to be reported.
+// The results are filtered on the severity they are reported with, so that
+// Includes and IncludesResult agree on the results of the same policy.
+func (f *SeverityFilter) Includes(policy policiesv1.Policy) bool {
+	if f == nil {
+		return true
+	}
+
+	var severity string
+	if policy != nil {
+		severity = string(computePolicyResultSeverity(policy))
+	}
+
+	return f.includesSeverity(severity)
+}
+
+// IncludesResult returns true if the result has to be reported.
+func (f *SeverityFilter) IncludesResult(result *wgpolicy.PolicyReportResult) bool {
+	if f == nil {
+		return true
+	}
+
+	return f.includesSeverity(string(result.Severity))
+}
+
+func (f *SeverityFilter) includesSeverity(severity string) bool {
+	rank := severityRank(severity)
+	if rank == 0 {
+		return f.includeUnset
+	}
+
+	return rank >= f.minRank
+}
+
+// CompareSeverity compares two severities, returning a negative number when a
+// is lower than b, a positive number when a is higher than b and 0 otherwise.
+// Unknown severities are lower than all the supported ones.
+func CompareSeverity(a, b string) int {
+	return severityRank(a) - severityRank(b)
+}
+
+// severityRank returns the rank of the severity, 0 when it's empty or unknown.
+func severityRank(severity string) int {
+	return severityRanks()[strings.ToLower(severity)]
+}
@@ -0,0 +1,69 @@
+package report
+
+import (
+	"testing"
+
+	policiesv1 "github.com/kubewarden/kubewarden-controller/api/policies/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	wgpolicy "sigs.k8s.io/wg-policy-prototypes/policy-report/pkg/api/wgpolicyk8s.io/v1alpha2"
+)
+
+func TestSeverityFilter(t *testing.T) {
+	policyWithSeverity := func(severity string) policiesv1.Policy {
+		policy := &policiesv1.ClusterAdmissionPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy"}}
+		if severity != "" {
+			policy.SetAnnotations(map[string]string{policiesv1.AnnotationSeverity: severity})
+		}
+		return policy
+	}
+	monitoredPolicyWithSeverity := func(severity string) policiesv1.Policy {
+		policy := &policiesv1.ClusterAdmissionPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy"}}
+		policy.SetAnnotations(map[string]string{policiesv1.AnnotationSeverity: severity})
+		policy.Spec.Mode = policiesv1.PolicyMode(policiesv1.PolicyModeStatusMonitor)
+		return policy
+	}
+
+	tests := []struct {
+		name         string
+		minSeverity  string
+		includeUnset bool
+		policy       policiesv1.Policy
+		expected     bool
+	}{
+		{"no threshold", "", false, policyWithSeverity(""), true},
+		{"above threshold", "medium", false, policyWithSeverity("critical"), true},
+		{"at threshold", "medium", false, policyWithSeverity("medium"), true},
+		{"below threshold", "medium", false, policyWithSeverity("low"), false},
+		{"case insensitive", "MEDIUM", false, policyWithSeverity("High"), true},
+		{"unset severity excluded", "info", false, policyWithSeverity(""), false},
+		{"unset severity included", "critical", true, policyWithSeverity(""), true},
+		{"unknown severity treated as unset", "info", false, policyWithSeverity("urgent"), false},
+		{"nil policy treated as unset", "info", true, nil, true},
+		{"monitor mode reported as info", "medium", false, monitoredPolicyWithSeverity("critical"), false},
+		{"monitor mode at info threshold", "info", false, monitoredPolicyWithSeverity("critical"), true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filter, err := NewSeverityFilter(test.minSeverity, test.includeUnset)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, filter.Includes(test.policy))
+
+			// the results reported for the policy are filtered the same way
+			var severity wgpolicy.PolicyResultSeverity
+			if test.policy != nil {
+				severity = computePolicyResultSeverity(test.policy)
+			}
+			result := &wgpolicy.PolicyReportResult{Severity: severity}
+			assert.Equal(t, test.expected, filter.IncludesResult(result))
+		})
+	}
+}
+
+func TestNewSeverityFilterUnknownSeverity(t *testing.T) {
+	_, err := NewSeverityFilter("urgent", false)
+	require.Error(t, err)
+}
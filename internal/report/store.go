@@ -2,46 +2,296 @@ package report
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"strconv"
+	"sync"
 
 	auditConstants "github.com/kubewarden/audit-scanner/internal/constants"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	corev1 "k8s.io/api/core/v1"
+	apimachineryerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	wgpolicy "sigs.k8s.io/wg-policy-prototypes/policy-report/pkg/api/wgpolicyk8s.io/v1alpha2"
 )
 
+// fieldManager is the field manager of all the writes of the reports, so that
+// the ownership of their fields is tracked consistently by the API server.
+const fieldManager = "audit-scanner"
+
+// ApplyMode is the strategy used to write the reports to the cluster.
+type ApplyMode string
+
+const (
+	// ApplyModeClientSide fetches the current report and patches it with the
+	// differences computed by the scanner.
+	ApplyModeClientSide ApplyMode = "client-side"
+	// ApplyModeServerSide sends the whole report as a server-side apply patch,
+	// letting the API server merge it with the concurrent writes.
+	ApplyModeServerSide ApplyMode = "server-side"
+)
+
+// SupportedApplyModes returns the apply modes accepted by WithApplyMode.
+func SupportedApplyModes() []ApplyMode {
+	return []ApplyMode{ApplyModeClientSide, ApplyModeServerSide}
+}
+
 // PolicyReportStore is a store for PolicyReport and ClusterPolicyReport.
 type PolicyReportStore struct {
 	// client is a controller-runtime client that knows about PolicyReport and ClusterPolicyReport CRDs
 	client client.Client
+	// dryRun disables any write to the cluster, the writes are logged instead
+	dryRun bool
+	// applyMode is the strategy used to create or patch the reports
+	applyMode ApplyMode
+	// retainReports enables keeping a copy of every report handed to the store in memory
+	retainReports bool
+	// onlyFailedResults drops the results other than fail and error from the reports written to the cluster
+	onlyFailedResults bool
+	// historyDepth is the number of scans kept in the history of every report, 0 disables the history
+	historyDepth int
+	// scanCoverage enables recording scanFilters and the time of writing in the annotations of every report
+	scanCoverage bool
+	scanFilters  map[string]string
+	// sampleSize is the number of resources of every type audited by a sampled scan, 0 when all of them are audited
+	sampleSize int
+	// stream receives every report as soon as it's recorded, it's nil when streaming is disabled
+	stream *json.Encoder
+	// mutex protects the totals and the retained reports, which are updated by concurrent scan workers
+	mutex                sync.Mutex
+	summary              wgpolicy.PolicyReportSummary
+	namespaces           int
+	resources            int
+	evaluations          int
+	policyReports        []wgpolicy.PolicyReport
+	clusterPolicyReports []wgpolicy.ClusterPolicyReport
+	// policySummaries holds the totals of the results of every policy, keyed by policy name
+	policySummaries map[string]*wgpolicy.PolicyReportSummary
+	streamErr       error
+}
+
+// StoreOption configures optional behaviour of a PolicyReportStore.
+type StoreOption func(*PolicyReportStore)
+
+// WithInMemoryReports makes the store keep a copy of every report it receives,
+// so that they can be written out at the end of the scan.
+func WithInMemoryReports() StoreOption {
+	return func(s *PolicyReportStore) {
+		s.retainReports = true
+	}
+}
+
+// WithReportStream makes the store write every report it receives to w, as a
+// JSON object on its own line, instead of waiting for the end of the scan.
+// Unlike WithInMemoryReports, the memory used doesn't grow with the size of
+// the scan.
+func WithReportStream(w io.Writer) StoreOption {
+	return func(s *PolicyReportStore) {
+		s.stream = json.NewEncoder(w)
+	}
+}
+
+// WithDryRun makes the store log the reports it would write to the cluster,
+// instead of creating, patching or deleting them.
+func WithDryRun() StoreOption {
+	return func(s *PolicyReportStore) {
+		s.dryRun = true
+	}
+}
+
+// WithApplyMode sets the strategy used to create or patch the reports.
+// The reports are written with client-side patches by default.
+func WithApplyMode(mode ApplyMode) StoreOption {
+	return func(s *PolicyReportStore) {
+		s.applyMode = mode
+	}
+}
+
+// WithOnlyFailedResults makes the store write to the cluster only the failing
+// and errored results of the reports, to bound the size of the reports of
+// clusters with many compliant resources. The summaries of the reports still
+// count all the results, and the reports recorded in memory or streamed are
+// kept whole.
+func WithOnlyFailedResults() StoreOption {
+	return func(s *PolicyReportStore) {
+		s.onlyFailedResults = true
+	}
+}
+
+// WithSampleSize marks the reports written to the cluster as the result of a
+// scan that audited at most sampleSize resources of every type, with the
+// kubewarden.io/policyreport-sample-size label, so that they aren't mistaken
+// for a complete coverage of the cluster. Nothing is marked when it's 0.
+func WithSampleSize(sampleSize int) StoreOption {
+	return func(s *PolicyReportStore) {
+		s.sampleSize = sampleSize
+	}
 }
 
 // NewPolicyReportStore creates a new PolicyReportStore.
-func NewPolicyReportStore(client client.Client) *PolicyReportStore {
-	return &PolicyReportStore{
-		client: client,
+func NewPolicyReportStore(c client.Client, opts ...StoreOption) *PolicyReportStore {
+	store := &PolicyReportStore{
+		applyMode: ApplyModeClientSide,
+	}
+	if c != nil {
+		store.client = client.WithFieldOwner(c, fieldManager)
+	}
+	for _, opt := range opts {
+		opt(store)
+	}
+
+	return store
+}
+
+// RecordPolicyReport adds the summary of the PolicyReport to the summary of the scan.
+// When the store was created with WithInMemoryReports, it keeps a copy of the report too.
+// When the store was created with WithReportStream, the report is written to the stream.
+func (s *PolicyReportStore) RecordPolicyReport(policyReport *wgpolicy.PolicyReport) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	addSummary(&s.summary, policyReport.Summary)
+	s.resources++
+	s.evaluations += countResults(policyReport.Results)
+	s.addPolicySummaries(policyReport.Results)
+	if s.retainReports {
+		s.policyReports = append(s.policyReports, *policyReport.DeepCopy())
 	}
+	s.streamPolicyReport(policyReport)
+}
+
+// RecordClusterPolicyReport adds the summary of the ClusterPolicyReport to the summary of the scan.
+// When the store was created with WithInMemoryReports, it keeps a copy of the report too.
+// When the store was created with WithReportStream, the report is written to the stream.
+func (s *PolicyReportStore) RecordClusterPolicyReport(clusterPolicyReport *wgpolicy.ClusterPolicyReport) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	addSummary(&s.summary, clusterPolicyReport.Summary)
+	s.resources++
+	s.evaluations += countResults(clusterPolicyReport.Results)
+	s.addPolicySummaries(clusterPolicyReport.Results)
+	if s.retainReports {
+		s.clusterPolicyReports = append(s.clusterPolicyReports, *clusterPolicyReport.DeepCopy())
+	}
+	s.streamClusterPolicyReport(clusterPolicyReport)
+}
+
+// Summary returns the sum of the summaries of all the reports recorded so far.
+func (s *PolicyReportStore) Summary() wgpolicy.PolicyReportSummary {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.summary
+}
+
+// isConflict returns true when a write failed because the report was changed
+// concurrently: either it was updated after being fetched, or it was created
+// after its absence was observed.
+func isConflict(err error) bool {
+	return apimachineryerrors.IsConflict(err) || apimachineryerrors.IsAlreadyExists(err)
+}
+
+// storedLabels returns the labels of a report written to the cluster.
+func (s *PolicyReportStore) storedLabels(reportLabels map[string]string) map[string]string {
+	if s.sampleSize <= 0 {
+		return reportLabels
+	}
+
+	storedLabels := maps.Clone(reportLabels)
+	if storedLabels == nil {
+		storedLabels = map[string]string{}
+	}
+	storedLabels[labelSampleSize] = strconv.Itoa(s.sampleSize)
+
+	return storedLabels
+}
+
+// storedResults returns the results of a report written to the cluster.
+func (s *PolicyReportStore) storedResults(results []*wgpolicy.PolicyReportResult) []*wgpolicy.PolicyReportResult {
+	if !s.onlyFailedResults {
+		return results
+	}
+
+	failedResults := []*wgpolicy.PolicyReportResult{}
+	for _, result := range results {
+		if result != nil && (result.Result == statusFail || result.Result == statusError) {
+			failedResults = append(failedResults, result)
+		}
+	}
+
+	return failedResults
+}
+
+func countResults(results []*wgpolicy.PolicyReportResult) int {
+	count := 0
+	for _, result := range results {
+		if result != nil {
+			count++
+		}
+	}
+
+	return count
+}
+
+func addSummary(total *wgpolicy.PolicyReportSummary, summary wgpolicy.PolicyReportSummary) {
+	total.Pass += summary.Pass
+	total.Fail += summary.Fail
+	total.Warn += summary.Warn
+	total.Error += summary.Error
+	total.Skip += summary.Skip
 }
 
 // CreateOrPatchPolicyReport creates or patches a PolicyReport.
+// The operation is retried when it conflicts with a concurrent write of the
+// same report, e.g. by an overlapping scan.
 func (s *PolicyReportStore) CreateOrPatchPolicyReport(ctx context.Context, policyReport *wgpolicy.PolicyReport) error {
-	oldPolicyReport := &wgpolicy.PolicyReport{ObjectMeta: metav1.ObjectMeta{
-		Name:      policyReport.GetName(),
-		Namespace: policyReport.GetNamespace(),
-	}}
+	if s.dryRun {
+		log.Info().Dict("dict", zerolog.Dict().
+			Str("report-name", policyReport.GetName()).
+			Str("report-namespace", policyReport.GetNamespace()).
+			Str("resource-name", policyReport.Scope.Name).
+			Int("pass", policyReport.Summary.Pass).
+			Int("fail", policyReport.Summary.Fail).
+			Int("error", policyReport.Summary.Error),
+		).Msg("dry-run: PolicyReport would be created or patched")
+		return nil
+	}
+	if s.applyMode == ApplyModeServerSide {
+		return s.applyPolicyReport(ctx, policyReport)
+	}
 
-	operation, err := controllerutil.CreateOrPatch(ctx, s.client, oldPolicyReport, func() error {
-		oldPolicyReport.ObjectMeta.Labels = policyReport.ObjectMeta.Labels
-		oldPolicyReport.ObjectMeta.OwnerReferences = policyReport.ObjectMeta.OwnerReferences
-		oldPolicyReport.Scope = policyReport.Scope
-		oldPolicyReport.Summary = policyReport.Summary
-		oldPolicyReport.Results = policyReport.Results
+	var operation controllerutil.OperationResult
+	err := retry.OnError(retry.DefaultRetry, isConflict, func() error {
+		// the current report is fetched again at every attempt, so that the
+		// patch is computed against its latest resourceVersion
+		oldPolicyReport := &wgpolicy.PolicyReport{ObjectMeta: metav1.ObjectMeta{
+			Name:      policyReport.GetName(),
+			Namespace: policyReport.GetNamespace(),
+		}}
 
-		return nil
+		var err error
+		operation, err = controllerutil.CreateOrPatch(ctx, s.client, oldPolicyReport, func() error {
+			oldPolicyReport.ObjectMeta.Labels = s.storedLabels(policyReport.ObjectMeta.Labels)
+			oldPolicyReport.ObjectMeta.OwnerReferences = policyReport.ObjectMeta.OwnerReferences
+			oldPolicyReport.ObjectMeta.Annotations = s.storedAnnotations(oldPolicyReport, policyReport.ObjectMeta.Labels, policyReport.Summary)
+			oldPolicyReport.Scope = policyReport.Scope
+			oldPolicyReport.Summary = policyReport.Summary
+			oldPolicyReport.Results = s.storedResults(policyReport.Results)
+
+			return nil
+		})
+
+		return err
 	})
 	if err != nil {
 		return err
@@ -58,11 +308,62 @@ func (s *PolicyReportStore) CreateOrPatchPolicyReport(ctx context.Context, polic
 	return nil
 }
 
+// applyPolicyReport writes the PolicyReport with a server-side apply patch.
+func (s *PolicyReportStore) applyPolicyReport(ctx context.Context, policyReport *wgpolicy.PolicyReport) error {
+	appliedPolicyReport := &wgpolicy.PolicyReport{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: wgpolicy.SchemeGroupVersion.String(),
+			Kind:       "PolicyReport",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            policyReport.GetName(),
+			Namespace:       policyReport.GetNamespace(),
+			Labels:          s.storedLabels(policyReport.ObjectMeta.Labels),
+			OwnerReferences: policyReport.ObjectMeta.OwnerReferences,
+		},
+		Scope:   policyReport.Scope,
+		Summary: policyReport.Summary,
+		Results: s.storedResults(policyReport.Results),
+	}
+	currentPolicyReport := &wgpolicy.PolicyReport{ObjectMeta: metav1.ObjectMeta{
+		Name:      policyReport.GetName(),
+		Namespace: policyReport.GetNamespace(),
+	}}
+	if s.historyDepth > 0 {
+		// the history isn't merged by the API server, it's rebuilt from the current report
+		if err := s.currentReport(ctx, currentPolicyReport); err != nil {
+			return err
+		}
+	}
+	appliedPolicyReport.ObjectMeta.Annotations = s.storedAnnotations(currentPolicyReport, policyReport.ObjectMeta.Labels, policyReport.Summary)
+
+	if err := s.client.Patch(ctx, appliedPolicyReport, client.Apply, client.ForceOwnership); err != nil {
+		return err
+	}
+
+	log.Debug().Dict("dict", zerolog.Dict()).
+		Str("report-name", policyReport.GetName()).
+		Str("report-version", appliedPolicyReport.GetResourceVersion()).
+		Str("resource-name", policyReport.Scope.Name).
+		Str("resource-namespace", policyReport.Scope.Namespace).
+		Str("resource-version", policyReport.Scope.ResourceVersion).
+		Msg("PolicyReport applied")
+
+	return nil
+}
+
+// DeleteOldPolicyReports deletes the PolicyReports of the namespace written by
+// the scans other than the given one. The mirrors of the ClusterPolicyReports
+// are kept, they are deleted by DeleteOldMirroredPolicyReports.
 func (s *PolicyReportStore) DeleteOldPolicyReports(ctx context.Context, scanRunID, namespace string) error {
-	labelSelector, err := labels.Parse(fmt.Sprintf("%s!=%s,%s=%s", auditConstants.AuditScannerRunUIDLabel, scanRunID, labelAppManagedBy, labelApp))
+	labelSelector, err := labels.Parse(fmt.Sprintf("%s!=%s,%s=%s,!%s", auditConstants.AuditScannerRunUIDLabel, scanRunID, labelAppManagedBy, labelApp, labelMirroredReport))
 	if err != nil {
 		return err
 	}
+	if s.dryRun {
+		log.Info().Str("labelSelector", labelSelector.String()).Str("namespace", namespace).Msg("dry-run: old PolicyReports would be deleted")
+		return nil
+	}
 	log.Debug().Str("labelSelector", labelSelector.String()).Msg("Deleting old PolicyReports")
 
 	return s.client.DeleteAllOf(ctx, &wgpolicy.PolicyReport{}, &client.DeleteAllOfOptions{ListOptions: client.ListOptions{
@@ -71,20 +372,130 @@ func (s *PolicyReportStore) DeleteOldPolicyReports(ctx context.Context, scanRunI
 	}})
 }
 
+// DeleteStalePolicyReports deletes the PolicyReports created by the audit scanner
+// in the namespaces that no longer exist or are being deleted. The namespaces
+// in scannedNamespaces are known to be alive, the others are looked up: the
+// reports of namespaces only excluded from the scan are kept, they are still
+// the latest results of their resources. PolicyReports not created by the
+// audit scanner are kept, as well as the mirrors of the ClusterPolicyReports.
+func (s *PolicyReportStore) DeleteStalePolicyReports(ctx context.Context, scannedNamespaces sets.Set[string]) error {
+	labelSelector, err := labels.Parse(fmt.Sprintf("%s,%s=%s,!%s", auditConstants.AuditScannerRunUIDLabel, labelAppManagedBy, labelApp, labelMirroredReport))
+	if err != nil {
+		return err
+	}
+
+	policyReports := &wgpolicy.PolicyReportList{}
+	if err := s.client.List(ctx, policyReports, &client.ListOptions{LabelSelector: labelSelector}); err != nil {
+		return err
+	}
+
+	var errs error
+	// goneNamespaces caches whether the namespaces not scanned are gone
+	goneNamespaces := map[string]bool{}
+	for i := range policyReports.Items {
+		policyReport := &policyReports.Items[i]
+		if scannedNamespaces.Has(policyReport.GetNamespace()) {
+			continue
+		}
+		gone, found := goneNamespaces[policyReport.GetNamespace()]
+		if !found {
+			var err error
+			gone, err = s.isNamespaceGone(ctx, policyReport.GetNamespace())
+			if err != nil {
+				errs = errors.Join(errs, err)
+				continue
+			}
+			goneNamespaces[policyReport.GetNamespace()] = gone
+		}
+		if !gone {
+			continue
+		}
+		if s.dryRun {
+			log.Info().Str("report-name", policyReport.GetName()).Str("namespace", policyReport.GetNamespace()).Msg("dry-run: stale PolicyReport would be deleted")
+			continue
+		}
+		log.Debug().Str("report-name", policyReport.GetName()).Str("namespace", policyReport.GetNamespace()).Msg("Deleting stale PolicyReport")
+		if err := s.client.Delete(ctx, policyReport); client.IgnoreNotFound(err) != nil {
+			errs = errors.Join(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// isNamespaceGone returns true when the namespace doesn't exist or is being
+// deleted.
+func (s *PolicyReportStore) isNamespaceGone(ctx context.Context, name string) (bool, error) {
+	namespace := &corev1.Namespace{}
+	err := s.client.Get(ctx, client.ObjectKey{Name: name}, namespace)
+	if apimachineryerrors.IsNotFound(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("cannot get namespace %s: %w", name, err)
+	}
+
+	return namespace.Status.Phase == corev1.NamespaceTerminating, nil
+}
+
+// DeleteOldMirroredPolicyReports deletes the mirrors of the ClusterPolicyReports
+// in the namespace written by the scans other than the given one.
+func (s *PolicyReportStore) DeleteOldMirroredPolicyReports(ctx context.Context, scanRunID, namespace string) error {
+	labelSelector, err := labels.Parse(fmt.Sprintf("%s!=%s,%s=%s,%s", auditConstants.AuditScannerRunUIDLabel, scanRunID, labelAppManagedBy, labelApp, labelMirroredReport))
+	if err != nil {
+		return err
+	}
+	if s.dryRun {
+		log.Info().Str("labelSelector", labelSelector.String()).Str("namespace", namespace).Msg("dry-run: old mirrored PolicyReports would be deleted")
+		return nil
+	}
+	log.Debug().Str("labelSelector", labelSelector.String()).Str("namespace", namespace).Msg("Deleting old mirrored PolicyReports")
+
+	return s.client.DeleteAllOf(ctx, &wgpolicy.PolicyReport{}, &client.DeleteAllOfOptions{ListOptions: client.ListOptions{
+		LabelSelector: labelSelector,
+		Namespace:     namespace,
+	}})
+}
+
 // CreateOrPatchClusterPolicyReport creates or patches a ClusterPolicyReport.
+// The operation is retried when it conflicts with a concurrent write of the
+// same report, e.g. by an overlapping scan.
 func (s *PolicyReportStore) CreateOrPatchClusterPolicyReport(ctx context.Context, clusterPolicyReport *wgpolicy.ClusterPolicyReport) error {
-	oldClusterPolicyReport := &wgpolicy.ClusterPolicyReport{ObjectMeta: metav1.ObjectMeta{
-		Name: clusterPolicyReport.GetName(),
-	}}
+	if s.dryRun {
+		log.Info().Dict("dict", zerolog.Dict().
+			Str("report-name", clusterPolicyReport.GetName()).
+			Str("resource-name", clusterPolicyReport.Scope.Name).
+			Int("pass", clusterPolicyReport.Summary.Pass).
+			Int("fail", clusterPolicyReport.Summary.Fail).
+			Int("error", clusterPolicyReport.Summary.Error),
+		).Msg("dry-run: ClusterPolicyReport would be created or patched")
+		return nil
+	}
+	if s.applyMode == ApplyModeServerSide {
+		return s.applyClusterPolicyReport(ctx, clusterPolicyReport)
+	}
 
-	operation, err := controllerutil.CreateOrPatch(ctx, s.client, oldClusterPolicyReport, func() error {
-		oldClusterPolicyReport.ObjectMeta.Labels = clusterPolicyReport.ObjectMeta.Labels
-		oldClusterPolicyReport.ObjectMeta.OwnerReferences = clusterPolicyReport.ObjectMeta.OwnerReferences
-		oldClusterPolicyReport.Scope = clusterPolicyReport.Scope
-		oldClusterPolicyReport.Summary = clusterPolicyReport.Summary
-		oldClusterPolicyReport.Results = clusterPolicyReport.Results
+	var operation controllerutil.OperationResult
+	err := retry.OnError(retry.DefaultRetry, isConflict, func() error {
+		// the current report is fetched again at every attempt, so that the
+		// patch is computed against its latest resourceVersion
+		oldClusterPolicyReport := &wgpolicy.ClusterPolicyReport{ObjectMeta: metav1.ObjectMeta{
+			Name: clusterPolicyReport.GetName(),
+		}}
 
-		return nil
+		var err error
+		operation, err = controllerutil.CreateOrPatch(ctx, s.client, oldClusterPolicyReport, func() error {
+			oldClusterPolicyReport.ObjectMeta.Labels = s.storedLabels(clusterPolicyReport.ObjectMeta.Labels)
+			oldClusterPolicyReport.ObjectMeta.OwnerReferences = clusterPolicyReport.ObjectMeta.OwnerReferences
+			oldClusterPolicyReport.ObjectMeta.Annotations = s.storedAnnotations(oldClusterPolicyReport, clusterPolicyReport.ObjectMeta.Labels, clusterPolicyReport.Summary)
+			oldClusterPolicyReport.Scope = clusterPolicyReport.Scope
+			oldClusterPolicyReport.Summary = clusterPolicyReport.Summary
+			oldClusterPolicyReport.Results = s.storedResults(clusterPolicyReport.Results)
+
+			return nil
+		})
+
+		return err
 	})
 	if err != nil {
 		return err
@@ -101,11 +512,59 @@ func (s *PolicyReportStore) CreateOrPatchClusterPolicyReport(ctx context.Context
 	return nil
 }
 
+// applyClusterPolicyReport writes the ClusterPolicyReport with a server-side apply patch.
+func (s *PolicyReportStore) applyClusterPolicyReport(ctx context.Context, clusterPolicyReport *wgpolicy.ClusterPolicyReport) error {
+	appliedClusterPolicyReport := &wgpolicy.ClusterPolicyReport{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: wgpolicy.SchemeGroupVersion.String(),
+			Kind:       "ClusterPolicyReport",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            clusterPolicyReport.GetName(),
+			Labels:          s.storedLabels(clusterPolicyReport.ObjectMeta.Labels),
+			OwnerReferences: clusterPolicyReport.ObjectMeta.OwnerReferences,
+		},
+		Scope:   clusterPolicyReport.Scope,
+		Summary: clusterPolicyReport.Summary,
+		Results: s.storedResults(clusterPolicyReport.Results),
+	}
+	currentClusterPolicyReport := &wgpolicy.ClusterPolicyReport{ObjectMeta: metav1.ObjectMeta{
+		Name: clusterPolicyReport.GetName(),
+	}}
+	if s.historyDepth > 0 {
+		// the history isn't merged by the API server, it's rebuilt from the current report
+		if err := s.currentReport(ctx, currentClusterPolicyReport); err != nil {
+			return err
+		}
+	}
+	appliedClusterPolicyReport.ObjectMeta.Annotations = s.storedAnnotations(currentClusterPolicyReport, clusterPolicyReport.ObjectMeta.Labels, clusterPolicyReport.Summary)
+
+	if err := s.client.Patch(ctx, appliedClusterPolicyReport, client.Apply, client.ForceOwnership); err != nil {
+		return err
+	}
+
+	log.Debug().Dict("dict", zerolog.Dict()).
+		Str("report-name", clusterPolicyReport.GetName()).
+		Str("report-version", appliedClusterPolicyReport.GetResourceVersion()).
+		Str("resource-name", clusterPolicyReport.Scope.Name).
+		Str("resource-version", clusterPolicyReport.Scope.ResourceVersion).
+		Msg("ClusterPolicyReport applied")
+
+	return nil
+}
+
+// DeleteOldClusterPolicyReports deletes the ClusterPolicyReports written by
+// the scans other than the given one. The summary report is kept, it's
+// replaced at the end of the scan.
 func (s *PolicyReportStore) DeleteOldClusterPolicyReports(ctx context.Context, scanRunID string) error {
-	labelSelector, err := labels.Parse(fmt.Sprintf("%s!=%s,%s=%s", auditConstants.AuditScannerRunUIDLabel, scanRunID, labelAppManagedBy, labelApp))
+	labelSelector, err := labels.Parse(fmt.Sprintf("%s!=%s,%s=%s,!%s", auditConstants.AuditScannerRunUIDLabel, scanRunID, labelAppManagedBy, labelApp, labelSummaryReport))
 	if err != nil {
 		return err
 	}
+	if s.dryRun {
+		log.Info().Str("labelSelector", labelSelector.String()).Msg("dry-run: old ClusterPolicyReports would be deleted")
+		return nil
+	}
 	log.Debug().Str("labelSelector", labelSelector.String()).Msg("Deleting old ClusterPolicyReports")
 
 	return s.client.DeleteAllOf(ctx, &wgpolicy.ClusterPolicyReport{}, &client.DeleteAllOfOptions{ListOptions: client.ListOptions{
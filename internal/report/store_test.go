@@ -10,11 +10,17 @@ import (
 	policiesv1 "github.com/kubewarden/kubewarden-controller/api/policies/v1"
 	"github.com/stretchr/testify/require"
 	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	apimachineryerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 	wgpolicy "sigs.k8s.io/wg-policy-prototypes/policy-report/pkg/api/wgpolicyk8s.io/v1alpha2"
 )
 
@@ -46,6 +52,60 @@ func TestCreatePolicyReport(t *testing.T) {
 	require.Equal(t, policyReport.Results, storedPolicyReport.Results)
 }
 
+func TestCreateReportsWithOnlyFailedResults(t *testing.T) {
+	fakeClient, err := testutils.NewFakeClient()
+	require.NoError(t, err)
+	store := NewPolicyReportStore(fakeClient, WithOnlyFailedResults(), WithInMemoryReports())
+
+	results := []*wgpolicy.PolicyReportResult{
+		{Policy: "pass", Result: statusPass},
+		{Policy: "fail", Result: statusFail},
+		{Policy: "warn", Result: statusWarn},
+		{Policy: "error", Result: statusError},
+	}
+	summary := wgpolicy.PolicyReportSummary{Pass: 1, Fail: 1, Warn: 1, Error: 1}
+
+	resource := unstructured.Unstructured{}
+	resource.SetUID("pod-uid")
+	resource.SetName("test-pod")
+	resource.SetNamespace("namespace")
+	resource.SetAPIVersion("v1")
+	resource.SetKind("Pod")
+	policyReport := NewPolicyReport("runUID", resource)
+	policyReport.Results = results
+	policyReport.Summary = summary
+	store.RecordPolicyReport(policyReport)
+	require.NoError(t, store.CreateOrPatchPolicyReport(context.TODO(), policyReport))
+
+	resource = unstructured.Unstructured{}
+	resource.SetUID("namespace-uid")
+	resource.SetName("test-namespace")
+	resource.SetAPIVersion("v1")
+	resource.SetKind("Namespace")
+	clusterPolicyReport := NewClusterPolicyReport("runUID", resource)
+	clusterPolicyReport.Results = results
+	clusterPolicyReport.Summary = summary
+	store.RecordClusterPolicyReport(clusterPolicyReport)
+	require.NoError(t, store.CreateOrPatchClusterPolicyReport(context.TODO(), clusterPolicyReport))
+
+	storedPolicyReport := &wgpolicy.PolicyReport{}
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: "pod-uid", Namespace: "namespace"}, storedPolicyReport)
+	require.NoError(t, err)
+	require.Equal(t, []*wgpolicy.PolicyReportResult{results[1], results[3]}, storedPolicyReport.Results)
+	require.Equal(t, summary, storedPolicyReport.Summary)
+
+	storedClusterPolicyReport := &wgpolicy.ClusterPolicyReport{}
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: "namespace-uid"}, storedClusterPolicyReport)
+	require.NoError(t, err)
+	require.Equal(t, []*wgpolicy.PolicyReportResult{results[1], results[3]}, storedClusterPolicyReport.Results)
+	require.Equal(t, summary, storedClusterPolicyReport.Summary)
+
+	// the reports of the scan results are kept whole
+	require.Len(t, store.Reports().PolicyReports[0].Results, 4)
+	require.Len(t, store.Reports().ClusterPolicyReports[0].Results, 4)
+	require.Equal(t, 8, store.ScanSummary().PolicyEvaluations)
+}
+
 func TestPatchPolicyReport(t *testing.T) {
 	fakeClient, err := testutils.NewFakeClient()
 	require.NoError(t, err)
@@ -171,6 +231,211 @@ func TestPatchClusterPolicyReport(t *testing.T) {
 	require.Equal(t, newClusterPolicyReport.Results, storedClusterPolicyReport.Results)
 }
 
+func TestPatchClusterPolicyReportConflict(t *testing.T) {
+	resource := unstructured.Unstructured{}
+	resource.SetUID("uid")
+	resource.SetAPIVersion("v1")
+	resource.SetKind("Namespace")
+	resource.SetName("test-namespace")
+	resource.SetResourceVersion("12345")
+
+	fakeClient, err := testutils.NewFakeClient(NewClusterPolicyReport("runUID", resource))
+	require.NoError(t, err)
+
+	// the first patch fails, as if the report was updated by another scan
+	// after being fetched
+	patches := 0
+	conflictingClient := interceptor.NewClient(fakeClient.(client.WithWatch), interceptor.Funcs{
+		Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			patches++
+			if patches == 1 {
+				return apimachineryerrors.NewConflict(schema.GroupResource{Group: wgpolicy.SchemeGroupVersion.Group, Resource: "clusterpolicyreports"}, obj.GetName(), fmt.Errorf("the object has been modified"))
+			}
+			return c.Patch(ctx, obj, patch, opts...)
+		},
+	})
+	store := NewPolicyReportStore(conflictingClient)
+
+	resource.SetResourceVersion("45678")
+	newClusterPolicyReport := NewClusterPolicyReport("newRunUID", resource)
+	err = store.CreateOrPatchClusterPolicyReport(context.TODO(), newClusterPolicyReport)
+	require.NoError(t, err)
+	require.Equal(t, 2, patches)
+
+	storedClusterPolicyReport := &wgpolicy.ClusterPolicyReport{}
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: newClusterPolicyReport.GetName()}, storedClusterPolicyReport)
+	require.NoError(t, err)
+	require.Equal(t, newClusterPolicyReport.ObjectMeta.Labels, storedClusterPolicyReport.ObjectMeta.Labels)
+	require.Equal(t, newClusterPolicyReport.Scope, storedClusterPolicyReport.Scope)
+}
+
+func TestCreateClusterPolicyReportConcurrently(t *testing.T) {
+	resource := unstructured.Unstructured{}
+	resource.SetUID("uid")
+	resource.SetAPIVersion("v1")
+	resource.SetKind("Namespace")
+	resource.SetName("test-namespace")
+	resource.SetResourceVersion("12345")
+
+	fakeClient, err := testutils.NewFakeClient()
+	require.NoError(t, err)
+
+	// another scan creates the report between the lookup and the creation
+	creates := 0
+	racingClient := interceptor.NewClient(fakeClient.(client.WithWatch), interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			creates++
+			if creates == 1 {
+				require.NoError(t, c.Create(ctx, NewClusterPolicyReport("otherRunUID", resource)))
+			}
+			return c.Create(ctx, obj, opts...)
+		},
+	})
+	store := NewPolicyReportStore(racingClient)
+
+	clusterPolicyReport := NewClusterPolicyReport("runUID", resource)
+	err = store.CreateOrPatchClusterPolicyReport(context.TODO(), clusterPolicyReport)
+	require.NoError(t, err)
+	require.Equal(t, 1, creates)
+
+	storedClusterPolicyReport := &wgpolicy.ClusterPolicyReport{}
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: clusterPolicyReport.GetName()}, storedClusterPolicyReport)
+	require.NoError(t, err)
+	require.Equal(t, clusterPolicyReport.ObjectMeta.Labels, storedClusterPolicyReport.ObjectMeta.Labels)
+}
+
+func TestCreatePolicyReportConcurrently(t *testing.T) {
+	resource := unstructured.Unstructured{}
+	resource.SetUID("uid")
+	resource.SetName("test-pod")
+	resource.SetNamespace("namespace")
+	resource.SetAPIVersion("v1")
+	resource.SetKind("Pod")
+	resource.SetResourceVersion("12345")
+
+	fakeClient, err := testutils.NewFakeClient()
+	require.NoError(t, err)
+
+	// another scan creates the report between the lookup and the creation
+	creates := 0
+	patches := 0
+	racingClient := interceptor.NewClient(fakeClient.(client.WithWatch), interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			creates++
+			if creates == 1 {
+				require.NoError(t, c.Create(ctx, NewPolicyReport("otherRunUID", resource)))
+			}
+			return c.Create(ctx, obj, opts...)
+		},
+		Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			patches++
+			return c.Patch(ctx, obj, patch, opts...)
+		},
+	})
+	store := NewPolicyReportStore(racingClient)
+
+	policyReport := NewPolicyReport("runUID", resource)
+	err = store.CreateOrPatchPolicyReport(context.TODO(), policyReport)
+	require.NoError(t, err)
+	require.Equal(t, 1, creates)
+	require.Equal(t, 1, patches)
+
+	storedPolicyReport := &wgpolicy.PolicyReport{}
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: policyReport.GetName(), Namespace: policyReport.GetNamespace()}, storedPolicyReport)
+	require.NoError(t, err)
+	require.Equal(t, policyReport.ObjectMeta.Labels, storedPolicyReport.ObjectMeta.Labels)
+}
+
+func TestPatchPolicyReportConflict(t *testing.T) {
+	resource := unstructured.Unstructured{}
+	resource.SetUID("uid")
+	resource.SetName("test-pod")
+	resource.SetNamespace("namespace")
+	resource.SetAPIVersion("v1")
+	resource.SetKind("Pod")
+	resource.SetResourceVersion("12345")
+
+	fakeClient, err := testutils.NewFakeClient(NewPolicyReport("runUID", resource))
+	require.NoError(t, err)
+
+	patches := 0
+	conflictingClient := interceptor.NewClient(fakeClient.(client.WithWatch), interceptor.Funcs{
+		Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			patches++
+			if patches == 1 {
+				return apimachineryerrors.NewConflict(schema.GroupResource{Group: wgpolicy.SchemeGroupVersion.Group, Resource: "policyreports"}, obj.GetName(), fmt.Errorf("the object has been modified"))
+			}
+			return c.Patch(ctx, obj, patch, opts...)
+		},
+	})
+	store := NewPolicyReportStore(conflictingClient)
+
+	newPolicyReport := NewPolicyReport("newRunUID", resource)
+	err = store.CreateOrPatchPolicyReport(context.TODO(), newPolicyReport)
+	require.NoError(t, err)
+	require.Equal(t, 2, patches)
+
+	storedPolicyReport := &wgpolicy.PolicyReport{}
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: newPolicyReport.GetName(), Namespace: newPolicyReport.GetNamespace()}, storedPolicyReport)
+	require.NoError(t, err)
+	require.Equal(t, newPolicyReport.ObjectMeta.Labels, storedPolicyReport.ObjectMeta.Labels)
+}
+
+func TestServerSideApply(t *testing.T) {
+	fakeClient, err := testutils.NewFakeClient()
+	require.NoError(t, err)
+
+	// the fake client doesn't support server-side apply, the patches are inspected instead
+	var appliedObjects []client.Object
+	applyingClient := interceptor.NewClient(fakeClient.(client.WithWatch), interceptor.Funcs{
+		Patch: func(_ context.Context, _ client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			require.Equal(t, types.ApplyPatchType, patch.Type())
+			patchOptions := &client.PatchOptions{}
+			patchOptions.ApplyOptions(opts)
+			require.Equal(t, fieldManager, patchOptions.FieldManager)
+			require.NotNil(t, patchOptions.Force)
+			require.True(t, *patchOptions.Force)
+
+			appliedObjects = append(appliedObjects, obj)
+			return nil
+		},
+	})
+	store := NewPolicyReportStore(applyingClient, WithApplyMode(ApplyModeServerSide))
+
+	pod := unstructured.Unstructured{}
+	pod.SetUID("pod-uid")
+	pod.SetName("test-pod")
+	pod.SetNamespace("namespace")
+	pod.SetAPIVersion("v1")
+	pod.SetKind("Pod")
+	policyReport := NewPolicyReport("runUID", pod)
+	require.NoError(t, store.CreateOrPatchPolicyReport(context.TODO(), policyReport))
+
+	namespace := unstructured.Unstructured{}
+	namespace.SetUID("namespace-uid")
+	namespace.SetName("namespace")
+	namespace.SetAPIVersion("v1")
+	namespace.SetKind("Namespace")
+	clusterPolicyReport := NewClusterPolicyReport("runUID", namespace)
+	require.NoError(t, store.CreateOrPatchClusterPolicyReport(context.TODO(), clusterPolicyReport))
+
+	require.Len(t, appliedObjects, 2)
+	appliedPolicyReport, ok := appliedObjects[0].(*wgpolicy.PolicyReport)
+	require.True(t, ok)
+	require.Equal(t, "PolicyReport", appliedPolicyReport.Kind)
+	require.Equal(t, wgpolicy.SchemeGroupVersion.String(), appliedPolicyReport.APIVersion)
+	require.Equal(t, policyReport.GetName(), appliedPolicyReport.GetName())
+	require.Equal(t, policyReport.GetNamespace(), appliedPolicyReport.GetNamespace())
+	require.Equal(t, policyReport.ObjectMeta.Labels, appliedPolicyReport.ObjectMeta.Labels)
+	require.Equal(t, policyReport.Scope, appliedPolicyReport.Scope)
+
+	appliedClusterPolicyReport, ok := appliedObjects[1].(*wgpolicy.ClusterPolicyReport)
+	require.True(t, ok)
+	require.Equal(t, "ClusterPolicyReport", appliedClusterPolicyReport.Kind)
+	require.Equal(t, clusterPolicyReport.GetName(), appliedClusterPolicyReport.GetName())
+	require.Equal(t, clusterPolicyReport.Scope, appliedClusterPolicyReport.Scope)
+}
+
 func TestDeletePolicyReport(t *testing.T) {
 	oldPolicyReport := testutils.NewPolicyReportFactory().
 		Name("old-report").Namespace("default").RunUID("old-uid").WithAppLabel().Build()
@@ -180,8 +445,11 @@ func TestDeletePolicyReport(t *testing.T) {
 		Name("new-report").Namespace("default").RunUID("new-uid").WithAppLabel().Build()
 	oldPolicyReportOtheNamespace := testutils.NewPolicyReportFactory().
 		Name("old-report-other-namespace").Namespace("other").RunUID("old-uid").WithAppLabel().Build()
+	oldMirroredPolicyReport := testutils.NewPolicyReportFactory().
+		Name("old-mirrored-report").Namespace("default").RunUID("old-uid").WithAppLabel().Build()
+	oldMirroredPolicyReport.Labels[labelMirroredReport] = labelMirroredReportValue
 
-	fakeClient, err := testutils.NewFakeClient(oldPolicyReport, otherOldPolicyReport, newPolicyReport, oldPolicyReportOtheNamespace)
+	fakeClient, err := testutils.NewFakeClient(oldPolicyReport, otherOldPolicyReport, newPolicyReport, oldPolicyReportOtheNamespace, oldMirroredPolicyReport)
 	require.NoError(t, err)
 	store := NewPolicyReportStore(fakeClient)
 
@@ -198,6 +466,14 @@ func TestDeletePolicyReport(t *testing.T) {
 	require.NoError(t, err)
 	err = fakeClient.List(context.TODO(), storedPolicyReportList, &client.ListOptions{LabelSelector: labelSelector, Namespace: "default"})
 	require.NoError(t, err)
+	require.Len(t, storedPolicyReportList.Items, 2)
+	// the mirrors of the ClusterPolicyReports are deleted by the cluster-wide scan
+	require.ElementsMatch(t, []string{"other-old-report", "old-mirrored-report"}, []string{storedPolicyReportList.Items[0].Name, storedPolicyReportList.Items[1].Name})
+
+	err = store.DeleteOldMirroredPolicyReports(context.Background(), "new-uid", "default")
+	require.NoError(t, err)
+	err = fakeClient.List(context.TODO(), storedPolicyReportList, &client.ListOptions{LabelSelector: labelSelector, Namespace: "default"})
+	require.NoError(t, err)
 	require.Len(t, storedPolicyReportList.Items, 1)
 	require.Equal(t, "other-old-report", storedPolicyReportList.Items[0].Name)
 
@@ -208,6 +484,39 @@ func TestDeletePolicyReport(t *testing.T) {
 	require.Len(t, storedPolicyReportList.Items, 1)
 }
 
+func TestDeleteStalePolicyReports(t *testing.T) {
+	excludedNamespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "excluded"}}
+	terminatingNamespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "terminating"},
+		Status:     corev1.NamespaceStatus{Phase: corev1.NamespaceTerminating},
+	}
+	objects := []runtime.Object{excludedNamespace, terminatingNamespace}
+	for _, namespace := range []string{"scanned", "excluded", "terminating", "deleted"} {
+		objects = append(objects, testutils.NewPolicyReportFactory().
+			Name("report").Namespace(namespace).RunUID("old-uid").WithAppLabel().Build())
+	}
+	userPolicyReport := testutils.NewPolicyReportFactory().Name("user-report").Namespace("deleted").Build()
+	objects = append(objects, userPolicyReport)
+
+	fakeClient, err := testutils.NewFakeClient(objects...)
+	require.NoError(t, err)
+	store := NewPolicyReportStore(fakeClient)
+
+	err = store.DeleteStalePolicyReports(context.Background(), sets.New("scanned"))
+	require.NoError(t, err)
+
+	storedPolicyReportList := &wgpolicy.PolicyReportList{}
+	err = fakeClient.List(context.TODO(), storedPolicyReportList)
+	require.NoError(t, err)
+	storedPolicyReports := []string{}
+	for _, policyReport := range storedPolicyReportList.Items {
+		storedPolicyReports = append(storedPolicyReports, policyReport.GetNamespace()+"/"+policyReport.GetName())
+	}
+	// the reports of the namespaces that still exist survive, even when they
+	// weren't scanned
+	require.ElementsMatch(t, []string{"scanned/report", "excluded/report", "deleted/user-report"}, storedPolicyReports)
+}
+
 func TestDeleteClusterPolicyReport(t *testing.T) {
 	oldPolicyReport := testutils.NewClusterPolicyReportFactory().
 		Name("old-report-with-app-label").WithAppLabel().RunUID("old-uid").Build()
@@ -239,3 +548,95 @@ func TestDeleteClusterPolicyReport(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, storedPolicyReportList.Items, 1)
 }
+
+func TestDryRun(t *testing.T) {
+	oldPolicyReport := testutils.NewPolicyReportFactory().
+		Name("old-report").Namespace("default").RunUID("old-uid").WithAppLabel().Build()
+	oldClusterPolicyReport := testutils.NewClusterPolicyReportFactory().
+		Name("old-cluster-report").WithAppLabel().RunUID("old-uid").Build()
+	fakeClient, err := testutils.NewFakeClient(oldPolicyReport, oldClusterPolicyReport)
+	require.NoError(t, err)
+	store := NewPolicyReportStore(fakeClient, WithDryRun(), WithInMemoryReports())
+
+	resource := unstructured.Unstructured{}
+	resource.SetUID("uid")
+	resource.SetName("test-pod")
+	resource.SetNamespace("default")
+
+	policyReport := NewPolicyReport("new-uid", resource)
+	require.NoError(t, store.CreateOrPatchPolicyReport(context.TODO(), policyReport))
+	store.RecordPolicyReport(policyReport)
+	clusterPolicyReport := NewClusterPolicyReport("new-uid", resource)
+	require.NoError(t, store.CreateOrPatchClusterPolicyReport(context.TODO(), clusterPolicyReport))
+	store.RecordClusterPolicyReport(clusterPolicyReport)
+	require.NoError(t, store.DeleteOldPolicyReports(context.TODO(), "new-uid", "default"))
+	require.NoError(t, store.DeleteOldClusterPolicyReports(context.TODO(), "new-uid"))
+
+	policyReportList := &wgpolicy.PolicyReportList{}
+	require.NoError(t, fakeClient.List(context.TODO(), policyReportList))
+	require.Len(t, policyReportList.Items, 1)
+	require.Equal(t, "old-report", policyReportList.Items[0].Name)
+
+	clusterPolicyReportList := &wgpolicy.ClusterPolicyReportList{}
+	require.NoError(t, fakeClient.List(context.TODO(), clusterPolicyReportList))
+	require.Len(t, clusterPolicyReportList.Items, 1)
+	require.Equal(t, "old-cluster-report", clusterPolicyReportList.Items[0].Name)
+
+	// the reports that would have been written are still available for the output
+	require.Len(t, store.Reports().PolicyReports, 1)
+	require.Len(t, store.Reports().ClusterPolicyReports, 1)
+}
+
+func TestSummary(t *testing.T) {
+	store := NewPolicyReportStore(nil)
+
+	store.RecordPolicyReport(&wgpolicy.PolicyReport{Summary: wgpolicy.PolicyReportSummary{Pass: 2, Fail: 1, Skip: 1}})
+	store.RecordPolicyReport(&wgpolicy.PolicyReport{Summary: wgpolicy.PolicyReportSummary{Pass: 1, Error: 1}})
+	store.RecordClusterPolicyReport(&wgpolicy.ClusterPolicyReport{Summary: wgpolicy.PolicyReportSummary{Fail: 2}})
+
+	require.Equal(t, wgpolicy.PolicyReportSummary{Pass: 3, Fail: 3, Error: 1, Skip: 1}, store.Summary())
+}
+
+func TestCreateReportsWithCancelledContext(t *testing.T) {
+	resource := unstructured.Unstructured{}
+	resource.SetUID("uid")
+	resource.SetAPIVersion("v1")
+	resource.SetKind("Pod")
+	resource.SetName("test-pod")
+	resource.SetNamespace("namespace")
+
+	fakeClient, err := testutils.NewFakeClient()
+	require.NoError(t, err)
+	// the fake client ignores the context, the API server fails the requests of a cancelled one
+	cancellableClient := interceptor.NewClient(fakeClient.(client.WithWatch), interceptor.Funcs{
+		Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			return c.Get(ctx, key, obj, opts...)
+		},
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			return c.Create(ctx, obj, opts...)
+		},
+	})
+	store := NewPolicyReportStore(cancellableClient)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// the writes of a cancelled scan are given up, rather than retried
+	err = store.CreateOrPatchPolicyReport(ctx, NewPolicyReport("runUID", resource))
+	require.ErrorIs(t, err, context.Canceled)
+	err = store.CreateOrPatchClusterPolicyReport(ctx, NewClusterPolicyReport("runUID", resource))
+	require.ErrorIs(t, err, context.Canceled)
+
+	policyReports := &wgpolicy.PolicyReportList{}
+	require.NoError(t, fakeClient.List(context.Background(), policyReports))
+	require.Empty(t, policyReports.Items)
+	clusterPolicyReports := &wgpolicy.ClusterPolicyReportList{}
+	require.NoError(t, fakeClient.List(context.Background(), clusterPolicyReports))
+	require.Empty(t, clusterPolicyReports.Items)
+}
@@ -0,0 +1,225 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	wgpolicy "sigs.k8s.io/wg-policy-prototypes/policy-report/pkg/api/wgpolicyk8s.io/v1alpha2"
+)
+
+const (
+	tableMinWidth = 0
+	tableTabWidth = 8
+	tablePadding  = 2
+	// tableMaxMessageLength is the maximum number of characters of the
+	// messages shown in the table, longer messages are truncated
+	tableMaxMessageLength = 80
+	tableEllipsis         = "…"
+)
+
+// ScanSummary holds the totals of a scan, computed from the reports recorded
+// by the store.
+type ScanSummary struct {
+	// Namespaces is the number of namespaces scanned completely
+	Namespaces int
+	// Resources is the number of resources audited, one for every report
+	Resources int
+	// PolicyEvaluations is the number of results in the reports
+	PolicyEvaluations int
+	Pass              int
+	Fail              int
+	Warn              int
+	Error             int
+	Skip              int
+
+	// SampleSize is the maximum number of resources of every type audited, 0
+	// when all of them have been audited
+	SampleSize int
+}
+
+// PolicySummary holds the totals of the results of a policy, across all the
+// resources it has been evaluated against.
+type PolicySummary struct {
+	Policy string `json:"policy"`
+	Pass   int    `json:"pass"`
+	Fail   int    `json:"fail"`
+	Warn   int    `json:"warn"`
+	Error  int    `json:"error"`
+	Skip   int    `json:"skip"`
+}
+
+// RecordNamespaceScanned counts a namespace whose scan completed.
+func (s *PolicyReportStore) RecordNamespaceScanned() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.namespaces++
+}
+
+// ScanSummary returns the totals of the reports recorded so far.
+func (s *PolicyReportStore) ScanSummary() ScanSummary {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return ScanSummary{
+		Namespaces:        s.namespaces,
+		Resources:         s.resources,
+		PolicyEvaluations: s.evaluations,
+		SampleSize:        s.sampleSize,
+		Pass:              s.summary.Pass,
+		Fail:              s.summary.Fail,
+		Warn:              s.summary.Warn,
+		Error:             s.summary.Error,
+		Skip:              s.summary.Skip,
+	}
+}
+
+// PolicySummaries returns the totals of the results of every policy, across
+// all the PolicyReports and ClusterPolicyReports recorded so far, sorted by
+// policy name. The totals are counted as the reports are recorded, hence they
+// don't require the store to be created with WithInMemoryReports.
+func (s *PolicyReportStore) PolicySummaries() []PolicySummary {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	policySummaries := make([]PolicySummary, 0, len(s.policySummaries))
+	for _, policy := range slices.Sorted(maps.Keys(s.policySummaries)) {
+		summary := s.policySummaries[policy]
+		policySummaries = append(policySummaries, PolicySummary{
+			Policy: policy,
+			Pass:   summary.Pass,
+			Fail:   summary.Fail,
+			Warn:   summary.Warn,
+			Error:  summary.Error,
+			Skip:   summary.Skip,
+		})
+	}
+
+	return policySummaries
+}
+
+// WritePolicySummaries writes the totals of the results of every policy to w
+// as a JSON array.
+func (s *PolicyReportStore) WritePolicySummaries(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(s.PolicySummaries()); err != nil {
+		return fmt.Errorf("cannot write the policy summaries: %w", err)
+	}
+
+	return nil
+}
+
+// WritePolicySummariesFile writes the totals of the results of every policy to
+// the file at path as a JSON array, replacing it as a whole like WriteFile.
+func (s *PolicyReportStore) WritePolicySummariesFile(path string) error {
+	return WriteFileAtomically(path, s.WritePolicySummaries)
+}
+
+// addPolicySummaries adds the results to the totals of their policies.
+// It must be called with the mutex held.
+func (s *PolicyReportStore) addPolicySummaries(results []*wgpolicy.PolicyReportResult) {
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		if s.policySummaries == nil {
+			s.policySummaries = map[string]*wgpolicy.PolicyReportSummary{}
+		}
+		summary, found := s.policySummaries[result.Policy]
+		if !found {
+			summary = &wgpolicy.PolicyReportSummary{}
+			s.policySummaries[result.Policy] = summary
+		}
+		switch result.Result {
+		case statusPass:
+			summary.Pass++
+		case statusFail:
+			summary.Fail++
+		case statusWarn:
+			summary.Warn++
+		case statusError:
+			summary.Error++
+		case statusSkip:
+			summary.Skip++
+		}
+	}
+}
+
+// Reset forgets the totals and the reports recorded so far, so that the store
+// can be reused for another scan.
+func (s *PolicyReportStore) Reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.summary = wgpolicy.PolicyReportSummary{}
+	s.namespaces = 0
+	s.resources = 0
+	s.evaluations = 0
+	s.policyReports = nil
+	s.clusterPolicyReports = nil
+	s.policySummaries = nil
+	s.streamErr = nil
+}
+
+// WriteTable writes the failing and errored results of the retained reports
+// to w as a column-aligned table, followed by the totals of every policy and
+// the summary of the scan.
+func (s *PolicyReportStore) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, tableMinWidth, tableTabWidth, tablePadding, ' ', 0)
+
+	if failedResults := s.GetFailedResults(); len(failedResults) > 0 {
+		fmt.Fprintln(tw, "NAMESPACE\tRESOURCE\tPOLICY\tRESULT\tMESSAGE")
+		for _, failedResult := range failedResults {
+			namespace, resource := "", ""
+			if failedResult.Resource != nil {
+				namespace = failedResult.Resource.Namespace
+				resource = failedResult.Resource.Kind + "/" + failedResult.Resource.Name
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+				namespace, resource, failedResult.Result.Policy, failedResult.Result.Result,
+				truncateMessage(failedResult.Result.Description))
+		}
+		fmt.Fprintln(tw)
+	}
+
+	if policySummaries := s.PolicySummaries(); len(policySummaries) > 0 {
+		fmt.Fprintln(tw, "POLICY\tPASS\tFAIL\tWARN\tERROR\tSKIP")
+		for _, policySummary := range policySummaries {
+			fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\n",
+				policySummary.Policy, policySummary.Pass, policySummary.Fail,
+				policySummary.Warn, policySummary.Error, policySummary.Skip)
+		}
+		fmt.Fprintln(tw)
+	}
+
+	summary := s.ScanSummary()
+	fmt.Fprintln(tw, "NAMESPACES\tRESOURCES\tEVALUATIONS\tPASS\tFAIL\tWARN\tERROR\tSKIP")
+	fmt.Fprintf(tw, "%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\n",
+		summary.Namespaces, summary.Resources, summary.PolicyEvaluations,
+		summary.Pass, summary.Fail, summary.Warn, summary.Error, summary.Skip)
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("cannot write the scan results table: %w", err)
+	}
+
+	return nil
+}
+
+// truncateMessage shortens the message to tableMaxMessageLength characters,
+// ending it with an ellipsis. Line breaks are replaced by spaces, so that
+// every result takes a single row.
+func truncateMessage(message string) string {
+	message = strings.Join(strings.Fields(message), " ")
+	runes := []rune(message)
+	if len(runes) <= tableMaxMessageLength {
+		return message
+	}
+
+	return string(runes[:tableMaxMessageLength-1]) + tableEllipsis
+}
@@ -0,0 +1,151 @@
+package report
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/kubewarden/audit-scanner/internal/constants"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	wgpolicy "sigs.k8s.io/wg-policy-prototypes/policy-report/pkg/api/wgpolicyk8s.io/v1alpha2"
+)
+
+const (
+	// SummaryReportName is the name of the ClusterPolicyReport holding the
+	// totals of the results of every policy. The names of the other reports
+	// are the UIDs of their resources, hence they never clash with it.
+	SummaryReportName = "kubewarden-audit-summary"
+	// labelSummaryReport tells the summary report apart from the reports of
+	// the resources, e.g. so that it isn't deleted as an old report.
+	labelSummaryReport      = "kubewarden.io/policyreport-summary"
+	labelSummaryReportValue = "true"
+)
+
+// NewSummaryReport returns the ClusterPolicyReport holding the totals of the
+// results of every policy recorded so far, across all the PolicyReports and
+// ClusterPolicyReports. Every policy has a single result, whose status is the
+// worst one among the results of the policy and whose properties hold the
+// number of results by status. The summary of the report holds the totals of
+// the scan.
+func (s *PolicyReportStore) NewSummaryReport(runUID string) *wgpolicy.ClusterPolicyReport {
+	policySummaries := s.PolicySummaries()
+	scanSummary := s.ScanSummary()
+
+	now := metav1.Timestamp{Seconds: time.Now().Unix()}
+	results := make([]*wgpolicy.PolicyReportResult, 0, len(policySummaries))
+	for _, policySummary := range policySummaries {
+		results = append(results, &wgpolicy.PolicyReportResult{
+			Source:    policyReportSource,
+			Policy:    policySummary.Policy,
+			Timestamp: now,
+			Result:    worstResult(policySummary),
+			Scored:    true,
+			Properties: map[string]string{
+				statusPass:  strconv.Itoa(policySummary.Pass),
+				statusFail:  strconv.Itoa(policySummary.Fail),
+				statusWarn:  strconv.Itoa(policySummary.Warn),
+				statusError: strconv.Itoa(policySummary.Error),
+				statusSkip:  strconv.Itoa(policySummary.Skip),
+			},
+		})
+	}
+
+	return &wgpolicy.ClusterPolicyReport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: SummaryReportName,
+			Labels: map[string]string{
+				labelAppManagedBy:                 labelApp,
+				labelPolicyReportVersion:          labelPolicyReportVersionValue,
+				labelSummaryReport:                labelSummaryReportValue,
+				constants.AuditScannerRunUIDLabel: runUID,
+			},
+		},
+		Summary: wgpolicy.PolicyReportSummary{
+			Pass:  scanSummary.Pass,
+			Fail:  scanSummary.Fail,
+			Warn:  scanSummary.Warn,
+			Error: scanSummary.Error,
+			Skip:  scanSummary.Skip,
+		},
+		Results: results,
+	}
+}
+
+// worstResult returns the most severe status among the results of a policy.
+func worstResult(policySummary PolicySummary) wgpolicy.PolicyResult {
+	switch {
+	case policySummary.Fail > 0:
+		return statusFail
+	case policySummary.Error > 0:
+		return statusError
+	case policySummary.Warn > 0:
+		return statusWarn
+	case policySummary.Pass > 0:
+		return statusPass
+	default:
+		return statusSkip
+	}
+}
+
+// CreateOrPatchSummaryReport writes the ClusterPolicyReport built by
+// NewSummaryReport, replacing the one of the previous scan. Unlike the
+// reports of the resources, all its results are written even when the store
+// keeps only the failed ones, since there is one result per policy.
+func (s *PolicyReportStore) CreateOrPatchSummaryReport(ctx context.Context, runUID string) error {
+	summaryReport := s.NewSummaryReport(runUID)
+	scanSummary := s.ScanSummary()
+	if s.dryRun {
+		log.Info().Dict("dict", zerolog.Dict().
+			Str("report-name", summaryReport.GetName()).
+			Int("policies", len(summaryReport.Results)).
+			Int("pass", summaryReport.Summary.Pass).
+			Int("fail", summaryReport.Summary.Fail).
+			Int("error", summaryReport.Summary.Error),
+		).Msg("dry-run: summary ClusterPolicyReport would be created or patched")
+		return nil
+	}
+
+	if s.applyMode == ApplyModeServerSide {
+		summaryReport.ObjectMeta.Labels = s.storedLabels(summaryReport.ObjectMeta.Labels)
+		summaryReport.ObjectMeta.Annotations = s.summaryAnnotations(&wgpolicy.ClusterPolicyReport{}, scanSummary)
+		summaryReport.TypeMeta = metav1.TypeMeta{
+			APIVersion: wgpolicy.SchemeGroupVersion.String(),
+			Kind:       "ClusterPolicyReport",
+		}
+		if err := s.client.Patch(ctx, summaryReport, client.Apply, client.ForceOwnership); err != nil {
+			return err
+		}
+		log.Debug().Str("report-name", summaryReport.GetName()).Msg("summary ClusterPolicyReport applied")
+		return nil
+	}
+
+	var operation controllerutil.OperationResult
+	err := retry.OnError(retry.DefaultRetry, isConflict, func() error {
+		oldSummaryReport := &wgpolicy.ClusterPolicyReport{ObjectMeta: metav1.ObjectMeta{
+			Name: summaryReport.GetName(),
+		}}
+
+		var err error
+		operation, err = controllerutil.CreateOrPatch(ctx, s.client, oldSummaryReport, func() error {
+			oldSummaryReport.ObjectMeta.Labels = s.storedLabels(summaryReport.ObjectMeta.Labels)
+			oldSummaryReport.ObjectMeta.Annotations = s.summaryAnnotations(oldSummaryReport, scanSummary)
+			oldSummaryReport.Summary = summaryReport.Summary
+			oldSummaryReport.Results = summaryReport.Results
+
+			return nil
+		})
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	log.Debug().Str("report-name", summaryReport.GetName()).Msgf("summary ClusterPolicyReport %s", operation)
+
+	return nil
+}
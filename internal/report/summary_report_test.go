@@ -0,0 +1,69 @@
+package report
+
+import (
+	"context"
+	"testing"
+
+	auditConstants "github.com/kubewarden/audit-scanner/internal/constants"
+	testutils "github.com/kubewarden/audit-scanner/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+	wgpolicy "sigs.k8s.io/wg-policy-prototypes/policy-report/pkg/api/wgpolicyk8s.io/v1alpha2"
+)
+
+func TestCreateOrPatchSummaryReport(t *testing.T) {
+	fakeClient, err := testutils.NewFakeClient()
+	require.NoError(t, err)
+	// the summary report holds all the policies, even when only the failed results are stored
+	store := NewPolicyReportStore(fakeClient, WithOnlyFailedResults())
+
+	store.RecordPolicyReport(&wgpolicy.PolicyReport{
+		Summary: wgpolicy.PolicyReportSummary{Pass: 1, Fail: 1},
+		Results: []*wgpolicy.PolicyReportResult{
+			{Policy: "no-privileged", Result: statusFail},
+			{Policy: "no-latest-tag", Result: statusPass},
+		},
+	})
+	store.RecordClusterPolicyReport(&wgpolicy.ClusterPolicyReport{
+		Summary: wgpolicy.PolicyReportSummary{Pass: 1, Error: 1, Skip: 1},
+		Results: []*wgpolicy.PolicyReportResult{
+			{Policy: "no-privileged", Result: statusPass},
+			{Policy: "require-labels", Result: statusError},
+			{Policy: "unused", Result: statusSkip},
+		},
+	})
+	require.NoError(t, store.CreateOrPatchSummaryReport(context.Background(), "first-uid"))
+
+	// the summary of the previous scan is replaced
+	store.RecordPolicyReport(&wgpolicy.PolicyReport{
+		Summary: wgpolicy.PolicyReportSummary{Warn: 1},
+		Results: []*wgpolicy.PolicyReportResult{
+			{Policy: "no-latest-tag", Result: statusWarn},
+		},
+	})
+	require.NoError(t, store.CreateOrPatchSummaryReport(context.Background(), "second-uid"))
+
+	summaryReport := &wgpolicy.ClusterPolicyReport{}
+	err = fakeClient.Get(context.Background(), types.NamespacedName{Name: SummaryReportName}, summaryReport)
+	require.NoError(t, err)
+	assert.Equal(t, "second-uid", summaryReport.GetLabels()[auditConstants.AuditScannerRunUIDLabel])
+	assert.Equal(t, wgpolicy.PolicyReportSummary{Pass: 2, Fail: 1, Warn: 1, Error: 1, Skip: 1}, summaryReport.Summary)
+
+	results := map[string]wgpolicy.PolicyResult{}
+	for _, result := range summaryReport.Results {
+		results[result.Policy] = result.Result
+	}
+	assert.Equal(t, map[string]wgpolicy.PolicyResult{
+		"no-latest-tag":  statusWarn,
+		"no-privileged":  statusFail,
+		"require-labels": statusError,
+		"unused":         statusSkip,
+	}, results)
+	assert.Equal(t, map[string]string{"pass": "1", "fail": "1", "warn": "0", "error": "0", "skip": "0"}, summaryReport.Results[1].Properties)
+
+	// the summary report isn't deleted together with the reports of the previous scans
+	require.NoError(t, store.DeleteOldClusterPolicyReports(context.Background(), "third-uid"))
+	err = fakeClient.Get(context.Background(), types.NamespacedName{Name: SummaryReportName}, summaryReport)
+	require.NoError(t, err)
+}
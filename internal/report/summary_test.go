@@ -0,0 +1,159 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	wgpolicy "sigs.k8s.io/wg-policy-prototypes/policy-report/pkg/api/wgpolicyk8s.io/v1alpha2"
+)
+
+func TestScanSummary(t *testing.T) {
+	store := NewPolicyReportStore(nil)
+	store.RecordNamespaceScanned()
+	store.RecordPolicyReport(&wgpolicy.PolicyReport{
+		Summary: wgpolicy.PolicyReportSummary{Pass: 1, Fail: 1},
+		Results: []*wgpolicy.PolicyReportResult{
+			{Policy: "pass-policy", Result: statusPass},
+			{Policy: "fail-policy", Result: statusFail},
+		},
+	})
+	store.RecordClusterPolicyReport(&wgpolicy.ClusterPolicyReport{
+		Summary: wgpolicy.PolicyReportSummary{Error: 1},
+		Results: []*wgpolicy.PolicyReportResult{
+			{Policy: "error-policy", Result: statusError},
+		},
+	})
+
+	assert.Equal(t, ScanSummary{
+		Namespaces:        1,
+		Resources:         2,
+		PolicyEvaluations: 3,
+		Pass:              1,
+		Fail:              1,
+		Error:             1,
+	}, store.ScanSummary())
+
+	var buf bytes.Buffer
+	require.NoError(t, store.Write(&buf, OutputFormatTable))
+	assert.Equal(t,
+		"POLICY        PASS  FAIL  WARN  ERROR  SKIP\n"+
+			"error-policy  0     0     0     1      0\n"+
+			"fail-policy   0     1     0     0      0\n"+
+			"pass-policy   1     0     0     0      0\n"+
+			"\n"+
+			"NAMESPACES  RESOURCES  EVALUATIONS  PASS  FAIL  WARN  ERROR  SKIP\n"+
+			"1           2          3            1     1     0     1      0\n",
+		buf.String())
+
+	store.Reset()
+	assert.Equal(t, ScanSummary{}, store.ScanSummary())
+	assert.Empty(t, store.PolicySummaries())
+}
+
+func TestResetDropsRetainedReports(t *testing.T) {
+	store := NewPolicyReportStore(nil, WithInMemoryReports())
+	store.RecordPolicyReport(&wgpolicy.PolicyReport{
+		Summary: wgpolicy.PolicyReportSummary{Pass: 1},
+		Results: []*wgpolicy.PolicyReportResult{{Policy: "pass-policy", Result: statusPass}},
+	})
+	store.RecordClusterPolicyReport(&wgpolicy.ClusterPolicyReport{
+		Summary: wgpolicy.PolicyReportSummary{Fail: 1},
+		Results: []*wgpolicy.PolicyReportResult{{Policy: "fail-policy", Result: statusFail}},
+	})
+	require.Len(t, store.Reports().ClusterPolicyReports, 1)
+
+	// every scan of a long running scanner starts from a clean store
+	store.Reset()
+	assert.Equal(t, ScanResult{
+		ClusterPolicyReports: []wgpolicy.ClusterPolicyReport{},
+		PolicyReports:        []wgpolicy.PolicyReport{},
+	}, store.Reports())
+	assert.Empty(t, store.GetFailedResults())
+}
+
+func TestWriteTable(t *testing.T) {
+	store := NewPolicyReportStore(nil, WithInMemoryReports())
+	store.RecordNamespaceScanned()
+	store.RecordPolicyReport(&wgpolicy.PolicyReport{
+		Scope:   &corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "pod"},
+		Summary: wgpolicy.PolicyReportSummary{Pass: 1, Fail: 1},
+		Results: []*wgpolicy.PolicyReportResult{
+			{Policy: "pass-policy", Result: statusPass, Description: "allowed"},
+			{Policy: "fail-policy", Result: statusFail, Description: strings.Repeat("a", tableMaxMessageLength+1)},
+		},
+	})
+	store.RecordClusterPolicyReport(&wgpolicy.ClusterPolicyReport{
+		Scope:   &corev1.ObjectReference{Kind: "Namespace", Name: "default"},
+		Summary: wgpolicy.PolicyReportSummary{Error: 1},
+		Results: []*wgpolicy.PolicyReportResult{
+			{Policy: "error-policy", Result: statusError, Description: "cannot\nbe evaluated"},
+		},
+	})
+
+	var buf bytes.Buffer
+	require.NoError(t, store.WriteTable(&buf))
+
+	truncatedMessage := strings.Repeat("a", tableMaxMessageLength-1) + tableEllipsis
+	assert.Equal(t,
+		"NAMESPACE  RESOURCE           POLICY        RESULT  MESSAGE\n"+
+			"           Namespace/default  error-policy  error   cannot be evaluated\n"+
+			"default    Pod/pod            fail-policy   fail    "+truncatedMessage+"\n"+
+			"\n"+
+			"POLICY        PASS  FAIL  WARN  ERROR  SKIP\n"+
+			"error-policy  0     0     0     1      0\n"+
+			"fail-policy   0     1     0     0      0\n"+
+			"pass-policy   1     0     0     0      0\n"+
+			"\n"+
+			"NAMESPACES  RESOURCES  EVALUATIONS  PASS  FAIL  WARN  ERROR  SKIP\n"+
+			"1           2          3            1     1     0     1      0\n",
+		buf.String())
+}
+
+func TestPolicySummaries(t *testing.T) {
+	// the totals don't depend on the reports being retained
+	store := NewPolicyReportStore(nil)
+	store.RecordPolicyReport(&wgpolicy.PolicyReport{
+		Results: []*wgpolicy.PolicyReportResult{
+			{Policy: "no-privileged", Result: statusFail},
+			{Policy: "no-latest-tag", Result: statusPass},
+		},
+	})
+	store.RecordPolicyReport(&wgpolicy.PolicyReport{
+		Results: []*wgpolicy.PolicyReportResult{
+			{Policy: "no-privileged", Result: statusPass},
+			{Policy: "no-latest-tag", Result: statusWarn},
+			nil,
+		},
+	})
+	store.RecordClusterPolicyReport(&wgpolicy.ClusterPolicyReport{
+		Results: []*wgpolicy.PolicyReportResult{
+			{Policy: "no-privileged", Result: statusFail},
+			{Policy: "require-labels", Result: statusSkip},
+			{Policy: "require-labels", Result: statusError},
+		},
+	})
+
+	expectedPolicySummaries := []PolicySummary{
+		{Policy: "no-latest-tag", Pass: 1, Warn: 1},
+		{Policy: "no-privileged", Pass: 1, Fail: 2},
+		{Policy: "require-labels", Error: 1, Skip: 1},
+	}
+	assert.Equal(t, expectedPolicySummaries, store.PolicySummaries())
+
+	var buf bytes.Buffer
+	require.NoError(t, store.WritePolicySummaries(&buf))
+	var writtenPolicySummaries []PolicySummary
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &writtenPolicySummaries))
+	assert.Equal(t, expectedPolicySummaries, writtenPolicySummaries)
+
+	// an empty scan is written as an empty array
+	store.Reset()
+	buf.Reset()
+	require.NoError(t, store.WritePolicySummaries(&buf))
+	assert.JSONEq(t, "[]", buf.String())
+}
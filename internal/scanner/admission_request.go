@@ -1,14 +1,27 @@
 package scanner
 
 import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
 	admv1 "k8s.io/api/admission/v1"
+	admv1beta1 "k8s.io/api/admission/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
-func newAdmissionRequest(resource unstructured.Unstructured) *admv1.AdmissionRequest {
+// admissionReviewKind is the kind of the AdmissionReviews, in all the API versions.
+const admissionReviewKind = "AdmissionReview"
+
+// newAdmissionRequest returns the AdmissionRequest auditing the resource,
+// framed as configured by admission. The request is a dry run, since the
+// outcome of the audit is never persisted. With UPDATE, both the object and
+// the old object are the resource.
+func newAdmissionRequest(resource unstructured.Unstructured, admission AdmissionConfig) *admv1.AdmissionRequest {
 	groupVersionKind := resource.GroupVersionKind()
+	dryRun := true
 	request := admv1.AdmissionRequest{
 		UID:  resource.GetUID(),
 		Name: resource.GetName(),
@@ -27,20 +40,116 @@ func newAdmissionRequest(resource unstructured.Unstructured) *admv1.AdmissionReq
 			Version: groupVersionKind.Version,
 			Kind:    groupVersionKind.Kind,
 		},
-		Operation: admv1.Create,
+		Operation: admission.Operation,
+		UserInfo:  admission.UserInfo,
+		DryRun:    &dryRun,
 		Namespace: resource.GetNamespace(),
 		Object: runtime.RawExtension{
 			Object: resource.DeepCopyObject(),
 			Raw:    nil,
 		},
 	}
+	if admission.Operation == admv1.Update {
+		// the resource is audited as updated without changes, so that the
+		// policies comparing the old and the new object see no difference
+		request.OldObject = runtime.RawExtension{
+			Object: resource.DeepCopyObject(),
+		}
+	}
 	return &request
 }
 
-func newAdmissionReview(resource unstructured.Unstructured) *admv1.AdmissionReview {
-	admissionRequest := newAdmissionRequest(resource)
+func newAdmissionReview(resource unstructured.Unstructured, admission AdmissionConfig) *admv1.AdmissionReview {
+	admissionRequest := newAdmissionRequest(resource, admission)
 	return &admv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: admv1.SchemeGroupVersion.String(),
+			Kind:       admissionReviewKind,
+		},
 		Request:  admissionRequest,
 		Response: nil,
 	}
 }
+
+// newV1beta1AdmissionReview returns the admission.k8s.io/v1beta1 version of
+// the AdmissionReview, sent to the Policy Servers expecting it.
+func newV1beta1AdmissionReview(admissionReview *admv1.AdmissionReview) *admv1beta1.AdmissionReview {
+	request := admissionReview.Request
+	return &admv1beta1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: admv1beta1.SchemeGroupVersion.String(),
+			Kind:       admissionReviewKind,
+		},
+		Request: &admv1beta1.AdmissionRequest{
+			UID:                request.UID,
+			Kind:               request.Kind,
+			Resource:           request.Resource,
+			SubResource:        request.SubResource,
+			RequestKind:        request.RequestKind,
+			RequestResource:    request.RequestResource,
+			RequestSubResource: request.RequestSubResource,
+			Name:               request.Name,
+			Namespace:          request.Namespace,
+			Operation:          admv1beta1.Operation(request.Operation),
+			UserInfo:           request.UserInfo,
+			Object:             request.Object,
+			OldObject:          request.OldObject,
+			DryRun:             request.DryRun,
+			Options:            request.Options,
+		},
+	}
+}
+
+// decodeAdmissionReview deserializes the AdmissionReview answered by a Policy
+// Server into the type of apiVersion, the one of the AdmissionReview sent, and
+// returns it as an admission.k8s.io/v1 one.
+func decodeAdmissionReview(body []byte, apiVersion string) (*admv1.AdmissionReview, error) {
+	if apiVersion != admv1beta1.SchemeGroupVersion.String() {
+		admissionReview := admv1.AdmissionReview{}
+		if err := json.Unmarshal(body, &admissionReview); err != nil {
+			return nil, err
+		}
+		return &admissionReview, nil
+	}
+
+	v1beta1AdmissionReview := admv1beta1.AdmissionReview{}
+	if err := json.Unmarshal(body, &v1beta1AdmissionReview); err != nil {
+		return nil, err
+	}
+	admissionReview := admv1.AdmissionReview{TypeMeta: v1beta1AdmissionReview.TypeMeta}
+	if response := v1beta1AdmissionReview.Response; response != nil {
+		admissionReview.Response = &admv1.AdmissionResponse{
+			UID:              response.UID,
+			Allowed:          response.Allowed,
+			Result:           response.Result,
+			Patch:            response.Patch,
+			PatchType:        (*admv1.PatchType)(response.PatchType),
+			AuditAnnotations: response.AuditAnnotations,
+			Warnings:         response.Warnings,
+		}
+	}
+
+	return &admissionReview, nil
+}
+
+// newAdmissionReviewPayload returns a function serializing the AdmissionReview
+// of the resource. The AdmissionReview doesn't depend on the policy, so it's
+// serialized only once, the first time it's needed, and the same payload is
+// sent to all the policies evaluating the resource.
+// The fields selected by the redactions are removed before serializing it, and
+// it's framed with the API version configured by admission.
+func newAdmissionReviewPayload(resource unstructured.Unstructured, admission AdmissionConfig, redactions []Redaction) func() ([]byte, error) {
+	return sync.OnceValues(func() ([]byte, error) {
+		admissionReview := newAdmissionReview(redact(resource, redactions), admission)
+		var framedAdmissionReview any = admissionReview
+		if admission.APIVersion == admv1beta1.SchemeGroupVersion.String() {
+			framedAdmissionReview = newV1beta1AdmissionReview(admissionReview)
+		}
+		payload, err := json.Marshal(framedAdmissionReview)
+		if err != nil {
+			return nil, fmt.Errorf("cannot serialize the AdmissionReview of %q: %w", resource.GetName(), err)
+		}
+
+		return payload, nil
+	})
+}
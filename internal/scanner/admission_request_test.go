@@ -1,10 +1,14 @@
 package scanner
 
 import (
+	"encoding/json"
+	"reflect"
 	"testing"
 
 	"github.com/google/uuid"
 	admv1 "k8s.io/api/admission/v1"
+	admv1beta1 "k8s.io/api/admission/v1beta1"
+	authenticationv1 "k8s.io/api/authentication/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
@@ -32,7 +36,7 @@ func generateUnstructuredPodObject() unstructured.Unstructured {
 
 func TestObjectInAdmissionRequest(t *testing.T) {
 	obj := generateUnstructuredPodObject()
-	admissionRequest := newAdmissionRequest(obj)
+	admissionRequest := newAdmissionRequest(obj, AdmissionConfig{Operation: admv1.Create})
 
 	admReqObj := admissionRequest.Object.Object
 	if admReqObj.GetObjectKind().GroupVersionKind().Group != obj.GroupVersionKind().Group {
@@ -48,7 +52,7 @@ func TestObjectInAdmissionRequest(t *testing.T) {
 
 func TestBasicInfoInAdmissionRequest(t *testing.T) {
 	obj := generateUnstructuredPodObject()
-	admissionRequest := newAdmissionRequest(obj)
+	admissionRequest := newAdmissionRequest(obj, AdmissionConfig{Operation: admv1.Create})
 
 	if admissionRequest.Kind.Group != obj.GroupVersionKind().Group {
 		t.Errorf("Group diverge")
@@ -84,7 +88,7 @@ func TestBasicInfoInAdmissionRequest(t *testing.T) {
 
 func TestGetAdmissionReview(t *testing.T) {
 	obj := generateUnstructuredPodObject()
-	admissionReview := newAdmissionReview(obj)
+	admissionReview := newAdmissionReview(obj, AdmissionConfig{Operation: admv1.Create})
 
 	if admissionReview.Response != nil {
 		t.Fatalf("Response should not be set")
@@ -123,3 +127,214 @@ func TestGetAdmissionReview(t *testing.T) {
 		t.Errorf("Operation diverge")
 	}
 }
+
+func TestAdmissionReviewPayloadIsSerializedOnce(t *testing.T) {
+	obj := generateUnstructuredPodObject()
+	payload := newAdmissionReviewPayload(obj, AdmissionConfig{Operation: admv1.Create}, nil)
+
+	first, err := payload()
+	if err != nil {
+		t.Fatalf("cannot serialize AdmissionReview: %v", err)
+	}
+	second, err := payload()
+	if err != nil {
+		t.Fatalf("cannot serialize AdmissionReview: %v", err)
+	}
+	if &first[0] != &second[0] {
+		t.Errorf("AdmissionReview serialized more than once")
+	}
+
+	admissionReview := admv1.AdmissionReview{}
+	if err := json.Unmarshal(first, &admissionReview); err != nil {
+		t.Fatalf("cannot deserialize AdmissionReview: %v", err)
+	}
+	if admissionReview.Request.UID != obj.GetUID() {
+		t.Errorf("UID diverge")
+	}
+	if admissionReview.Request.Name != resourceName {
+		t.Errorf("Name diverge")
+	}
+}
+
+func TestAdmissionRequestOperation(t *testing.T) {
+	obj := generateUnstructuredPodObject()
+
+	for _, operation := range SupportedAdmissionOperations() {
+		admissionRequest := newAdmissionRequest(obj, AdmissionConfig{Operation: operation})
+		if admissionRequest.Operation != operation {
+			t.Errorf("Operation diverge: expected %s, got %s", operation, admissionRequest.Operation)
+		}
+		// the audit never persists the resources
+		if admissionRequest.DryRun == nil || !*admissionRequest.DryRun {
+			t.Errorf("DryRun should be set")
+		}
+	}
+}
+
+func TestNewScannerAdmissionOperation(t *testing.T) {
+	scanner, err := NewScanner(newTestConfig(nil, nil, nil))
+	if err != nil {
+		t.Fatalf("cannot create scanner: %v", err)
+	}
+	// the resources are audited as if they were created by default
+	if scanner.admission.Operation != admv1.Create {
+		t.Errorf("Operation should default to CREATE, got %s", scanner.admission.Operation)
+	}
+
+	config := newTestConfig(nil, nil, nil)
+	config.Admission.Operation = admv1.Delete
+	if _, err := NewScanner(config); err == nil {
+		t.Errorf("DELETE should not be a supported operation")
+	}
+}
+
+func TestAdmissionReviewPayloadFramings(t *testing.T) {
+	obj := generateUnstructuredPodObject()
+
+	tests := []struct {
+		operation       admv1.Operation
+		expectOldObject bool
+	}{
+		{operation: admv1.Create, expectOldObject: false},
+		{operation: admv1.Update, expectOldObject: true},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.operation), func(t *testing.T) {
+			payload, err := newAdmissionReviewPayload(obj, AdmissionConfig{Operation: test.operation}, nil)()
+			if err != nil {
+				t.Fatalf("cannot serialize AdmissionReview: %v", err)
+			}
+			admissionReview := admv1.AdmissionReview{}
+			if err := json.Unmarshal(payload, &admissionReview); err != nil {
+				t.Fatalf("cannot deserialize AdmissionReview: %v", err)
+			}
+			admissionRequest := admissionReview.Request
+
+			if admissionRequest.Operation != test.operation {
+				t.Errorf("Operation diverge: expected %s, got %s", test.operation, admissionRequest.Operation)
+			}
+			if admissionRequest.DryRun == nil || !*admissionRequest.DryRun {
+				t.Errorf("DryRun should be set")
+			}
+			if len(admissionRequest.Object.Raw) == 0 {
+				t.Fatalf("Object should be set")
+			}
+			if !test.expectOldObject && len(admissionRequest.OldObject.Raw) != 0 {
+				t.Errorf("OldObject should not be set, got %s", admissionRequest.OldObject.Raw)
+			}
+			// the resource is updated without changes
+			if test.expectOldObject && string(admissionRequest.OldObject.Raw) != string(admissionRequest.Object.Raw) {
+				t.Errorf("OldObject diverge from Object: %s != %s", admissionRequest.OldObject.Raw, admissionRequest.Object.Raw)
+			}
+		})
+	}
+}
+
+func TestAdmissionRequestUserInfo(t *testing.T) {
+	obj := generateUnstructuredPodObject()
+	userInfo := authenticationv1.UserInfo{
+		Username: "system:serviceaccount:kubewarden:audit-scanner",
+		Groups:   []string{"system:serviceaccounts", "system:authenticated"},
+	}
+
+	payload, err := newAdmissionReviewPayload(obj, AdmissionConfig{Operation: admv1.Create, UserInfo: userInfo}, nil)()
+	if err != nil {
+		t.Fatalf("cannot serialize AdmissionReview: %v", err)
+	}
+	admissionReview := admv1.AdmissionReview{}
+	if err := json.Unmarshal(payload, &admissionReview); err != nil {
+		t.Fatalf("cannot deserialize AdmissionReview: %v", err)
+	}
+	if !reflect.DeepEqual(admissionReview.Request.UserInfo, userInfo) {
+		t.Errorf("UserInfo diverge: expected %v, got %v", userInfo, admissionReview.Request.UserInfo)
+	}
+
+	// the user info is left empty when it's not configured
+	admissionRequest := newAdmissionRequest(obj, AdmissionConfig{Operation: admv1.Create})
+	if !reflect.DeepEqual(admissionRequest.UserInfo, authenticationv1.UserInfo{}) {
+		t.Errorf("UserInfo should be empty, got %v", admissionRequest.UserInfo)
+	}
+}
+
+func TestAdmissionReviewPayloadAPIVersions(t *testing.T) {
+	obj := generateUnstructuredPodObject()
+
+	for _, apiVersion := range SupportedAdmissionAPIVersions() {
+		t.Run(apiVersion, func(t *testing.T) {
+			admission := AdmissionConfig{Operation: admv1.Update, APIVersion: apiVersion}
+			payload, err := newAdmissionReviewPayload(obj, admission, nil)()
+			if err != nil {
+				t.Fatalf("cannot serialize AdmissionReview: %v", err)
+			}
+			// the request is the same in all the API versions
+			admissionReview := admv1beta1.AdmissionReview{}
+			if err := json.Unmarshal(payload, &admissionReview); err != nil {
+				t.Fatalf("cannot deserialize AdmissionReview: %v", err)
+			}
+			if admissionReview.APIVersion != apiVersion {
+				t.Errorf("apiVersion diverge: expected %s, got %s", apiVersion, admissionReview.APIVersion)
+			}
+			if admissionReview.Kind != "AdmissionReview" {
+				t.Errorf("kind diverge: expected AdmissionReview, got %s", admissionReview.Kind)
+			}
+			if admissionReview.Request.UID != obj.GetUID() {
+				t.Errorf("UID diverge")
+			}
+			if admissionReview.Request.Operation != admv1beta1.Update {
+				t.Errorf("Operation diverge: expected %s, got %s", admv1beta1.Update, admissionReview.Request.Operation)
+			}
+			if len(admissionReview.Request.OldObject.Raw) == 0 {
+				t.Errorf("OldObject should be set")
+			}
+		})
+	}
+}
+
+func TestDecodeAdmissionReview(t *testing.T) {
+	body := []byte(`{"apiVersion":"admission.k8s.io/v1beta1","kind":"AdmissionReview","response":{"uid":"uid","allowed":false,"status":{"message":"rejected"},"warnings":["deprecated"]}}`)
+
+	admissionReview, err := decodeAdmissionReview(body, admv1beta1.SchemeGroupVersion.String())
+	if err != nil {
+		t.Fatalf("cannot deserialize AdmissionReview: %v", err)
+	}
+	response := admissionReview.Response
+	if response == nil {
+		t.Fatalf("Response should be set")
+	}
+	if response.UID != "uid" || response.Allowed {
+		t.Errorf("Response diverge: %v", response)
+	}
+	if response.Result == nil || response.Result.Message != "rejected" {
+		t.Errorf("Result diverge: %v", response.Result)
+	}
+	if !reflect.DeepEqual(response.Warnings, []string{"deprecated"}) {
+		t.Errorf("Warnings diverge: %v", response.Warnings)
+	}
+
+	// the AdmissionReviews without a response are returned as they are, the
+	// callers reject them
+	admissionReview, err = decodeAdmissionReview([]byte(`{}`), admv1beta1.SchemeGroupVersion.String())
+	if err != nil {
+		t.Fatalf("cannot deserialize AdmissionReview: %v", err)
+	}
+	if admissionReview.Response != nil {
+		t.Errorf("Response should not be set")
+	}
+}
+
+func TestNewScannerAdmissionAPIVersion(t *testing.T) {
+	scanner, err := NewScanner(newTestConfig(nil, nil, nil))
+	if err != nil {
+		t.Fatalf("cannot create scanner: %v", err)
+	}
+	if scanner.admission.APIVersion != admv1.SchemeGroupVersion.String() {
+		t.Errorf("APIVersion should default to %s, got %s", admv1.SchemeGroupVersion, scanner.admission.APIVersion)
+	}
+
+	config := newTestConfig(nil, nil, nil)
+	config.Admission.APIVersion = "admission.k8s.io/v2"
+	if _, err := NewScanner(config); err == nil {
+		t.Errorf("admission.k8s.io/v2 should not be a supported API version")
+	}
+}
@@ -0,0 +1,188 @@
+package scanner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const checkpointFilePermissions = 0o600
+
+// Checkpoint records the progress of a scan, so that an interrupted scan can
+// be resumed without auditing again what it has completed already.
+// The continue tokens of the lists in progress are not recorded: the API server
+// expires them within minutes, hence the resource types whose listing was
+// interrupted are listed again from the start.
+type Checkpoint struct {
+	// RunUID is the ID of the scan, the resumed scan reuses it so that the
+	// reports written before the interruption are not deleted as old ones
+	RunUID string `json:"runUID"`
+	// ClusterWideCompleted is true once all the cluster wide resources have been audited
+	ClusterWideCompleted bool `json:"clusterWideCompleted"`
+	// CompletedNamespaces are the namespaces whose resources have all been audited
+	CompletedNamespaces []string `json:"completedNamespaces"`
+	// CompletedGVRs are the resource types audited completely in the namespaces
+	// still in progress, keyed by namespace. The key of the cluster wide
+	// resources is the empty string
+	CompletedGVRs map[string][]string `json:"completedGVRs,omitempty"`
+}
+
+// LoadCheckpoint reads the checkpoint written by a previous scan to path.
+// It returns nil when the file doesn't exist, e.g. because the previous scan
+// completed.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil //nolint:nilnil // there's no scan to be resumed
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read checkpoint file: %w", err)
+	}
+
+	checkpoint := &Checkpoint{}
+	if err := json.Unmarshal(data, checkpoint); err != nil {
+		return nil, fmt.Errorf("cannot parse checkpoint file %s: %w", path, err)
+	}
+	if checkpoint.RunUID == "" {
+		return nil, fmt.Errorf("checkpoint file %s has no runUID", path)
+	}
+
+	return checkpoint, nil
+}
+
+// checkpointer keeps the checkpoint of the scan in progress and writes it to
+// its file every time a resource type, a namespace or the cluster wide
+// resources are completed. A nil checkpointer records nothing.
+type checkpointer struct {
+	path       string
+	mutex      sync.Mutex
+	checkpoint Checkpoint
+}
+
+func (c *checkpointer) isClusterWideCompleted() bool {
+	if c == nil {
+		return false
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.checkpoint.ClusterWideCompleted
+}
+
+func (c *checkpointer) isNamespaceCompleted(namespace string) bool {
+	if c == nil {
+		return false
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return slices.Contains(c.checkpoint.CompletedNamespaces, namespace)
+}
+
+// isGVRCompleted tells whether the resources of the type have all been
+// audited in the namespace, or cluster wide when namespace is empty.
+func (c *checkpointer) isGVRCompleted(namespace string, gvr schema.GroupVersionResource) bool {
+	if c == nil {
+		return false
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return slices.Contains(c.checkpoint.CompletedGVRs[namespace], gvr.String())
+}
+
+func (c *checkpointer) completeGVR(namespace string, gvr schema.GroupVersionResource) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.checkpoint.CompletedGVRs == nil {
+		c.checkpoint.CompletedGVRs = map[string][]string{}
+	}
+	c.checkpoint.CompletedGVRs[namespace] = append(c.checkpoint.CompletedGVRs[namespace], gvr.String())
+	c.write()
+}
+
+func (c *checkpointer) completeNamespace(namespace string) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.checkpoint.CompletedNamespaces = append(c.checkpoint.CompletedNamespaces, namespace)
+	delete(c.checkpoint.CompletedGVRs, namespace)
+	c.write()
+}
+
+func (c *checkpointer) completeClusterWide() {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.checkpoint.ClusterWideCompleted = true
+	delete(c.checkpoint.CompletedGVRs, "")
+	c.write()
+}
+
+// write replaces the checkpoint file, the failures are only logged since they
+// don't affect the scan. It must be called with the mutex held.
+func (c *checkpointer) write() {
+	if err := c.writeFile(); err != nil {
+		log.Warn().Err(err).Str("checkpoint-file", c.path).Msg("cannot write the scan checkpoint")
+	}
+}
+
+func (c *checkpointer) writeFile() error {
+	data, err := json.Marshal(c.checkpoint)
+	if err != nil {
+		return fmt.Errorf("cannot serialize the checkpoint: %w", err)
+	}
+	// the file is renamed so that an interruption never leaves a partial checkpoint
+	tmpPath := filepath.Join(filepath.Dir(c.path), "."+filepath.Base(c.path)+".tmp")
+	if err := os.WriteFile(tmpPath, data, checkpointFilePermissions); err != nil {
+		return fmt.Errorf("cannot write the checkpoint: %w", err)
+	}
+	if err := os.Rename(tmpPath, c.path); err != nil {
+		return errors.Join(fmt.Errorf("cannot write the checkpoint: %w", err), os.Remove(tmpPath))
+	}
+
+	return nil
+}
+
+// StartCheckpoint makes the scanner record the progress of the next scan in
+// the checkpoint file given with Config.CheckpointFile, starting from the
+// given checkpoint: the namespaces, the resource types and the cluster wide
+// resources it records as completed are skipped. It does nothing when the
+// checkpoint file isn't set.
+func (s *Scanner) StartCheckpoint(checkpoint Checkpoint) {
+	if s.checkpointFile == "" {
+		return
+	}
+	s.checkpoint = &checkpointer{path: s.checkpointFile, checkpoint: checkpoint}
+}
+
+// FinishCheckpoint removes the checkpoint file once the scan has completed,
+// so that the next scan starts from scratch.
+func (s *Scanner) FinishCheckpoint() error {
+	if s.checkpoint == nil {
+		return nil
+	}
+	s.checkpoint = nil
+	if err := os.Remove(s.checkpointFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("cannot remove the checkpoint file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kubewarden/audit-scanner/internal/k8s"
+	"github.com/kubewarden/audit-scanner/internal/policies"
+	"github.com/kubewarden/audit-scanner/internal/report"
+	auditscheme "github.com/kubewarden/audit-scanner/internal/scheme"
+	"github.com/kubewarden/audit-scanner/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apimachineryErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicFake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	wgpolicy "sigs.k8s.io/wg-policy-prototypes/policy-report/pkg/api/wgpolicyk8s.io/v1alpha2"
+)
+
+func TestLoadCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+
+	checkpoint, err := LoadCheckpoint(filepath.Join(dir, "missing.json"))
+	require.NoError(t, err)
+	assert.Nil(t, checkpoint)
+
+	malformed := filepath.Join(dir, "malformed.json")
+	require.NoError(t, os.WriteFile(malformed, []byte("{"), 0o600))
+	_, err = LoadCheckpoint(malformed)
+	require.Error(t, err)
+
+	withoutRunUID := filepath.Join(dir, "without-run-uid.json")
+	require.NoError(t, os.WriteFile(withoutRunUID, []byte(`{"completedNamespaces":["default"]}`), 0o600))
+	_, err = LoadCheckpoint(withoutRunUID)
+	require.Error(t, err)
+
+	path := filepath.Join(dir, "checkpoint.json")
+	podsGVR := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+	checkpointer := &checkpointer{path: path, checkpoint: Checkpoint{RunUID: "run-uid"}}
+	checkpointer.completeGVR("default", podsGVR)
+	checkpointer.completeGVR("", podsGVR)
+	checkpointer.completeClusterWide()
+
+	checkpoint, err = LoadCheckpoint(path)
+	require.NoError(t, err)
+	assert.Equal(t, &Checkpoint{
+		RunUID:               "run-uid",
+		ClusterWideCompleted: true,
+		CompletedGVRs:        map[string][]string{"default": {podsGVR.String()}},
+	}, checkpoint)
+
+	checkpointer.completeNamespace("default")
+	checkpoint, err = LoadCheckpoint(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"default"}, checkpoint.CompletedNamespaces)
+	assert.Empty(t, checkpoint.CompletedGVRs)
+}
+
+func TestScanAllNamespacesResumesFromCheckpoint(t *testing.T) {
+	mockPolicyServer := newMockPolicyServer()
+	defer mockPolicyServer.Close()
+
+	policyServer, policyServerService := newDefaultPolicyServer()
+
+	scannedNamespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "scanned"}}
+	pendingNamespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "pending"}}
+	scannedPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "scanned", UID: "scanned-pod-uid"}}
+	pendingPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "pending", UID: "pending-pod-uid"}}
+
+	clusterAdmissionPolicy := testutils.
+		NewClusterAdmissionPolicyFactory().
+		Name("clusterAdmissionPolicy").
+		Rule(admissionregistrationv1.Rule{
+			APIGroups:   []string{""},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"pods"},
+		}).
+		Build()
+
+	auditScheme, err := auditscheme.NewScheme()
+	require.NoError(t, err)
+	dynamicClient := dynamicFake.NewSimpleDynamicClient(auditScheme, scannedPod, pendingPod)
+	clientset := fake.NewSimpleClientset(scannedNamespace, pendingNamespace)
+	client, err := testutils.NewFakeClient(
+		scannedNamespace,
+		pendingNamespace,
+		policyServer,
+		policyServerService,
+		clusterAdmissionPolicy,
+	)
+	require.NoError(t, err)
+
+	k8sClient, err := k8s.NewClient(dynamicClient, clientset, "kubewarden", nil, pageSize)
+	require.NoError(t, err)
+
+	policiesClient, err := policies.NewClient(client, "kubewarden", mockPolicyServer.URL)
+	require.NoError(t, err)
+
+	checkpointFile := filepath.Join(t.TempDir(), "checkpoint.json")
+	config := newTestConfig(policiesClient, k8sClient, report.NewPolicyReportStore(client))
+	config.CheckpointFile = checkpointFile
+	scanner, err := NewScanner(config)
+	require.NoError(t, err)
+
+	// the scan was interrupted after scanning a namespace
+	scanner.StartCheckpoint(Checkpoint{RunUID: "run-uid", CompletedNamespaces: []string{"scanned"}})
+	err = scanner.ScanAllNamespaces(context.Background(), "run-uid")
+	require.NoError(t, err)
+
+	policyReport := wgpolicy.PolicyReport{}
+	err = client.Get(context.TODO(), types.NamespacedName{Name: string(pendingPod.GetUID()), Namespace: "pending"}, &policyReport)
+	require.NoError(t, err)
+	err = client.Get(context.TODO(), types.NamespacedName{Name: string(scannedPod.GetUID()), Namespace: "scanned"}, &policyReport)
+	require.True(t, apimachineryErrors.IsNotFound(err))
+
+	checkpoint, err := LoadCheckpoint(checkpointFile)
+	require.NoError(t, err)
+	assert.Equal(t, "run-uid", checkpoint.RunUID)
+	assert.ElementsMatch(t, []string{"scanned", "pending"}, checkpoint.CompletedNamespaces)
+
+	// the next scan starts from scratch
+	require.NoError(t, scanner.FinishCheckpoint())
+	_, err = os.Stat(checkpointFile)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -1,9 +1,19 @@
 package scanner
 
 import (
+	"regexp"
+	"time"
+
 	"github.com/kubewarden/audit-scanner/internal/k8s"
+	"github.com/kubewarden/audit-scanner/internal/metrics"
 	"github.com/kubewarden/audit-scanner/internal/policies"
 	"github.com/kubewarden/audit-scanner/internal/report"
+	admv1 "k8s.io/api/admission/v1"
+	admv1beta1 "k8s.io/api/admission/v1beta1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 type ParallelizationConfig struct {
@@ -17,15 +27,166 @@ type TLSConfig struct {
 	CAFile         string
 	ClientCertFile string
 	ClientKeyFile  string
+	// CADir is a directory whose .crt and .pem files are added to the trusted CAs
+	CADir string
+}
+
+type PolicyServerConfig struct {
+	// Timeout is the maximum duration of a single request to a Policy Server
+	Timeout time.Duration
+	// MaxRetries is the number of times a request failed because of a transient error is retried
+	MaxRetries int
+	// RetryBackoff is the initial time to wait before retrying a failed request,
+	// the requests are retried without waiting when it is not greater than 0
+	RetryBackoff time.Duration
+	// Token is sent as bearer token in the Authorization header of every request
+	Token string
+	// TokenFile is the path of a file containing the bearer token, it's read
+	// again when it changes. It cannot be used together with Token
+	TokenFile string
+	// KeepAlive enables reusing the connections to the Policy Servers. It's
+	// disabled by default, to spread the requests across the Policy Server replicas
+	KeepAlive bool
+	// MaxIdleConns is the maximum number of idle connections kept across all
+	// the Policy Servers, the default of net/http is used when it's 0
+	MaxIdleConns int
+	// MaxIdleConnsPerHost is the maximum number of idle connections kept for
+	// every Policy Server, the default of net/http is used when it's 0
+	MaxIdleConnsPerHost int
+	// IdleConnTimeout is the time an idle connection is kept before being
+	// closed, the default of net/http is used when it's 0
+	IdleConnTimeout time.Duration
+	// QPS is the maximum number of requests per second sent to the Policy
+	// Servers, shared by all the audits. Requests aren't rate limited when it's 0
+	QPS float64
+	// Burst is the maximum number of requests sent at once when QPS is set
+	Burst int
+	// UserAgent is sent in the User-Agent header of every request, the default
+	// one of net/http is used when it's empty
+	UserAgent string
+	// MaxResourceBytes is the maximum size of the AdmissionReview of a
+	// resource, the bigger ones aren't sent and their results are errored.
+	// The size isn't limited when it's 0
+	MaxResourceBytes int
+	// CompressRequests makes the AdmissionReviews bigger than a few KiB be sent
+	// gzipped, it requires Policy Servers accepting compressed requests
+	CompressRequests bool
+}
+
+// AdmissionConfig tells how the AdmissionRequests auditing the resources are
+// framed, so that the policies evaluate them as they would at admission time.
+type AdmissionConfig struct {
+	// Operation of the AdmissionRequests, CREATE when it's empty. The policies
+	// are evaluated only against the resources they have rules for with this
+	// operation. With UPDATE, the old object is the resource itself, as if it
+	// was updated without changes
+	Operation admv1.Operation
+	// UserInfo is the user sending the AdmissionRequests, evaluated by the
+	// policies making decisions on the requesting user. It's empty when unset
+	UserInfo authenticationv1.UserInfo
+	// APIVersion of the AdmissionReviews sent to the Policy Servers, and
+	// expected back from them. admission.k8s.io/v1 when it's empty
+	APIVersion string
+}
+
+// SupportedAdmissionAPIVersions returns the API versions the AdmissionReviews
+// can be sent with.
+func SupportedAdmissionAPIVersions() []string {
+	return []string{admv1.SchemeGroupVersion.String(), admv1beta1.SchemeGroupVersion.String()}
+}
+
+// SupportedAdmissionOperations returns the operations the resources can be
+// audited with.
+func SupportedAdmissionOperations() []admv1.Operation {
+	return []admv1.Operation{admv1.Create, admv1.Update}
 }
 
 type Config struct {
 	PoliciesClient    *policies.Client
 	K8sClient         *k8s.Client
 	PolicyReportStore *report.PolicyReportStore
+	// Metrics is optional, no metrics are recorded when it's nil
+	Metrics *metrics.Metrics
 
 	TLS             TLSConfig
 	Parallelization ParallelizationConfig
+	PolicyServer    PolicyServerConfig
+	Admission       AdmissionConfig
+
+	// NamespaceSelector restricts the namespaces scanned by ScanAllNamespaces.
+	// All the audited namespaces are scanned when it's nil.
+	NamespaceSelector labels.Selector
+	// SkipNamespaceRegexes are matched against the names of the namespaces,
+	// ScanAllNamespaces skips the namespaces matching any of them.
+	SkipNamespaceRegexes []*regexp.Regexp
+	// ResourceSelector restricts the resources fetched and audited, both in
+	// namespaces and cluster-wide. The reports of previous scans are kept,
+	// since the ones of the resources not selected are still current. All the
+	// resources are audited when it's nil.
+	ResourceSelector labels.Selector
+	// FieldSelector restricts the resources fetched and audited by their fields,
+	// e.g. status.phase=Running. The reports of previous scans are kept, like
+	// with ResourceSelector. All the resources are audited when it's nil.
+	FieldSelector fields.Selector
+	// IncludedKinds and ExcludedKinds are the kinds PoliciesClient restricts
+	// the audited resources to, or leaves out. The reports of previous scans
+	// are kept when any of them is set, since the ones of the resources of the
+	// kinds not audited are still current.
+	IncludedKinds []schema.GroupKind
+	ExcludedKinds []schema.GroupKind
+	// PolicyNames are the names of the policies PoliciesClient restricts the
+	// evaluation to. The reports of previous scans are kept when it's set,
+	// since the ones of the resources matched only by the other policies are
+	// still current.
+	PolicyNames []string
+	// SeverityFilter drops the results of the policies below a severity.
+	// All the results are reported when it's nil.
+	SeverityFilter *report.SeverityFilter
+	// Redactions remove fields from the resources before they are sent to the
+	// Policy Servers. The resources are sent as a whole when it's empty.
+	Redactions []Redaction
+	// SkipOwnedResources skips the resources controlled by another one, e.g.
+	// the Pods of a ReplicaSet, so that only the top-level objects are audited.
+	// The reports of previous scans are kept, since the ones of the resources
+	// skipped are still current
+	SkipOwnedResources bool
+	// SkipOwnerKinds restricts SkipOwnedResources to the resources whose
+	// controller is of one of these kinds. All the controllers are considered
+	// when it's empty.
+	SkipOwnerKinds []string
+	// SampleSize makes the scans audit only the first SampleSize resources of
+	// every type, in every namespace, for a quick spot check. The reports of
+	// previous scans are kept, since the ones of the resources not sampled
+	// are still current. All the resources are audited when it's 0
+	SampleSize int
+
+	// RecordTimings adds to every result the round-trip time of the request to
+	// the Policy Server evaluating the policy. The results of the cached
+	// verdicts and of the requests not sent have no timing
+	RecordTimings bool
+	// KeepOldReports disables the deletion of the reports written by previous
+	// scans, e.g. the reports of resources no longer matched by any policy
+	KeepOldReports bool
+	// PruneStaleReports makes ScanAllNamespaces delete the PolicyReports created
+	// by the audit scanner in the namespaces that no longer exist or are being
+	// deleted. The reports of the namespaces excluded from the scan are kept
+	PruneStaleReports bool
+	// MirrorNamespace is the namespace where a PolicyReport with a copy of the
+	// results of every ClusterPolicyReport is written too, for the users who
+	// can read only the namespaced reports. The copies aren't counted in the
+	// summary of the scan. Nothing is copied when it's empty
+	MirrorNamespace string
+	// VerdictCacheTTL is how long the verdicts of the policies are cached,
+	// keyed by the content of the resources, so that the resources with the
+	// same content aren't evaluated again by the Policy Servers. A verdict is
+	// dropped when the policy changes. The verdicts aren't cached when it's 0
+	VerdictCacheTTL time.Duration
+	// CollectErrors makes the Scanner keep the non-fatal errors hit during the
+	// scan, besides logging them. They are returned by Scanner.Errors
+	CollectErrors bool
+	// CheckpointFile is the file where the progress of the scans started with
+	// Scanner.StartCheckpoint is recorded, to resume them when interrupted
+	CheckpointFile string
 
 	OutputScan   bool
 	DisableStore bool
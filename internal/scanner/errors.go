@@ -0,0 +1,105 @@
+package scanner
+
+import (
+	"slices"
+	"sync"
+
+	policiesv1 "github.com/kubewarden/kubewarden-controller/api/policies/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ScanErrorKind tells which step of the scan a ScanError comes from.
+type ScanErrorKind string
+
+const (
+	// ScanErrorPolicies is an error fetching the policies to be evaluated.
+	ScanErrorPolicies ScanErrorKind = "policies"
+	// ScanErrorListResources is an error listing the resources to be audited.
+	ScanErrorListResources ScanErrorKind = "list-resources"
+	// ScanErrorPolicyMatch is an error checking whether a policy applies to a resource.
+	ScanErrorPolicyMatch ScanErrorKind = "policy-match"
+	// ScanErrorPolicyServer is an error sending a resource to a Policy Server,
+	// or the Policy Server failing to evaluate the policy.
+	ScanErrorPolicyServer ScanErrorKind = "policy-server"
+	// ScanErrorResourceTooLarge is a resource not sent to the Policy Server
+	// because its AdmissionReview is bigger than the configured maximum.
+	ScanErrorResourceTooLarge ScanErrorKind = "resource-too-large"
+	// ScanErrorAuditResource is an error auditing a resource.
+	ScanErrorAuditResource ScanErrorKind = "audit-resource"
+	// ScanErrorSaveReport is an error saving a report in the cluster.
+	ScanErrorSaveReport ScanErrorKind = "save-report"
+	// ScanErrorDeleteReports is an error deleting the reports of previous scans.
+	ScanErrorDeleteReports ScanErrorKind = "delete-reports"
+)
+
+// ScanError is a non-fatal error hit during a scan. The scan logs it and goes
+// on, the error is collected only when Config.CollectErrors is set.
+type ScanError struct {
+	Kind ScanErrorKind `json:"kind"`
+	// Namespace is empty for cluster wide resources and reports
+	Namespace string `json:"namespace,omitempty"`
+	GVR       string `json:"gvr,omitempty"`
+	Resource  string `json:"resource,omitempty"`
+	Policy    string `json:"policy,omitempty"`
+	Message   string `json:"message"`
+}
+
+// newResourceScanError returns a ScanError hit while evaluating a policy
+// against a resource.
+func newResourceScanError(kind ScanErrorKind, gvr schema.GroupVersionResource, resource unstructured.Unstructured, policy policiesv1.Policy, message string) ScanError {
+	return ScanError{
+		Kind:      kind,
+		Namespace: resource.GetNamespace(),
+		GVR:       gvr.String(),
+		Resource:  resource.GetName(),
+		Policy:    policy.GetUniqueName(),
+		Message:   message,
+	}
+}
+
+// errorCollector gathers the ScanErrors of a scan. It's safe for concurrent
+// use, a nil errorCollector discards them.
+type errorCollector struct {
+	mutex  sync.Mutex
+	errors []ScanError
+}
+
+func (c *errorCollector) add(scanError ScanError) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.errors = append(c.errors, scanError)
+}
+
+func (c *errorCollector) list() []ScanError {
+	if c == nil {
+		return nil
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return slices.Clone(c.errors)
+}
+
+func (c *errorCollector) reset() {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.errors = nil
+}
+
+// Errors returns the non-fatal errors hit since the Scanner has been created
+// or ResetErrors has been called, in the order they happened.
+// It's always empty when Config.CollectErrors is not set.
+func (s *Scanner) Errors() []ScanError {
+	return s.errors.list()
+}
+
+// ResetErrors drops the collected errors, e.g. before starting a new scan.
+func (s *Scanner) ResetErrors() {
+	s.errors.reset()
+}
@@ -0,0 +1,236 @@
+package scanner
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/kubewarden/audit-scanner/internal/tracing"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	admissionv1 "k8s.io/api/admission/v1"
+)
+
+const (
+	// maxRetryBackoff caps the time waited between two attempts to reach the Policy Server.
+	maxRetryBackoff = 30 * time.Second
+	// maxErrorBodyLength is the maximum number of bytes of the body of an
+	// unexpected response included in the errors, and hence in the reports
+	maxErrorBodyLength = 512
+	// compressionThreshold is the minimum size of the AdmissionReviews
+	// compressed, the smaller ones are sent as they are since compressing
+	// them would save little and cost CPU on both sides
+	compressionThreshold = 4096
+	contentEncodingGzip  = "gzip"
+)
+
+// statusCodeError is returned when the Policy Server answers with a status code other than 200.
+type statusCodeError struct {
+	statusCode int
+	body       []byte
+}
+
+func (e *statusCodeError) Error() string {
+	body := e.body
+	if len(body) > maxErrorBodyLength {
+		body = append(body[:maxErrorBodyLength:maxErrorBodyLength], "..."...)
+	}
+	return fmt.Sprintf("unexpected status code: %d body: %s", e.statusCode, body)
+}
+
+// sendAdmissionReviewToPolicyServer sends the AdmissionReview to the Policy Server.
+// Transient failures are retried up to policyServerMaxRetries times, waiting an
+// exponentially growing and jittered backoff between the attempts.
+// Each attempt is bound by policyServerTimeout and, when the requests are rate
+// limited, waits for the limiter before being sent.
+// The payload is the serialized AdmissionReview, shared by all the policies evaluating the same resource.
+// It returns the round-trip time of the last request sent too, which leaves
+// out the backoffs and the waits for the limiter, 0 when no request was sent.
+func (s *Scanner) sendAdmissionReviewToPolicyServer(ctx context.Context, url *url.URL, payload []byte) (*admissionv1.AdmissionReview, time.Duration, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "sendAdmissionReviewToPolicyServer", trace.WithAttributes(
+		attribute.String("policy-server.url", url.String()),
+	))
+	defer span.End()
+
+	start := time.Now()
+	defer func() {
+		s.metrics.ObservePolicyServerRequest(time.Since(start))
+	}()
+
+	// the payloads are dumped only at trace level, they contain the whole
+	// resources, including the sensitive data of e.g. Secrets
+	log.Trace().Str("url", url.String()).RawJSON("admission-review", payload).Msg("sending AdmissionReview to PolicyServer")
+
+	requestBody, contentEncoding, err := s.encodeRequestBody(payload)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "request to PolicyServer not sent")
+		return nil, 0, err
+	}
+
+	var roundTrip time.Duration
+	for attempt := 0; ; attempt++ {
+		if s.policyServerLimiter != nil {
+			if err := s.policyServerLimiter.Wait(ctx); err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, "request to PolicyServer not sent")
+				return nil, roundTrip, fmt.Errorf("waiting for the PolicyServer rate limiter: %w", err)
+			}
+		}
+		admissionReview, rt, err := s.doSendAdmissionReview(ctx, url, requestBody, contentEncoding)
+		roundTrip = rt
+		if err == nil {
+			span.SetAttributes(attribute.Int("attempts", attempt+1))
+			return admissionReview, roundTrip, nil
+		}
+		if attempt >= s.policyServerMaxRetries || !isRetryable(ctx, err) {
+			span.SetAttributes(attribute.Int("attempts", attempt+1))
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "request to PolicyServer failed")
+			return nil, roundTrip, err
+		}
+
+		backoff := retryBackoff(s.policyServerRetryBackoff, attempt)
+		log.Debug().Err(err).Dict("dict", zerolog.Dict().
+			Str("url", url.String()).
+			Int("attempt", attempt+1).
+			Dur("backoff", backoff),
+		).Msg("retrying request to PolicyServer")
+
+		select {
+		case <-ctx.Done():
+			return nil, roundTrip, errors.Join(err, ctx.Err())
+		case <-time.After(backoff):
+		}
+	}
+}
+
+// encodeRequestBody returns the body of the requests sending the payload and
+// its content encoding. When the requests are compressed, the payloads bigger
+// than compressionThreshold are gzipped, the others are sent as they are.
+func (s *Scanner) encodeRequestBody(payload []byte) ([]byte, string, error) {
+	if !s.compressRequests || len(payload) < compressionThreshold {
+		return payload, "", nil
+	}
+
+	var body bytes.Buffer
+	writer := gzip.NewWriter(&body)
+	if _, err := writer.Write(payload); err != nil {
+		return nil, "", fmt.Errorf("cannot compress the AdmissionReview: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", fmt.Errorf("cannot compress the AdmissionReview: %w", err)
+	}
+
+	return body.Bytes(), contentEncodingGzip, nil
+}
+
+// doSendAdmissionReview performs a single request against the Policy Server.
+// The requestBody is encoded with contentEncoding, it's sent as it is when empty.
+// It returns the round-trip time of the request too, from the time it's sent
+// to the time the whole response is received, 0 when it isn't sent.
+func (s *Scanner) doSendAdmissionReview(ctx context.Context, url *url.URL, requestBody []byte, contentEncoding string) (*admissionv1.AdmissionReview, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.policyServerTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url.String(), bytes.NewBuffer(requestBody))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Add("Content-Type", "application/json")
+	if contentEncoding != "" {
+		req.Header.Set("Content-Encoding", contentEncoding)
+	}
+	if s.policyServerUserAgent != "" {
+		req.Header.Set("User-Agent", s.policyServerUserAgent)
+	}
+	if s.policyServerToken != nil {
+		token, err := s.policyServerToken.Token()
+		if err != nil {
+			return nil, 0, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	start := time.Now()
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, time.Since(start), err
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	roundTrip := time.Since(start)
+	if err != nil {
+		return nil, roundTrip, fmt.Errorf("cannot read body of response: %w", err)
+	}
+	if event := log.Trace(); event.Enabled() {
+		event = event.Str("url", url.String()).Int("status-code", res.StatusCode)
+		// the body of the errors may not be JSON
+		if json.Valid(body) {
+			event = event.RawJSON("response", body)
+		} else {
+			event = event.Bytes("response", body)
+		}
+		event.Msg("received response from PolicyServer")
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, roundTrip, &statusCodeError{statusCode: res.StatusCode, body: body}
+	}
+
+	admissionReview, err := decodeAdmissionReview(body, s.admission.APIVersion)
+	if err != nil {
+		return nil, roundTrip, fmt.Errorf("cannot deserialize the audit review response: %w", err)
+	}
+	// the outcome of the evaluation is in the response, the callers rely on it
+	if admissionReview.Response == nil {
+		return nil, roundTrip, errors.New("the audit review response has no AdmissionResponse")
+	}
+	return admissionReview, roundTrip, nil
+}
+
+// isRetryable returns true when the request failed because of a transient error:
+// a network error, a server error or a throttled request.
+// Client errors and failures caused by the cancellation of the scan are not retried.
+func isRetryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var statusErr *statusCodeError
+	if errors.As(err, &statusErr) {
+		return statusErr.statusCode == http.StatusTooManyRequests ||
+			statusErr.statusCode >= http.StatusInternalServerError
+	}
+
+	// errors returned by http.Client.Do, including the timeout of a single attempt
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// retryBackoff returns the time to wait before the next attempt.
+// The backoff doubles at every attempt and is jittered to avoid retrying all
+// the failed requests at the same time. A base not greater than 0 retries
+// without waiting.
+func retryBackoff(base time.Duration, attempt int) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+	backoff := base << attempt
+	if backoff <= 0 || backoff > maxRetryBackoff {
+		backoff = maxRetryBackoff
+	}
+
+	//nolint:gosec // the jitter doesn't need a cryptographically secure random number
+	return backoff/2 + rand.N(backoff/2+1)
+}
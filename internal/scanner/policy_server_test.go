@@ -0,0 +1,395 @@
+package scanner
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestSendAdmissionReviewToPolicyServerTimeout(t *testing.T) {
+	mockPolicyServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+			writer.WriteHeader(http.StatusOK)
+		case <-r.Context().Done():
+		}
+	}))
+	defer mockPolicyServer.Close()
+
+	config := newTestConfig(nil, nil, nil)
+	config.PolicyServer.Timeout = 50 * time.Millisecond
+	scanner, err := NewScanner(config)
+	require.NoError(t, err)
+
+	policyServerURL, err := url.Parse(mockPolicyServer.URL)
+	require.NoError(t, err)
+
+	_, _, err = scanner.sendAdmissionReviewToPolicyServer(context.Background(), policyServerURL, newTestAdmissionReviewPayload(t))
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestSendAdmissionReviewToPolicyServerRoundTrip(t *testing.T) {
+	var attempts atomic.Int32
+	mockPolicyServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		if attempts.Add(1) == 1 {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		response, err := json.Marshal(admissionv1.AdmissionReview{
+			Response: &admissionv1.AdmissionResponse{Allowed: true},
+		})
+		if err != nil {
+			writer.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = writer.Write(response)
+	}))
+	defer mockPolicyServer.Close()
+
+	config := newTestConfig(nil, nil, nil)
+	config.PolicyServer.MaxRetries = 1
+	config.PolicyServer.RetryBackoff = 500 * time.Millisecond
+	scanner, err := NewScanner(config)
+	require.NoError(t, err)
+
+	policyServerURL, err := url.Parse(mockPolicyServer.URL)
+	require.NoError(t, err)
+
+	start := time.Now()
+	_, roundTrip, err := scanner.sendAdmissionReviewToPolicyServer(context.Background(), policyServerURL, newTestAdmissionReviewPayload(t))
+	require.NoError(t, err)
+	require.Equal(t, int32(2), attempts.Load())
+	// the round-trip time is the one of the last request, without the backoff
+	// waited after the first one
+	assert.Positive(t, roundTrip)
+	assert.Less(t, roundTrip, time.Since(start)/2)
+}
+
+func TestSendAdmissionReviewToPolicyServerRetries(t *testing.T) {
+	tests := []struct {
+		name             string
+		failingAttempts  int32
+		failureCode      int
+		maxRetries       int
+		expectedAttempts int32
+		expectError      bool
+	}{
+		{"service unavailable is retried", 2, http.StatusServiceUnavailable, 3, 3, false},
+		{"too many requests is retried", 1, http.StatusTooManyRequests, 3, 2, false},
+		{"retries are exhausted", 5, http.StatusInternalServerError, 2, 3, true},
+		{"client errors are not retried", 1, http.StatusBadRequest, 3, 1, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			mockPolicyServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+				if attempts.Add(1) <= test.failingAttempts {
+					writer.WriteHeader(test.failureCode)
+					return
+				}
+
+				response, err := json.Marshal(admissionv1.AdmissionReview{
+					Response: &admissionv1.AdmissionResponse{Allowed: true},
+				})
+				if err != nil {
+					writer.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				_, _ = writer.Write(response)
+			}))
+			defer mockPolicyServer.Close()
+
+			config := newTestConfig(nil, nil, nil)
+			config.PolicyServer.MaxRetries = test.maxRetries
+			config.PolicyServer.RetryBackoff = time.Millisecond
+			scanner, err := NewScanner(config)
+			require.NoError(t, err)
+
+			policyServerURL, err := url.Parse(mockPolicyServer.URL)
+			require.NoError(t, err)
+
+			admissionReview, _, err := scanner.sendAdmissionReviewToPolicyServer(context.Background(), policyServerURL, newTestAdmissionReviewPayload(t))
+			if test.expectError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.True(t, admissionReview.Response.Allowed)
+			}
+			assert.Equal(t, test.expectedAttempts, attempts.Load())
+		})
+	}
+}
+
+func TestSendAdmissionReviewToPolicyServerRateLimit(t *testing.T) {
+	var requests atomic.Int32
+	mockPolicyServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		response, err := json.Marshal(admissionv1.AdmissionReview{
+			Response: &admissionv1.AdmissionResponse{Allowed: true},
+		})
+		if err != nil {
+			writer.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = writer.Write(response)
+	}))
+	defer mockPolicyServer.Close()
+
+	config := newTestConfig(nil, nil, nil)
+	config.PolicyServer.QPS = 0.1
+	config.PolicyServer.Burst = 1
+	scanner, err := NewScanner(config)
+	require.NoError(t, err)
+
+	policyServerURL, err := url.Parse(mockPolicyServer.URL)
+	require.NoError(t, err)
+
+	_, _, err = scanner.sendAdmissionReviewToPolicyServer(context.Background(), policyServerURL, newTestAdmissionReviewPayload(t))
+	require.NoError(t, err)
+
+	// the burst has been consumed, the next request waits for the limiter
+	// until its context is cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	_, _, err = scanner.sendAdmissionReviewToPolicyServer(ctx, policyServerURL, newTestAdmissionReviewPayload(t))
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestSendAdmissionReviewToPolicyServerUserAgent(t *testing.T) {
+	var userAgent atomic.Value
+	mockPolicyServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, r *http.Request) {
+		userAgent.Store(r.UserAgent())
+		response, err := json.Marshal(admissionv1.AdmissionReview{
+			Response: &admissionv1.AdmissionResponse{Allowed: true},
+		})
+		if err != nil {
+			writer.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = writer.Write(response)
+	}))
+	defer mockPolicyServer.Close()
+
+	config := newTestConfig(nil, nil, nil)
+	config.PolicyServer.UserAgent = "kubewarden-audit-scanner/v1.2.3"
+	scanner, err := NewScanner(config)
+	require.NoError(t, err)
+
+	policyServerURL, err := url.Parse(mockPolicyServer.URL)
+	require.NoError(t, err)
+
+	_, _, err = scanner.sendAdmissionReviewToPolicyServer(context.Background(), policyServerURL, newTestAdmissionReviewPayload(t))
+	require.NoError(t, err)
+	assert.Equal(t, "kubewarden-audit-scanner/v1.2.3", userAgent.Load())
+}
+
+func TestRetryBackoff(t *testing.T) {
+	for attempt := range 5 {
+		backoff := retryBackoff(100*time.Millisecond, attempt)
+		upperBound := 100 * time.Millisecond << attempt
+
+		assert.GreaterOrEqual(t, backoff, upperBound/2)
+		assert.LessOrEqual(t, backoff, upperBound)
+	}
+
+	assert.LessOrEqual(t, retryBackoff(time.Second, 62), maxRetryBackoff)
+
+	// no backoff retries immediately, whatever the attempt
+	for attempt := range 5 {
+		assert.Zero(t, retryBackoff(0, attempt))
+	}
+	assert.Zero(t, retryBackoff(-time.Second, 1))
+}
+
+func TestSendAdmissionReviewToPolicyServerBearerToken(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("first-token\n"), 0o600))
+
+	tests := []struct {
+		name          string
+		policyServer  PolicyServerConfig
+		rotate        func(t *testing.T)
+		expectedAuths []string
+	}{
+		{
+			name:          "no token",
+			expectedAuths: []string{"", ""},
+		},
+		{
+			name:          "static token",
+			policyServer:  PolicyServerConfig{Token: "static-token"},
+			expectedAuths: []string{"Bearer static-token", "Bearer static-token"},
+		},
+		{
+			name:         "rotated token file",
+			policyServer: PolicyServerConfig{TokenFile: tokenFile},
+			rotate: func(t *testing.T) {
+				require.NoError(t, os.WriteFile(tokenFile, []byte("second-token-rotated\n"), 0o600))
+				// make sure the modification time changes, even on coarse grained filesystems
+				later := time.Now().Add(time.Minute)
+				require.NoError(t, os.Chtimes(tokenFile, later, later))
+			},
+			expectedAuths: []string{"Bearer first-token", "Bearer second-token-rotated"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var auths []string
+			mockPolicyServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, r *http.Request) {
+				auths = append(auths, r.Header.Get("Authorization"))
+				response, err := json.Marshal(admissionv1.AdmissionReview{
+					Response: &admissionv1.AdmissionResponse{Allowed: true},
+				})
+				if err != nil {
+					writer.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				_, _ = writer.Write(response)
+			}))
+			defer mockPolicyServer.Close()
+
+			config := newTestConfig(nil, nil, nil)
+			config.PolicyServer = test.policyServer
+			scanner, err := NewScanner(config)
+			require.NoError(t, err)
+
+			policyServerURL, err := url.Parse(mockPolicyServer.URL)
+			require.NoError(t, err)
+
+			_, _, err = scanner.sendAdmissionReviewToPolicyServer(context.Background(), policyServerURL, newTestAdmissionReviewPayload(t))
+			require.NoError(t, err)
+			if test.rotate != nil {
+				test.rotate(t)
+			}
+			_, _, err = scanner.sendAdmissionReviewToPolicyServer(context.Background(), policyServerURL, newTestAdmissionReviewPayload(t))
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedAuths, auths)
+		})
+	}
+}
+
+func TestNewScannerTokenValidation(t *testing.T) {
+	config := newTestConfig(nil, nil, nil)
+	config.PolicyServer = PolicyServerConfig{Token: "token", TokenFile: "/path/to/token"}
+	_, err := NewScanner(config)
+	require.Error(t, err)
+
+	config.PolicyServer = PolicyServerConfig{TokenFile: filepath.Join(t.TempDir(), "missing")}
+	_, err = NewScanner(config)
+	require.Error(t, err)
+
+	config.PolicyServer = PolicyServerConfig{QPS: 10}
+	_, err = NewScanner(config)
+	require.Error(t, err)
+}
+
+func newTestAdmissionReviewPayload(t *testing.T) []byte {
+	t.Helper()
+
+	payload, err := newAdmissionReviewPayload(unstructured.Unstructured{}, AdmissionConfig{}, nil)()
+	require.NoError(t, err)
+
+	return payload
+}
+
+func TestNewScannerConnectionPool(t *testing.T) {
+	config := newTestConfig(nil, nil, nil)
+	config.PolicyServer.KeepAlive = true
+	config.PolicyServer.MaxIdleConns = 200
+	config.PolicyServer.MaxIdleConnsPerHost = 50
+	config.PolicyServer.IdleConnTimeout = 2 * time.Minute
+
+	scanner, err := NewScanner(config)
+	require.NoError(t, err)
+
+	transport, ok := scanner.httpClient.Transport.(*http.Transport)
+	require.True(t, ok)
+	assert.False(t, transport.DisableKeepAlives)
+	assert.Equal(t, 200, transport.MaxIdleConns)
+	assert.Equal(t, 50, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, 2*time.Minute, transport.IdleConnTimeout)
+
+	// keep-alives are disabled by default, without changing the transport shared by the process
+	scanner, err = NewScanner(newTestConfig(nil, nil, nil))
+	require.NoError(t, err)
+	transport, ok = scanner.httpClient.Transport.(*http.Transport)
+	require.True(t, ok)
+	assert.True(t, transport.DisableKeepAlives)
+
+	defaultTransport, ok := http.DefaultTransport.(*http.Transport)
+	require.True(t, ok)
+	assert.False(t, defaultTransport.DisableKeepAlives)
+	assert.NotSame(t, defaultTransport, transport)
+}
+
+func TestSendAdmissionReviewToPolicyServerCompressed(t *testing.T) {
+	var contentEncodings []string
+	var admissionReviews []admissionv1.AdmissionReview
+	mockPolicyServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, r *http.Request) {
+		contentEncodings = append(contentEncodings, r.Header.Get("Content-Encoding"))
+		body := io.Reader(r.Body)
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			gzipReader, err := gzip.NewReader(r.Body)
+			if !assert.NoError(t, err) {
+				writer.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			body = gzipReader
+		}
+		admissionReview := admissionv1.AdmissionReview{}
+		if !assert.NoError(t, json.NewDecoder(body).Decode(&admissionReview)) {
+			writer.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		admissionReviews = append(admissionReviews, admissionReview)
+
+		response, err := json.Marshal(admissionv1.AdmissionReview{Response: &admissionv1.AdmissionResponse{Allowed: true}})
+		if !assert.NoError(t, err) {
+			return
+		}
+		_, _ = writer.Write(response)
+	}))
+	defer mockPolicyServer.Close()
+	policyServerURL, err := url.Parse(mockPolicyServer.URL)
+	require.NoError(t, err)
+
+	config := newTestConfig(nil, nil, nil)
+	config.PolicyServer.CompressRequests = true
+	scanner, err := NewScanner(config)
+	require.NoError(t, err)
+
+	// the small payloads are not worth compressing
+	_, _, err = scanner.sendAdmissionReviewToPolicyServer(context.Background(), policyServerURL, newTestAdmissionReviewPayload(t))
+	require.NoError(t, err)
+
+	resource := unstructured.Unstructured{}
+	resource.SetName("configmap")
+	require.NoError(t, unstructured.SetNestedField(resource.Object, strings.Repeat("x", 2*compressionThreshold), "data", "key"))
+	payload, err := newAdmissionReviewPayload(resource, AdmissionConfig{}, nil)()
+	require.NoError(t, err)
+	_, _, err = scanner.sendAdmissionReviewToPolicyServer(context.Background(), policyServerURL, payload)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"", "gzip"}, contentEncodings)
+	require.Len(t, admissionReviews, 2)
+	assert.Equal(t, "configmap", admissionReviews[1].Request.Name)
+}
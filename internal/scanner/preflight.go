@@ -0,0 +1,32 @@
+package scanner
+
+import (
+	"context"
+
+	"github.com/kubewarden/audit-scanner/internal/policies"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// preflightResourceName is the name of the resource sent to the Policy Servers
+// to check they can be reached.
+const preflightResourceName = "audit-scanner-preflight"
+
+// CheckPolicyServer verifies that the Policy Server running the policy can be
+// reached, e.g. that the TLS and authentication settings are right, by asking
+// it to evaluate the policy against an empty resource of the given type.
+// The outcome of the evaluation doesn't matter, as long as the Policy Server
+// answers with a well-formed AdmissionReview. Nothing is recorded.
+func (s *Scanner) CheckPolicyServer(ctx context.Context, policy *policies.Policy, gvr schema.GroupVersionResource) error {
+	resource := unstructured.Unstructured{}
+	resource.SetAPIVersion(gvr.GroupVersion().String())
+	resource.SetName(preflightResourceName)
+
+	payload, err := newAdmissionReviewPayload(resource, s.admission, s.redactions)()
+	if err != nil {
+		return err
+	}
+	_, _, err = s.sendAdmissionReviewToPolicyServer(ctx, policy.PolicyServer, payload)
+
+	return err
+}
@@ -0,0 +1,61 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/kubewarden/audit-scanner/internal/policies"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestCheckPolicyServer(t *testing.T) {
+	tests := []struct {
+		name        string
+		statusCode  int
+		response    string
+		expectError bool
+	}{
+		{"rejected resource", http.StatusOK, `{"response":{"allowed":false}}`, false},
+		{"missing admission response", http.StatusOK, `{}`, true},
+		{"malformed response", http.StatusOK, `not json`, true},
+		{"unauthorized", http.StatusUnauthorized, ``, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var admissionReview admissionv1.AdmissionReview
+			mockPolicyServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&admissionReview); err != nil {
+					writer.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				writer.WriteHeader(test.statusCode)
+				_, _ = writer.Write([]byte(test.response))
+			}))
+			defer mockPolicyServer.Close()
+
+			scanner, err := NewScanner(newTestConfig(nil, nil, nil))
+			require.NoError(t, err)
+
+			policyServerURL, err := url.Parse(mockPolicyServer.URL)
+			require.NoError(t, err)
+
+			gvr := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+			err = scanner.CheckPolicyServer(context.Background(), &policies.Policy{PolicyServer: policyServerURL}, gvr)
+			if test.expectError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.NotNil(t, admissionReview.Request)
+			assert.Equal(t, preflightResourceName, admissionReview.Request.Name)
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package scanner
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// redactionKindRegex matches the kind prefixing the JSONPath of a redaction.
+var redactionKindRegex = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*$`)
+
+// Redaction removes a field from the resources before they are sent to the
+// Policy Servers, so that sensitive data, e.g. the data of Secrets, doesn't
+// leave the scanner.
+type Redaction struct {
+	// kind restricts the redaction to the resources of this kind, it applies
+	// to all the resources when empty
+	kind  string
+	nodes []jsonpath.Node
+}
+
+// ParseRedaction parses a redaction in the form [<kind>:]<JSONPath>, e.g.
+// Secret:.data or .metadata.annotations.kubectl\.kubernetes\.io/last-applied-configuration.
+// The JSONPath can select fields and all the items of lists with [*], and it
+// must end with a field. The braces and the leading $ are optional.
+func ParseRedaction(expression string) (Redaction, error) {
+	redaction := Redaction{}
+	path := expression
+	if kind, rest, found := strings.Cut(expression, ":"); found && redactionKindRegex.MatchString(kind) {
+		redaction.kind = kind
+		path = rest
+	}
+
+	path = strings.TrimSuffix(strings.TrimPrefix(path, "{"), "}")
+	path = strings.TrimPrefix(path, "$")
+	if !strings.HasPrefix(path, ".") && !strings.HasPrefix(path, "[") {
+		path = "." + path
+	}
+	parser, err := jsonpath.Parse("redaction", "{"+path+"}")
+	if err != nil {
+		return Redaction{}, fmt.Errorf("cannot parse JSONPath %q: %w", expression, err)
+	}
+	if len(parser.Root.Nodes) != 1 {
+		return Redaction{}, fmt.Errorf("JSONPath %q must be a single expression", expression)
+	}
+	list, ok := parser.Root.Nodes[0].(*jsonpath.ListNode)
+	if !ok || len(list.Nodes) == 0 {
+		return Redaction{}, fmt.Errorf("JSONPath %q must select a field", expression)
+	}
+
+	for _, node := range list.Nodes {
+		switch node := node.(type) {
+		case *jsonpath.FieldNode:
+		case *jsonpath.ArrayNode:
+			if node.Params[0].Known || node.Params[1].Known || node.Params[2].Known {
+				return Redaction{}, fmt.Errorf("JSONPath %q can select only all the items of a list, with [*]", expression)
+			}
+		default:
+			return Redaction{}, fmt.Errorf("JSONPath %q can select only fields and all the items of lists, found %s", expression, node.Type())
+		}
+	}
+	if _, ok := list.Nodes[len(list.Nodes)-1].(*jsonpath.FieldNode); !ok {
+		return Redaction{}, fmt.Errorf("JSONPath %q must end with a field", expression)
+	}
+	redaction.nodes = list.Nodes
+
+	return redaction, nil
+}
+
+// redact returns the resource without the fields selected by the redactions
+// matching its kind. The resource is copied only when a redaction applies.
+func redact(resource unstructured.Unstructured, redactions []Redaction) unstructured.Unstructured {
+	redacted := false
+	for _, redaction := range redactions {
+		if redaction.kind != "" && redaction.kind != resource.GetKind() {
+			continue
+		}
+		if !redacted {
+			resource = *resource.DeepCopy()
+			redacted = true
+		}
+		removeNodes(resource.Object, redaction.nodes)
+	}
+
+	return resource
+}
+
+// removeNodes walks the value along the nodes, removing the field selected
+// by the last one.
+func removeNodes(value any, nodes []jsonpath.Node) {
+	switch node := nodes[0].(type) {
+	case *jsonpath.FieldNode:
+		fields, ok := value.(map[string]any)
+		if !ok {
+			return
+		}
+		if len(nodes) == 1 {
+			delete(fields, node.Value)
+			return
+		}
+		removeNodes(fields[node.Value], nodes[1:])
+	case *jsonpath.ArrayNode:
+		items, ok := value.([]any)
+		if !ok {
+			return
+		}
+		for _, item := range items {
+			removeNodes(item, nodes[1:])
+		}
+	}
+}
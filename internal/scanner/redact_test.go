@@ -0,0 +1,103 @@
+package scanner
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestParseRedaction(t *testing.T) {
+	tests := []struct {
+		expression    string
+		expectedError bool
+	}{
+		{expression: "Secret:.data"},
+		{expression: "Secret:{.data}"},
+		{expression: "$.metadata.annotations.kubectl\\.kubernetes\\.io/last-applied-configuration"},
+		{expression: "spec.containers[*].env"},
+		{expression: "Pod:.spec.containers[0].env", expectedError: true},
+		{expression: "..data", expectedError: true},
+		{expression: ".spec.containers[*]", expectedError: true},
+		{expression: ".data[", expectedError: true},
+		{expression: ".items[?(@.name==\"a\")].value", expectedError: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expression, func(t *testing.T) {
+			_, err := ParseRedaction(test.expression)
+			if test.expectedError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAdmissionReviewPayloadIsRedacted(t *testing.T) {
+	secret := unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata": map[string]any{
+			"name":      "credentials",
+			"namespace": "default",
+			"annotations": map[string]any{
+				"kubectl.kubernetes.io/last-applied-configuration": "{}",
+				"team": "payments",
+			},
+		},
+		"data": map[string]any{"password": "c2VjcmV0"},
+		"type": "Opaque",
+	}}
+	pod := unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata":   map[string]any{"name": "nginx", "namespace": "default"},
+		"data":       "not a secret",
+		"spec": map[string]any{"containers": []any{
+			map[string]any{"name": "nginx", "env": []any{map[string]any{"name": "TOKEN", "value": "secret"}}},
+			map[string]any{"name": "sidecar"},
+		}},
+	}}
+
+	redactions := []Redaction{}
+	for _, expression := range []string{
+		"Secret:.data",
+		".metadata.annotations.kubectl\\.kubernetes\\.io/last-applied-configuration",
+		".spec.containers[*].env",
+	} {
+		redaction, err := ParseRedaction(expression)
+		require.NoError(t, err)
+		redactions = append(redactions, redaction)
+	}
+
+	payload, err := newAdmissionReviewPayload(secret, AdmissionConfig{}, redactions)()
+	require.NoError(t, err)
+	admissionReview := admv1.AdmissionReview{}
+	require.NoError(t, json.Unmarshal(payload, &admissionReview))
+	redactedSecret := map[string]any{}
+	require.NoError(t, json.Unmarshal(admissionReview.Request.Object.Raw, &redactedSecret))
+	assert.NotContains(t, redactedSecret, "data")
+	assert.Equal(t, "Opaque", redactedSecret["type"])
+	assert.Equal(t, map[string]any{"team": "payments"}, redactedSecret["metadata"].(map[string]any)["annotations"])
+
+	payload, err = newAdmissionReviewPayload(pod, AdmissionConfig{}, redactions)()
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(payload, &admissionReview))
+	redactedPod := map[string]any{}
+	require.NoError(t, json.Unmarshal(admissionReview.Request.Object.Raw, &redactedPod))
+	// the redaction of the Secrets doesn't apply to the other kinds
+	assert.Equal(t, "not a secret", redactedPod["data"])
+	assert.Equal(t, []any{
+		map[string]any{"name": "nginx"},
+		map[string]any{"name": "sidecar"},
+	}, redactedPod["spec"].(map[string]any)["containers"])
+
+	// the resources themselves are left untouched
+	assert.Contains(t, secret.Object, "data")
+	assert.Contains(t, pod.Object["spec"].(map[string]any)["containers"].([]any)[0], "env")
+}
@@ -1,43 +1,120 @@
 package scanner
 
 import (
-	"bytes"
 	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
-	"net/url"
 	"os"
+	"regexp"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/kubewarden/audit-scanner/internal/k8s"
+	"github.com/kubewarden/audit-scanner/internal/metrics"
 	"github.com/kubewarden/audit-scanner/internal/policies"
 	"github.com/kubewarden/audit-scanner/internal/report"
+	"github.com/kubewarden/audit-scanner/internal/tracing"
 	policiesv1 "github.com/kubewarden/kubewarden-controller/api/policies/v1"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/semaphore"
+	"golang.org/x/time/rate"
 	admissionv1 "k8s.io/api/admission/v1"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apimachineryerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	wgpolicy "sigs.k8s.io/wg-policy-prototypes/policy-report/pkg/api/wgpolicyk8s.io/v1alpha2"
 )
 
-const httpClientTimeout = 10 * time.Second
+// errResourceTooLarge is returned when the AdmissionReview of a resource is
+// bigger than the maximum size configured.
+var errResourceTooLarge = errors.New("resource too large")
+
+// errSampleComplete stops the listing of the resources of a type once the
+// sample has been audited.
+var errSampleComplete = errors.New("sample complete")
+
+// defaultPolicyServerTimeout is the timeout of the requests to the Policy Server
+// used when none is configured.
+const defaultPolicyServerTimeout = 10 * time.Second
 
 // Scanner verifies that existing resources don't violate any of the policies.
 type Scanner struct {
 	policiesClient    *policies.Client
 	k8sClient         *k8s.Client
 	policyReportStore *report.PolicyReportStore
+	// metrics records the progress of the scan, it's nil when metrics are disabled
+	metrics *metrics.Metrics
 	// http client used to make requests against the Policy Server
-	httpClient               http.Client
+	httpClient http.Client
+	// timeout of every single request made against the Policy Server
+	policyServerTimeout time.Duration
+	// number of retries of requests failed because of transient errors
+	policyServerMaxRetries int
+	// initial backoff between retries, doubled at every attempt
+	policyServerRetryBackoff time.Duration
+	// policyServerLimiter rate limits the requests sent to the Policy Servers, it's nil when they aren't limited
+	policyServerLimiter *rate.Limiter
+	// policyServerUserAgent is sent in the User-Agent header of the requests to the Policy Servers
+	policyServerUserAgent string
+	// maxResourceBytes is the maximum size of the AdmissionReviews sent, they aren't limited when it's 0
+	maxResourceBytes int
+	// compressRequests enables sending the big AdmissionReviews gzipped
+	compressRequests bool
+	// policyServerToken provides the bearer token sent to the Policy Server, it's nil when no token is used
+	policyServerToken tokenSource
+	// namespaceSelector restricts the namespaces scanned by ScanAllNamespaces
+	namespaceSelector labels.Selector
+	// skipNamespaceRegexes skip the namespaces matching any of them in ScanAllNamespaces
+	skipNamespaceRegexes []*regexp.Regexp
+	// resourceSelector restricts the resources fetched and audited
+	resourceSelector labels.Selector
+	// fieldSelector restricts the resources fetched and audited by their fields
+	fieldSelector fields.Selector
+	// severityFilter drops the results of the policies below a severity, it's nil when all the results are reported
+	severityFilter *report.SeverityFilter
+	// redactions remove fields from the resources before they are sent to the Policy Servers
+	redactions []Redaction
+	// admission tells how the AdmissionRequests sent to the Policy Servers are framed
+	admission AdmissionConfig
+	// skipOwnedResources skips the resources controlled by an owner of one of skipOwnerKinds
+	skipOwnedResources bool
+	// skipOwnerKinds are the kinds of the owners whose resources are skipped, all of them when empty
+	skipOwnerKinds []string
+	// sampleSize is the number of resources of every type audited, all of them are audited when it's 0
+	sampleSize int
+	// recordTimings adds the duration of the evaluation of every policy to its result
+	recordTimings bool
+	// keepOldReports disables the deletion of the reports written by previous scans
+	keepOldReports bool
+	// pruneStaleReports enables the deletion of the PolicyReports of the deleted namespaces by ScanAllNamespaces
+	pruneStaleReports bool
+	// mirrorNamespace is where the results of the cluster-wide resources are copied to, it's empty when they aren't
+	mirrorNamespace string
+	// verdictCache keeps the verdicts of the policies on the resource contents, it's nil when they aren't cached
+	verdictCache *verdictCache
+	// errors collects the non-fatal errors, it's nil when they are only logged
+	errors *errorCollector
+	// checkpointFile is where the progress of the scans is recorded, it's empty when it isn't
+	checkpointFile string
+	// checkpoint records the progress of the scan in progress, it's nil when it isn't recorded
+	checkpoint               *checkpointer
 	outputScan               bool
 	disableStore             bool
 	parallelNamespacesAudits int
@@ -46,9 +123,9 @@ type Scanner struct {
 }
 
 // NewScanner creates a new scanner
-// If insecureClient is false, it will read the caCertFile and add it to the in-app
-// cert trust store. This gets used by the httpClient when connection to
-// PolicyServers endpoints.
+// If insecureClient is false, it will read the caCertFile and the certs of the
+// CA directory and add them to the in-app cert trust store. This gets used by
+// the httpClient when connection to PolicyServers endpoints.
 func NewScanner(
 	config Config,
 ) (*Scanner, error) {
@@ -63,6 +140,14 @@ func NewScanner(
 		rootCAs = x509.NewCertPool()
 	}
 
+	if config.TLS.CADir != "" {
+		if err := appendCACertsFromDir(rootCAs, config.TLS.CADir); err != nil {
+			return nil, err
+		}
+	}
+	// the pool without the CA file, the new content of the file is appended to it when it changes
+	basePool := rootCAs.Clone()
+
 	if config.TLS.CAFile != "" {
 		caCert, err := os.ReadFile(config.TLS.CAFile)
 		if err != nil {
@@ -78,30 +163,31 @@ func NewScanner(
 
 	tlsConfig.RootCAs = rootCAs
 
+	if (config.TLS.ClientCertFile == "") != (config.TLS.ClientKeyFile == "") {
+		return nil, errors.New("both the client certificate and the client key are required for mTLS communication with the PolicyServers, only one of them was provided")
+	}
 	if config.TLS.ClientCertFile != "" && config.TLS.ClientKeyFile != "" {
 		cert, err := tls.LoadX509KeyPair(config.TLS.ClientCertFile, config.TLS.ClientKeyFile)
 		if err != nil {
-			return nil, fmt.Errorf("loading client certificate: %w", err)
+			return nil, fmt.Errorf("failed to load client certificate %q and key %q: %w", config.TLS.ClientCertFile, config.TLS.ClientKeyFile, err)
 		}
 		log.Debug().Str("client-cert", config.TLS.ClientCertFile).
-			Str("client-key", config.TLS.ClientCertFile).
-			Msg("appended cert file to in-app RootCAs trust store")
+			Str("client-key", config.TLS.ClientKeyFile).
+			Msg("loaded client certificate for mTLS communication with the PolicyServers")
 
 		tlsConfig.Certificates = []tls.Certificate{cert}
 	}
 
-	if config.TLS.Insecure {
-		log.Warn().Msg("connecting to PolicyServers endpoints without validating TLS connection")
-	}
 	tlsConfig.InsecureSkipVerify = config.TLS.Insecure
 
 	httpClient := *http.DefaultClient
-	httpClient.Timeout = httpClientTimeout
-	httpClient.Transport = http.DefaultTransport
-	transport, ok := httpClient.Transport.(*http.Transport)
+	defaultTransport, ok := http.DefaultTransport.(*http.Transport)
 	if !ok {
 		return nil, errors.New("failed to build httpClient: failed http.Transport type assertion")
 	}
+	// the default transport is shared by the whole process, hence it's cloned before being tuned
+	transport := defaultTransport.Clone()
+	httpClient.Transport = transport
 
 	transport.TLSClientConfig = tlsConfig
 
@@ -111,13 +197,137 @@ func NewScanner(
 	// PolicyServer Pod, causing the load to be unevenly distributed.
 	// To avoid this, we disable keep-alives, which ensures a
 	// new connection is created for each evaluation request.
-	transport.DisableKeepAlives = true
+	// Keep-alives can be enabled when the PolicyServers have a single replica,
+	// or when the load is spread by other means, e.g. a service mesh, to avoid
+	// a TCP and TLS handshake for every request.
+	transport.DisableKeepAlives = !config.PolicyServer.KeepAlive
+	if config.PolicyServer.MaxIdleConns > 0 {
+		transport.MaxIdleConns = config.PolicyServer.MaxIdleConns
+	}
+	if config.PolicyServer.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = config.PolicyServer.MaxIdleConnsPerHost
+	}
+	if config.PolicyServer.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = config.PolicyServer.IdleConnTimeout
+	}
+	if config.TLS.CAFile != "" && !config.TLS.Insecure {
+		// the CA of the PolicyServers can be rotated while the scanner is running
+		reloadingTransport, err := newCAReloadingTransport(config.TLS.CAFile, basePool, transport)
+		if err != nil {
+			return nil, err
+		}
+		httpClient.Transport = reloadingTransport
+	}
+
+	policyServerTimeout := config.PolicyServer.Timeout
+	if policyServerTimeout <= 0 {
+		policyServerTimeout = defaultPolicyServerTimeout
+	}
+
+	admission := config.Admission
+	if admission.Operation == "" {
+		admission.Operation = admissionv1.Create
+	}
+	if !slices.Contains(SupportedAdmissionOperations(), admission.Operation) {
+		return nil, fmt.Errorf("unsupported admission operation %q, supported values are: %v", admission.Operation, SupportedAdmissionOperations())
+	}
+	if admission.APIVersion == "" {
+		admission.APIVersion = admissionv1.SchemeGroupVersion.String()
+	}
+	if !slices.Contains(SupportedAdmissionAPIVersions(), admission.APIVersion) {
+		return nil, fmt.Errorf("unsupported admission API version %q, supported values are: %v", admission.APIVersion, SupportedAdmissionAPIVersions())
+	}
+
+	var policyServerToken tokenSource
+	switch {
+	case config.PolicyServer.Token != "" && config.PolicyServer.TokenFile != "":
+		return nil, errors.New("the PolicyServer token and token file cannot be used together")
+	case config.PolicyServer.Token != "":
+		policyServerToken = staticTokenSource(config.PolicyServer.Token)
+	case config.PolicyServer.TokenFile != "":
+		fileToken, err := newFileTokenSource(config.PolicyServer.TokenFile)
+		if err != nil {
+			return nil, err
+		}
+		policyServerToken = fileToken
+	}
+
+	var policyServerLimiter *rate.Limiter
+	if config.PolicyServer.QPS > 0 {
+		if config.PolicyServer.Burst < 1 {
+			return nil, errors.New("the PolicyServer burst must be at least 1 when the requests are rate limited")
+		}
+		policyServerLimiter = rate.NewLimiter(rate.Limit(config.PolicyServer.QPS), config.PolicyServer.Burst)
+	}
+
+	namespaceSelector := config.NamespaceSelector
+	if namespaceSelector == nil {
+		namespaceSelector = labels.Everything()
+	}
+
+	resourceSelector := config.ResourceSelector
+	if resourceSelector == nil {
+		resourceSelector = labels.Everything()
+	}
+
+	fieldSelector := config.FieldSelector
+	if fieldSelector == nil {
+		fieldSelector = fields.Everything()
+	}
+
+	if config.SampleSize < 0 {
+		return nil, fmt.Errorf("sample size cannot be negative: %d", config.SampleSize)
+	}
+
+	var scanErrors *errorCollector
+	if config.CollectErrors {
+		scanErrors = &errorCollector{}
+	}
+
+	if config.VerdictCacheTTL < 0 {
+		return nil, fmt.Errorf("verdict cache TTL cannot be negative: %s", config.VerdictCacheTTL)
+	}
+	var verdictCache *verdictCache
+	if config.VerdictCacheTTL > 0 {
+		verdictCache = newVerdictCache(config.VerdictCacheTTL)
+	}
+	// the reports of the resources left out of the sample, not selected, of
+	// the kinds not audited, skipped as owned or matched only by the policies
+	// not evaluated are still current
+	keepOldReports := config.KeepOldReports || config.SampleSize > 0 || !resourceSelector.Empty() || !fieldSelector.Empty() ||
+		len(config.IncludedKinds) > 0 || len(config.ExcludedKinds) > 0 || config.SkipOwnedResources || len(config.PolicyNames) > 0
 
 	return &Scanner{
 		policiesClient:           config.PoliciesClient,
 		k8sClient:                config.K8sClient,
 		policyReportStore:        config.PolicyReportStore,
+		metrics:                  config.Metrics,
 		httpClient:               httpClient,
+		policyServerTimeout:      policyServerTimeout,
+		policyServerMaxRetries:   config.PolicyServer.MaxRetries,
+		policyServerRetryBackoff: config.PolicyServer.RetryBackoff,
+		policyServerLimiter:      policyServerLimiter,
+		policyServerUserAgent:    config.PolicyServer.UserAgent,
+		maxResourceBytes:         config.PolicyServer.MaxResourceBytes,
+		compressRequests:         config.PolicyServer.CompressRequests,
+		policyServerToken:        policyServerToken,
+		namespaceSelector:        namespaceSelector,
+		skipNamespaceRegexes:     config.SkipNamespaceRegexes,
+		resourceSelector:         resourceSelector,
+		fieldSelector:            fieldSelector,
+		severityFilter:           config.SeverityFilter,
+		redactions:               config.Redactions,
+		admission:                admission,
+		skipOwnedResources:       config.SkipOwnedResources,
+		skipOwnerKinds:           config.SkipOwnerKinds,
+		sampleSize:               config.SampleSize,
+		recordTimings:            config.RecordTimings,
+		keepOldReports:           keepOldReports,
+		pruneStaleReports:        config.PruneStaleReports,
+		mirrorNamespace:          config.MirrorNamespace,
+		verdictCache:             verdictCache,
+		errors:                   scanErrors,
+		checkpointFile:           config.CheckpointFile,
 		outputScan:               config.OutputScan,
 		disableStore:             config.DisableStore,
 		parallelNamespacesAudits: config.Parallelization.ParallelNamespacesAudits,
@@ -130,23 +340,36 @@ func NewScanner(
 // Returns errors if there's any when fetching policies or resources, but only
 // logs them if there's a problem auditing the resource of saving the Report or
 // Result, so it can continue with the next audit, or next Result.
+// A failure listing a resource type doesn't stop the scan of the other types:
+// the errors are joined and returned once all of them have been scanned.
+// A namespace being deleted is skipped, since its resources are going away
+// and no report can be created in it.
 func (s *Scanner) ScanNamespace(ctx context.Context, nsName, runUID string) error {
+	ctx, span := tracing.Tracer().Start(ctx, "ScanNamespace", trace.WithAttributes(
+		attribute.String("namespace", nsName),
+		attribute.String("run-uid", runUID),
+	))
+	defer span.End()
+
 	log.Info().
 		Dict("dict", zerolog.Dict().
 			Str("namespace", nsName).
 			Str("RunUID", runUID).
 			Int("parallel-resources-audits", s.parallelResourcesAudits),
 		).Msg("namespace scan started")
-	semaphore := semaphore.NewWeighted(int64(s.parallelResourcesAudits))
-	var workers sync.WaitGroup
 
 	namespace, err := s.k8sClient.GetNamespace(ctx, nsName)
 	if err != nil {
 		return err
 	}
+	if namespace.Status.Phase == corev1.NamespaceTerminating {
+		log.Info().Str("namespace", nsName).Msg("namespace is terminating, skipping it")
+		return nil
+	}
 	policies, err := s.policiesClient.GetPoliciesByNamespace(ctx, namespace)
 	if err != nil {
 		log.Error().Err(err).Str("namespace", nsName).Msg("failed to obtain auditable policies")
+		s.errors.add(ScanError{Kind: ScanErrorPolicies, Namespace: nsName, Message: err.Error()})
 		return err
 	}
 
@@ -158,69 +381,83 @@ func (s *Scanner) ScanNamespace(ctx context.Context, nsName, runUID string) erro
 			Int("policies-errored", policies.ErroredNum),
 		).Msg("policy count")
 
-	for gvr, pols := range policies.PoliciesByGVR {
-		pager, err := s.k8sClient.GetResources(gvr, nsName)
-		if err != nil {
-			log.Error().Err(err).Str("gvr", gvr.String()).Str("ns", nsName).Msg("failed to get resources")
-		}
-
-		err = pager.EachListItem(ctx, metav1.ListOptions{}, func(obj runtime.Object) error {
-			resource, ok := obj.(*unstructured.Unstructured)
-			if !ok {
-				return errors.New("failed to convert runtime.Object to *unstructured.Unstructured")
-			}
-
-			err := semaphore.Acquire(ctx, 1)
-			if err != nil {
-				return err
-			}
-			workers.Add(1)
-			policiesToAudit := pols
-
-			go func() {
-				defer semaphore.Release(1)
-				defer workers.Done()
-
-				if err := s.auditResource(ctx, policiesToAudit, *resource, runUID, policies.SkippedNum, policies.ErroredNum); err != nil {
-					log.Error().Err(err).Str("RunUID", runUID).Msg("error auditing resource")
-				}
-			}()
-			return nil
-		})
-		if err != nil {
-			return err
-		}
+	err = s.auditResources(ctx, nsName, runUID, policies, s.auditResource)
+	if ctx.Err() != nil {
+		// the scan has been interrupted, the reports of the resources not
+		// audited by this run must not be deleted
+		return ctx.Err()
 	}
-	workers.Wait()
-	if err := s.policyReportStore.DeleteOldPolicyReports(ctx, runUID, nsName); err != nil {
+	if err != nil {
+		// the reports of the resources that could not be listed must not be
+		// deleted either
+		return err
+	}
+	s.policyReportStore.RecordNamespaceScanned()
+	if s.keepOldReports {
+		log.Debug().Str("namespace", nsName).Msg("keeping the PolicyReports of previous scans")
+	} else if err := s.policyReportStore.DeleteOldPolicyReports(ctx, runUID, nsName); err != nil {
 		log.Error().Err(err).Str("RunUID", runUID).Msg("error deleting old PolicyReports")
+		s.errors.add(ScanError{Kind: ScanErrorDeleteReports, Namespace: nsName, Message: err.Error()})
 	}
+	s.checkpoint.completeNamespace(nsName)
 	log.Info().Msg("Namespaced resources scan finished")
 	return nil
 }
 
-// ScanAllNamespaces scans resources for all namespaces, except the ones in the skipped list.
+// ScanAllNamespaces scans resources for all namespaces, except the ones in the skipped list
+// and the ones not matching the namespace selector.
 // Returns errors if there's any when fetching policies or resources, but only
 // logs them if there's a problem auditing the resource of saving the Report or
 // Result, so it can continue with the next audit, or next Result.
 func (s *Scanner) ScanAllNamespaces(ctx context.Context, runUID string) error {
+	ctx, span := tracing.Tracer().Start(ctx, "ScanAllNamespaces", trace.WithAttributes(attribute.String("run-uid", runUID)))
+	defer span.End()
+
 	log.Info().
 		Dict("dict", zerolog.Dict().
-			Int("parallel-namespaces-audits", s.parallelNamespacesAudits),
+			Int("parallel-namespaces-audits", s.parallelNamespacesAudits).
+			Strs("skipped-namespaces", s.k8sClient.SkippedNamespaces()),
 		).Msg("all-namespaces scan started")
 	nsList, err := s.k8sClient.GetAuditedNamespaces(ctx)
 	if err != nil {
 		log.Error().Err(err).Msg("error scanning all namespaces")
+		return err
 	}
 	semaphore := semaphore.NewWeighted(int64(s.parallelNamespacesAudits))
 	var workers sync.WaitGroup
+	// scanErrs joins the errors of the namespaces scanned concurrently
+	var scanErrs error
+	var scanErrsMutex sync.Mutex
+	scannedNamespaces := sets.New[string]()
+	skippedByRegex := 0
 
 	for _, namespace := range nsList.Items {
-		workers.Add(1)
+		// the skipped namespaces are usually filtered out by the API server already
+		if s.k8sClient.IsSkippedNamespace(namespace.Name) {
+			log.Debug().Str("ns", namespace.Name).Msg("namespace is skipped")
+			continue
+		}
+		if regex := s.matchSkipNamespaceRegex(namespace.Name); regex != nil {
+			log.Debug().Str("ns", namespace.Name).Str("skip-namespace-regex", regex.String()).Msg("namespace matches a skip regex, skipping")
+			skippedByRegex++
+			continue
+		}
+		if !s.namespaceSelector.Matches(labels.Set(namespace.GetLabels())) {
+			log.Debug().Str("ns", namespace.Name).Str("namespace-selector", s.namespaceSelector.String()).Msg("namespace doesn't match the namespace selector, skipping")
+			continue
+		}
+		scannedNamespaces.Insert(namespace.Name)
+		if s.checkpoint.isNamespaceCompleted(namespace.Name) {
+			log.Debug().Str("ns", namespace.Name).Msg("namespace already scanned before the scan was resumed, skipping")
+			continue
+		}
 		err := semaphore.Acquire(ctx, 1)
 		if err != nil {
+			// the scan has been cancelled, wait for the namespaces being scanned
+			workers.Wait()
 			return err
 		}
+		workers.Add(1)
 		namespaceName := namespace.Name
 
 		go func() {
@@ -229,26 +466,70 @@ func (s *Scanner) ScanAllNamespaces(ctx context.Context, runUID string) error {
 
 			if e := s.ScanNamespace(ctx, namespaceName, runUID); e != nil {
 				log.Error().Err(e).Str("ns", namespaceName).Msg("error scanning namespace")
-				err = errors.Join(err, e)
+				scanErrsMutex.Lock()
+				scanErrs = errors.Join(scanErrs, e)
+				scanErrsMutex.Unlock()
 			}
 		}()
 	}
 	workers.Wait()
+	if skippedByRegex > 0 {
+		log.Info().Int("skipped-namespaces", skippedByRegex).Msg("namespaces matching a skip regex were not scanned")
+	}
+
+	// stale reports are pruned only after a complete scan, otherwise the
+	// reports of namespaces that still exist could be deleted
+	if s.pruneStaleReports && ctx.Err() == nil {
+		if e := s.policyReportStore.DeleteStalePolicyReports(ctx, scannedNamespaces); e != nil {
+			log.Error().Err(e).Msg("error deleting stale PolicyReports")
+			s.errors.add(ScanError{Kind: ScanErrorDeleteReports, Message: e.Error()})
+		}
+	}
 
 	log.Info().Msg("all-namespaces scan finished")
 
-	return err
+	return scanErrs
+}
+
+// isResourceUnavailable returns true when listing resources failed because
+// their type is not served by the API server.
+func isResourceUnavailable(err error) bool {
+	return apimachineryerrors.IsNotFound(err) || meta.IsNoMatchError(err)
+}
+
+// isScanInterrupted returns true when the error comes from the scan being
+// cancelled or timing out, rather than from the resource being audited.
+func isScanInterrupted(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// matchSkipNamespaceRegex returns the first skip regex matching the name of
+// the namespace, or nil when the namespace has to be scanned.
+func (s *Scanner) matchSkipNamespaceRegex(nsName string) *regexp.Regexp {
+	for _, regex := range s.skipNamespaceRegexes {
+		if regex.MatchString(nsName) {
+			return regex
+		}
+	}
+
+	return nil
 }
 
 // ScanClusterWideResources scans all cluster wide resources.
 // Returns errors if there's any when fetching policies or resources, but only
 // logs them if there's a problem auditing the resource of saving the Report or
 // Result, so it can continue with the next audit, or next Result.
+// A failure listing a resource type doesn't stop the scan of the other types:
+// the errors are joined and returned once all of them have been scanned.
 func (s *Scanner) ScanClusterWideResources(ctx context.Context, runUID string) error {
-	log.Info().Str("RunUID", runUID).Msg("clusterwide resources scan started")
+	ctx, span := tracing.Tracer().Start(ctx, "ScanClusterWideResources", trace.WithAttributes(attribute.String("run-uid", runUID)))
+	defer span.End()
 
-	semaphore := semaphore.NewWeighted(int64(s.parallelResourcesAudits))
-	var workers sync.WaitGroup
+	if s.checkpoint.isClusterWideCompleted() {
+		log.Info().Str("RunUID", runUID).Msg("clusterwide resources already scanned before the scan was resumed, skipping them")
+		return nil
+	}
+	log.Info().Str("RunUID", runUID).Msg("clusterwide resources scan started")
 
 	policies, err := s.policiesClient.GetClusterWidePolicies(ctx)
 	if err != nil {
@@ -263,44 +544,198 @@ func (s *Scanner) ScanClusterWideResources(ctx context.Context, runUID string) e
 			Int("parallel-resources-audits", s.parallelResourcesAudits),
 		).Msg("cluster admission policies count")
 
+	err = s.auditResources(ctx, "", runUID, policies, s.auditClusterResource)
+	if ctx.Err() != nil {
+		// the scan has been interrupted, the reports of the resources not
+		// audited by this run must not be deleted
+		return ctx.Err()
+	}
+	if err != nil {
+		// the reports of the resources that could not be listed must not be
+		// deleted either
+		return err
+	}
+	if s.keepOldReports {
+		log.Debug().Msg("keeping the ClusterPolicyReports of previous scans")
+	} else if err := s.policyReportStore.DeleteOldClusterPolicyReports(ctx, runUID); err != nil {
+		log.Error().Err(err).Str("RunUID", runUID).Msg("error deleting old ClusterPolicyReports")
+		s.errors.add(ScanError{Kind: ScanErrorDeleteReports, Message: err.Error()})
+	}
+	if s.mirrorNamespace != "" && !s.keepOldReports {
+		if err := s.policyReportStore.DeleteOldMirroredPolicyReports(ctx, runUID, s.mirrorNamespace); err != nil {
+			log.Error().Err(err).Str("RunUID", runUID).Str("namespace", s.mirrorNamespace).Msg("error deleting old mirrored PolicyReports")
+			s.errors.add(ScanError{Kind: ScanErrorDeleteReports, Message: err.Error()})
+		}
+	}
+	s.checkpoint.completeClusterWide()
+	log.Info().Msg("Cluster-wide resources scan finished")
+
+	return nil
+}
+
+// auditResources audits the resources of every type of the policies, in the
+// namespace, or cluster wide when nsName is empty, with audit, running up to
+// parallelResourcesAudits audits at the same time.
+// A failure listing a resource type doesn't stop the audit of the other types:
+// the errors are joined and returned once all of them have been audited. The
+// error stopping the iteration, e.g. the cancellation of the scan, is returned
+// once the running audits complete.
+func (s *Scanner) auditResources(ctx context.Context, nsName, runUID string, policies *policies.Policies, audit auditResourceFunc) error {
+	semaphore := semaphore.NewWeighted(int64(s.parallelResourcesAudits))
+	var workers sync.WaitGroup
+	defer workers.Wait()
+
+	var listErrs error
 	for gvr, pols := range policies.PoliciesByGVR {
-		pager, err := s.k8sClient.GetResources(gvr, "")
+		if s.checkpoint.isGVRCompleted(nsName, gvr) {
+			withScope(log.Debug(), gvr, nsName).Msg("resources already audited before the scan was resumed, skipping them")
+			continue
+		}
+		pager, err := s.k8sClient.GetResources(gvr, nsName)
 		if err != nil {
-			return err
+			withScope(log.Error(), gvr, nsName).Err(err).Msg("failed to get resources")
+			s.errors.add(ScanError{Kind: ScanErrorListResources, Namespace: nsName, GVR: gvr.String(), Message: err.Error()})
+			listErrs = errors.Join(listErrs, listResourcesError(gvr, nsName, err))
+			continue
 		}
 
-		err = pager.EachListItem(ctx, metav1.ListOptions{}, func(obj runtime.Object) error {
+		// gvrWorkers tracks the audits of the resources of this type, to record it as completed
+		var gvrWorkers sync.WaitGroup
+		// sampled counts the resources of this type audited, when sampling
+		sampled := 0
+		err = pager.EachListItem(ctx, s.resourceListOptions(), func(obj runtime.Object) error {
+			if err := ctx.Err(); err != nil {
+				// the scan has been cancelled: stop paging through the resources
+				return err
+			}
 			resource, ok := obj.(*unstructured.Unstructured)
 			if !ok {
 				return errors.New("failed to convert runtime.Object to *unstructured.Unstructured")
 			}
 
-			workers.Add(1)
 			err := semaphore.Acquire(ctx, 1)
 			if err != nil {
 				return err
 			}
+			workers.Add(1)
+			gvrWorkers.Add(1)
 			policiesToAudit := pols
 
 			go func() {
 				defer semaphore.Release(1)
 				defer workers.Done()
+				defer gvrWorkers.Done()
 
-				s.auditClusterResource(ctx, policiesToAudit, *resource, runUID, policies.SkippedNum, policies.ErroredNum)
+				err := audit(ctx, policiesToAudit, gvr, *resource, runUID, policies.SkippedNum, policies.ErroredNum)
+				if err != nil && !isScanInterrupted(err) {
+					withScope(log.Error(), gvr, nsName).Err(err).Str("RunUID", runUID).Msg("error auditing resource")
+					s.errors.add(ScanError{Kind: ScanErrorAuditResource, Namespace: nsName, GVR: gvr.String(), Resource: resource.GetName(), Message: err.Error()})
+				}
 			}()
+			sampled++
+			if s.sampleSize > 0 && sampled >= s.sampleSize {
+				return errSampleComplete
+			}
 
 			return nil
 		})
+		if errors.Is(err, errSampleComplete) {
+			log.Debug().Str("gvr", gvr.String()).Int("sample-size", s.sampleSize).Msg("sample audited, skipping the other resources")
+			err = nil
+		}
+		if apimachineryerrors.IsBadRequest(err) {
+			// the API server rejected the request, e.g. because the field selector
+			// is not supported by this resource type: skip it and go on
+			withScope(log.Error(), gvr, nsName).Err(err).
+				Str("field-selector", s.fieldSelector.String()).
+				Msg("API server rejected the request to list resources, skipping them")
+			continue
+		}
+		if isResourceUnavailable(err) {
+			// the resource is not served anymore, e.g. the CRD has been removed
+			withScope(log.Warn(), gvr, nsName).Err(err).Msg("resource type not available in the cluster, skipping it")
+			continue
+		}
+		if err != nil && ctx.Err() == nil {
+			// a failure listing a resource type doesn't prevent auditing the others
+			withScope(log.Error(), gvr, nsName).Err(err).Msg("failed to list resources, skipping them")
+			s.errors.add(ScanError{Kind: ScanErrorListResources, Namespace: nsName, GVR: gvr.String(), Message: err.Error()})
+			listErrs = errors.Join(listErrs, listResourcesError(gvr, nsName, err))
+			continue
+		}
 		if err != nil {
 			return err
 		}
+		if s.checkpoint != nil {
+			workers.Add(1)
+			go func() {
+				defer workers.Done()
+				gvrWorkers.Wait()
+				if ctx.Err() == nil {
+					s.checkpoint.completeGVR(nsName, gvr)
+				}
+			}()
+		}
 	}
 
-	workers.Wait()
-	if err := s.policyReportStore.DeleteOldClusterPolicyReports(ctx, runUID); err != nil {
-		log.Error().Err(err).Str("RunUID", runUID).Msg("error deleting old ClusterPolicyReports")
+	return listErrs
+}
+
+// auditResourceFunc audits a single resource, e.g. auditResource or
+// auditClusterResource.
+type auditResourceFunc func(ctx context.Context, policies []*policies.Policy, gvr schema.GroupVersionResource, resource unstructured.Unstructured, runUID string, skippedPoliciesNum, erroredPoliciesNum int) error
+
+// listResourcesError returns the error listing the resources of a type, in the
+// namespace or cluster wide when nsName is empty.
+func listResourcesError(gvr schema.GroupVersionResource, nsName string, err error) error {
+	if nsName == "" {
+		return fmt.Errorf("cannot list %s: %w", gvr.String(), err)
 	}
-	log.Info().Msg("Cluster-wide resources scan finished")
+
+	return fmt.Errorf("cannot list %s in namespace %s: %w", gvr.String(), nsName, err)
+}
+
+// withScope adds the resource type to the log event, together with the
+// namespace of the namespaced resources.
+func withScope(event *zerolog.Event, gvr schema.GroupVersionResource, nsName string) *zerolog.Event {
+	event = event.Str("gvr", gvr.String())
+	if nsName != "" {
+		event = event.Str("ns", nsName)
+	}
+
+	return event
+}
+
+// evaluateResource evaluates the policies against the resource and adds their
+// results to its report with addResult, e.g. report.AddResultToPolicyReport.
+// The results are not added when the scan is cancelled while evaluating the
+// policies, since they are not reliable.
+func (s *Scanner) evaluateResource(ctx context.Context, policies []*policies.Policy, gvr schema.GroupVersionResource, resource unstructured.Unstructured, addResult func(policy policiesv1.Policy, admissionReview *admissionv1.AdmissionReview, errored bool) *wgpolicy.PolicyReportResult) error {
+	auditResults, err := s.auditPolicies(ctx, policies, gvr, resource)
+	if err != nil {
+		return err
+	}
+	if ctx.Err() != nil {
+		// the scan has been cancelled while evaluating the policies, the
+		// results are not reliable: keep the report of the previous scan
+		return ctx.Err()
+	}
+
+	for _, res := range auditResults {
+		if !s.severityFilter.Includes(res.policy) {
+			continue
+		}
+		result := addResult(res.policy, res.admissionReviewResponse, res.errored)
+		if res.err != nil {
+			report.SetEvaluationError(result, res.err)
+		}
+		// the verdicts not coming from the Policy Server have no timing
+		if s.recordTimings && res.duration > 0 {
+			report.SetEvaluationDuration(result, res.duration)
+		}
+		s.metrics.PolicyEvaluated(string(result.Result))
+	}
+	s.metrics.ResourceScanned()
 
 	return nil
 }
@@ -309,90 +744,41 @@ type policyAuditResult struct {
 	policy                  policiesv1.Policy
 	admissionReviewResponse *admissionv1.AdmissionReview
 	errored                 bool
+	// err is the error that prevented the evaluation of the policy, if any
+	err error
+	// duration is the round-trip time of the request to the Policy Server, 0
+	// when no request was sent, e.g. because the verdict was cached
+	duration time.Duration
 }
 
-//gocognit:ignore
-func (s *Scanner) auditResource(ctx context.Context, policies []*policies.Policy, resource unstructured.Unstructured, runUID string, skippedPoliciesNum, erroredPoliciesNum int) error {
+func (s *Scanner) auditResource(ctx context.Context, policies []*policies.Policy, gvr schema.GroupVersionResource, resource unstructured.Unstructured, runUID string, skippedPoliciesNum, erroredPoliciesNum int) error {
+	ctx, span := tracing.Tracer().Start(ctx, "auditResource", trace.WithAttributes(resourceAttributes(gvr, resource)...))
+	defer span.End()
+
+	if owner := s.skippedOwner(resource); owner != nil {
+		log.Debug().Dict("dict", zerolog.Dict().
+			Str("resource", resource.GetName()).
+			Str("namespace", resource.GetNamespace()).
+			Str("owner-kind", owner.Kind).
+			Str("owner-name", owner.Name),
+		).Msg("resource controlled by another one, skipping it")
+		return nil
+	}
+
 	log.Info().Str("resource", resource.GetName()).
 		Dict("dict", zerolog.Dict().
 			Int("policies-to-evaluate", len(policies)).
 			Int("parallel-policies-audit", s.parallelPoliciesAudits),
 		).Msg("audit resource")
 
-	semaphore := semaphore.NewWeighted(int64(s.parallelPoliciesAudits))
-	var workers sync.WaitGroup
-	auditResults := make(chan policyAuditResult, len(policies))
-
-	for _, policyToUse := range policies {
-		err := semaphore.Acquire(ctx, 1)
-		if err != nil {
-			return err
-		}
-		workers.Add(1)
-
-		url := policyToUse.PolicyServer
-		policy := policyToUse.Policy
-
-		go func() {
-			defer semaphore.Release(1)
-			defer workers.Done()
-
-			matches, err := policyMatches(policy, resource)
-			if err != nil {
-				log.Error().Err(err).Msg("error matching policy to resource")
-			}
-
-			if !matches {
-				return
-			}
-
-			admissionReviewRequest := newAdmissionReview(resource)
-			admissionReviewResponse, responseErr := s.sendAdmissionReviewToPolicyServer(ctx, url, admissionReviewRequest)
-			errored := false
-
-			if responseErr != nil {
-				errored = true
-				// log responseErr, will end in PolicyReportResult too
-				log.Error().Err(responseErr).Dict("response", zerolog.Dict().
-					Str("admissionRequest-name", admissionReviewRequest.Request.Name).
-					Str("policy", policy.GetName()).
-					Str("resource", resource.GetName()),
-				).Msg("error sending AdmissionReview to PolicyServer")
-			} else if admissionReviewResponse.Response.Result != nil &&
-				admissionReviewResponse.Response.Result.Code == 500 {
-				errored = true
-				// log Result.Message, will end in PolicyReportResult too
-				log.Error().Err(errors.New(admissionReviewResponse.Response.Result.Message)).Dict("response", zerolog.Dict().
-					Str("admissionRequest-name", admissionReviewRequest.Request.Name).
-					Str("policy", policy.GetName()).
-					Str("resource", resource.GetName()),
-				).Msg("error evaluating Policy in PolicyServer")
-			}
-
-			if !errored {
-				log.Debug().Dict("response", zerolog.Dict().
-					Str("uid", string(admissionReviewResponse.Response.UID)).
-					Str("policy", policy.GetName()).
-					Str("resource", resource.GetName()).
-					Bool("allowed", admissionReviewResponse.Response.Allowed),
-				).Msg("audit review response")
-			}
-
-			auditResults <- policyAuditResult{
-				policy,
-				admissionReviewResponse,
-				errored,
-			}
-		}()
-	}
-	workers.Wait()
-	close(auditResults)
-
 	policyReport := report.NewPolicyReport(runUID, resource)
 	policyReport.Summary.Skip = skippedPoliciesNum
 	policyReport.Summary.Error = erroredPoliciesNum
-	for res := range auditResults {
-		report.AddResultToPolicyReport(policyReport, res.policy, res.admissionReviewResponse, res.errored)
+	err := s.evaluateResource(ctx, policies, gvr, resource, func(policy policiesv1.Policy, admissionReview *admissionv1.AdmissionReview, errored bool) *wgpolicy.PolicyReportResult {
+		return report.AddResultToPolicyReport(policyReport, policy, admissionReview, errored)
+	})
+	if err != nil {
+		return err
 	}
 
 	if s.outputScan {
@@ -404,75 +790,46 @@ func (s *Scanner) auditResource(ctx context.Context, policies []*policies.Policy
 		log.Info().RawJSON("report", policyReportJSON).Msg("PolicyReport summary")
 	}
 
+	s.policyReportStore.RecordPolicyReport(policyReport)
 	if !s.disableStore {
 		err := s.policyReportStore.CreateOrPatchPolicyReport(ctx, policyReport)
 		if err != nil {
 			log.Error().Err(err).Msg("error adding PolicyReport to store.")
+			s.errors.add(ScanError{Kind: ScanErrorSaveReport, Namespace: resource.GetNamespace(), GVR: gvr.String(), Resource: resource.GetName(), Message: err.Error()})
 		}
 	}
 
 	return nil
 }
 
-func (s *Scanner) auditClusterResource(ctx context.Context, policies []*policies.Policy, resource unstructured.Unstructured, runUID string, skippedPoliciesNum, erroredPoliciesNum int) {
+func (s *Scanner) auditClusterResource(ctx context.Context, policies []*policies.Policy, gvr schema.GroupVersionResource, resource unstructured.Unstructured, runUID string, skippedPoliciesNum, erroredPoliciesNum int) error {
+	ctx, span := tracing.Tracer().Start(ctx, "auditClusterResource", trace.WithAttributes(resourceAttributes(gvr, resource)...))
+	defer span.End()
+
+	if owner := s.skippedOwner(resource); owner != nil {
+		log.Debug().Dict("dict", zerolog.Dict().
+			Str("resource", resource.GetName()).
+			Str("owner-kind", owner.Kind).
+			Str("owner-name", owner.Name),
+		).Msg("clusterwide resource controlled by another one, skipping it")
+		return nil
+	}
+
 	log.Info().
 		Str("resource", resource.GetName()).
 		Dict("dict", zerolog.Dict().
-			Int("policies-to-evaluate", len(policies)),
+			Int("policies-to-evaluate", len(policies)).
+			Int("parallel-policies-audit", s.parallelPoliciesAudits),
 		).Msg("audit clusterwide resource")
 
 	clusterPolicyReport := report.NewClusterPolicyReport(runUID, resource)
 	clusterPolicyReport.Summary.Skip = skippedPoliciesNum
 	clusterPolicyReport.Summary.Error = erroredPoliciesNum
-	for _, p := range policies {
-		url := p.PolicyServer
-		policy := p.Policy
-
-		matches, err := policyMatches(policy, resource)
-		if err != nil {
-			log.Error().Err(err).Msg("error matching policy to resource")
-		}
-
-		if !matches {
-			continue
-		}
-
-		admissionReviewRequest := newAdmissionReview(resource)
-		admissionReviewResponse, responseErr := s.sendAdmissionReviewToPolicyServer(ctx, url, admissionReviewRequest)
-		errored := false
-
-		if responseErr != nil {
-			errored = true
-			// log error, will end in ClusterPolicyReportResult too
-			log.Error().Err(responseErr).Dict("response", zerolog.Dict().
-				Str("admissionRequest name", admissionReviewRequest.Request.Name).
-				Str("policy", policy.GetName()).
-				Str("resource", resource.GetName()),
-			).
-				Msg("error sending AdmissionReview to PolicyServer")
-		} else if admissionReviewResponse.Response.Result != nil &&
-			admissionReviewResponse.Response.Result.Code == 500 {
-			errored = true
-			// log Result.Message, will end in PolicyReportResult too
-			log.Error().Err(errors.New(admissionReviewResponse.Response.Result.Message)).Dict("response", zerolog.Dict().
-				Str("admissionRequest-name", admissionReviewRequest.Request.Name).
-				Str("policy", policy.GetName()).
-				Str("resource", resource.GetName()),
-			).
-				Msg("error evaluating Policy in PolicyServer")
-		}
-
-		if !errored {
-			log.Debug().Dict("response", zerolog.Dict().
-				Str("uid", string(admissionReviewResponse.Response.UID)).
-				Str("policy", policy.GetName()).
-				Str("resource", resource.GetName()).
-				Bool("allowed", admissionReviewResponse.Response.Allowed),
-			).
-				Msg("audit review response")
-		}
-
-		report.AddResultToClusterPolicyReport(clusterPolicyReport, policy, admissionReviewResponse, errored)
+	err := s.evaluateResource(ctx, policies, gvr, resource, func(policy policiesv1.Policy, admissionReview *admissionv1.AdmissionReview, errored bool) *wgpolicy.PolicyReportResult {
+		return report.AddResultToClusterPolicyReport(clusterPolicyReport, policy, admissionReview, errored)
+	})
+	if err != nil {
+		return err
 	}
 
 	if s.outputScan {
@@ -486,63 +843,230 @@ func (s *Scanner) auditClusterResource(ctx context.Context, policies []*policies
 			Msg("ClusterPolicyReport summary")
 	}
 
+	s.policyReportStore.RecordClusterPolicyReport(clusterPolicyReport)
 	if !s.disableStore {
 		err := s.policyReportStore.CreateOrPatchClusterPolicyReport(ctx, clusterPolicyReport)
 		if err != nil {
 			log.Error().Err(err).Msg("error adding ClusterPolicyReport to store")
+			s.errors.add(ScanError{Kind: ScanErrorSaveReport, GVR: gvr.String(), Resource: resource.GetName(), Message: err.Error()})
 		}
 	}
+	if s.mirrorNamespace != "" && !s.disableStore {
+		// the mirror isn't recorded, its results are already counted by the
+		// summary of the scan through the ClusterPolicyReport
+		mirroredPolicyReport := report.NewMirroredPolicyReport(clusterPolicyReport, s.mirrorNamespace)
+		if err := s.policyReportStore.CreateOrPatchPolicyReport(ctx, mirroredPolicyReport); err != nil {
+			log.Error().Err(err).Str("namespace", s.mirrorNamespace).Msg("error adding mirrored PolicyReport to store")
+			s.errors.add(ScanError{Kind: ScanErrorSaveReport, GVR: gvr.String(), Resource: resource.GetName(), Message: err.Error()})
+		}
+	}
+
+	return nil
 }
 
-func policyMatches(policy policiesv1.Policy, resource unstructured.Unstructured) (bool, error) {
-	if policy.GetObjectSelector() == nil {
-		return true, nil
+// auditPolicies evaluates the given policies against a resource, running up to
+// parallelPoliciesAudits evaluations at the same time.
+// The returned results follow the order of the given policies, regardless of
+// the order in which the evaluations complete. Policies that don't match the
+// resource are omitted. A panic while evaluating a policy is recovered and
+// reported as an errored result, so it doesn't abort the audit of the resource.
+// Once ctx is cancelled no other policy is evaluated and ctx.Err() is returned.
+func (s *Scanner) auditPolicies(ctx context.Context, policies []*policies.Policy, gvr schema.GroupVersionResource, resource unstructured.Unstructured) ([]policyAuditResult, error) {
+	semaphore := semaphore.NewWeighted(int64(s.parallelPoliciesAudits))
+	var workers sync.WaitGroup
+	// every worker writes only into its own slot, so no locking is needed
+	auditResults := make([]*policyAuditResult, len(policies))
+	payload := newAdmissionReviewPayload(resource, s.admission, s.redactions)
+	contentHash := newResourceContentHash(resource, s.redactions)
+
+	for i, policyToUse := range policies {
+		if err := ctx.Err(); err != nil {
+			// don't send more requests to the Policy Servers once cancelled
+			workers.Wait()
+			return nil, err
+		}
+		err := semaphore.Acquire(ctx, 1)
+		if err != nil {
+			workers.Wait()
+			return nil, err
+		}
+		workers.Add(1)
+
+		go func() {
+			defer semaphore.Release(1)
+			defer workers.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					log.Error().Dict("dict", zerolog.Dict().
+						Str("resource", resource.GetName()).
+						Str("panic", fmt.Sprint(r)),
+					).Msg("recovered from panic while auditing resource")
+					auditResults[i] = &policyAuditResult{
+						policy:  policyToUse.Policy,
+						errored: true,
+						err:     fmt.Errorf("panic while auditing resource: %v", r),
+					}
+				}
+			}()
+
+			auditResults[i] = s.auditPolicy(ctx, policyToUse, gvr, resource, payload, contentHash)
+		}()
 	}
+	workers.Wait()
 
-	selector, err := metav1.LabelSelectorAsSelector(policy.GetObjectSelector())
+	results := make([]policyAuditResult, 0, len(auditResults))
+	for _, auditResult := range auditResults {
+		if auditResult != nil {
+			results = append(results, *auditResult)
+		}
+	}
+
+	return results, nil
+}
+
+// auditPolicy evaluates a single policy against a resource.
+// payload returns the serialized AdmissionReview of the resource, contentHash
+// the hash of its content, used to look up the verdict cache.
+// Returns nil if the policy doesn't match the resource.
+func (s *Scanner) auditPolicy(ctx context.Context, policyToUse *policies.Policy, gvr schema.GroupVersionResource, resource unstructured.Unstructured, payload func() ([]byte, error), contentHash func() (string, error)) *policyAuditResult {
+	url := policyToUse.PolicyServer
+	policy := policyToUse.Policy
+
+	matches, err := policyMatches(policy, gvr, resource, s.admission.Operation)
 	if err != nil {
-		log.Error().Err(err).Msg("error creating label selector from policy")
+		log.Error().Err(err).Msg("error matching policy to resource")
+		s.errors.add(newResourceScanError(ScanErrorPolicyMatch, gvr, resource, policy, err.Error()))
+	}
 
-		return false, err
+	if !matches {
+		return nil
 	}
 
-	labels := labels.Set(resource.GetLabels())
-	if !selector.Matches(labels) {
-		return false, nil
+	ctx, span := tracing.Tracer().Start(ctx, "admissionReview", trace.WithAttributes(
+		append(resourceAttributes(gvr, resource), attribute.String("policy", policy.GetUniqueName()))...,
+	))
+	defer span.End()
+
+	var admissionReviewResponse *admissionv1.AdmissionReview
+	var duration time.Duration
+	admissionReviewPayload, responseErr := payload()
+	if responseErr == nil && s.maxResourceBytes > 0 && len(admissionReviewPayload) > s.maxResourceBytes {
+		// the request would be rejected by the Policy Server, or time out
+		responseErr = fmt.Errorf("%w: the AdmissionReview is %d bytes, more than the maximum of %d bytes, it was not sent to the PolicyServer",
+			errResourceTooLarge, len(admissionReviewPayload), s.maxResourceBytes)
+	}
+	if responseErr == nil {
+		admissionReviewResponse, duration, responseErr = s.evaluatePolicy(ctx, url, policy, resource, admissionReviewPayload, contentHash)
+	}
+	errored := false
+
+	if responseErr != nil {
+		errored = true
+		// log responseErr, will end in PolicyReportResult too
+		log.Error().Err(responseErr).Dict("response", zerolog.Dict().
+			Str("admissionRequest-name", resource.GetName()).
+			Str("policy", policy.GetName()).
+			Str("resource", resource.GetName()),
+		).Msg("error sending AdmissionReview to PolicyServer")
+		errorKind := ScanErrorPolicyServer
+		if errors.Is(responseErr, errResourceTooLarge) {
+			errorKind = ScanErrorResourceTooLarge
+		}
+		s.errors.add(newResourceScanError(errorKind, gvr, resource, policy, responseErr.Error()))
+	} else if admissionReviewResponse.Response.Result != nil &&
+		admissionReviewResponse.Response.Result.Code == 500 {
+		errored = true
+		// log Result.Message, will end in PolicyReportResult too
+		log.Error().Err(errors.New(admissionReviewResponse.Response.Result.Message)).Dict("response", zerolog.Dict().
+			Str("admissionRequest-name", resource.GetName()).
+			Str("policy", policy.GetName()).
+			Str("resource", resource.GetName()),
+		).Msg("error evaluating Policy in PolicyServer")
+		s.errors.add(newResourceScanError(ScanErrorPolicyServer, gvr, resource, policy, admissionReviewResponse.Response.Result.Message))
 	}
 
-	return true, nil
+	if errored {
+		span.SetStatus(codes.Error, "policy evaluation failed")
+		if responseErr != nil {
+			span.RecordError(responseErr)
+		}
+	} else {
+		span.SetAttributes(attribute.Bool("allowed", admissionReviewResponse.Response.Allowed))
+		log.Debug().Dict("response", zerolog.Dict().
+			Str("uid", string(admissionReviewResponse.Response.UID)).
+			Str("policy", policy.GetName()).
+			Str("resource", resource.GetName()).
+			Bool("allowed", admissionReviewResponse.Response.Allowed),
+		).Msg("audit review response")
+	}
+
+	return &policyAuditResult{
+		policy:                  policy,
+		admissionReviewResponse: admissionReviewResponse,
+		errored:                 errored,
+		err:                     responseErr,
+		duration:                duration,
+	}
 }
 
-func (s *Scanner) sendAdmissionReviewToPolicyServer(ctx context.Context, url *url.URL, admissionRequest *admissionv1.AdmissionReview) (*admissionv1.AdmissionReview, error) {
-	payload, err := json.Marshal(admissionRequest)
-	if err != nil {
-		return nil, err
+// skippedOwner returns the controller of the resource when the resources it
+// controls are skipped, e.g. the ReplicaSet of a Pod: the template of the
+// controller is audited instead. It returns nil when the resource is audited.
+func (s *Scanner) skippedOwner(resource unstructured.Unstructured) *metav1.OwnerReference {
+	if !s.skipOwnedResources {
+		return nil
+	}
+	owner := metav1.GetControllerOfNoCopy(&resource)
+	if owner == nil {
+		return nil
+	}
+	if len(s.skipOwnerKinds) > 0 && !slices.Contains(s.skipOwnerKinds, owner.Kind) {
+		return nil
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url.String(), bytes.NewBuffer(payload))
-	if err != nil {
-		return nil, err
+	return owner
+}
+
+// resourceListOptions returns the options used to list the resources to be audited.
+func (s *Scanner) resourceListOptions() metav1.ListOptions {
+	return metav1.ListOptions{
+		LabelSelector: s.resourceSelector.String(),
+		FieldSelector: s.fieldSelector.String(),
 	}
-	req.Header.Add("Content-Type", "application/json")
+}
 
-	res, err := s.httpClient.Do(req)
-	if err != nil {
-		return nil, err
+// resourceAttributes returns the span attributes identifying a resource.
+func resourceAttributes(gvr schema.GroupVersionResource, resource unstructured.Unstructured) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("resource.gvr", gvr.String()),
+		attribute.String("resource.namespace", resource.GetNamespace()),
+		attribute.String("resource.name", resource.GetName()),
 	}
-	defer res.Body.Close()
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		return nil, fmt.Errorf("cannot read body of response: %w", err)
+}
+
+// policyMatches returns true if the policy has to be evaluated against the resource.
+// The resource must be targeted by the rules of the policy, with the configured audit operation,
+// and its labels must match the object selector of the policy.
+func policyMatches(policy policiesv1.Policy, gvr schema.GroupVersionResource, resource unstructured.Unstructured, operation admissionv1.Operation) (bool, error) {
+	if !policies.RulesMatch(policy.GetRules(), gvr, admissionregistrationv1.OperationType(operation)) {
+		return false, nil
 	}
-	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d body: %s", res.StatusCode, body)
+
+	if policy.GetObjectSelector() == nil {
+		return true, nil
 	}
 
-	admissionReview := admissionv1.AdmissionReview{}
-	err = json.Unmarshal(body, &admissionReview)
+	selector, err := metav1.LabelSelectorAsSelector(policy.GetObjectSelector())
 	if err != nil {
-		return nil, fmt.Errorf("cannot deserialize the audit review response: %w", err)
+		log.Error().Err(err).Msg("error creating label selector from policy")
+
+		return false, err
 	}
-	return &admissionReview, nil
+
+	labels := labels.Set(resource.GetLabels())
+	if !selector.Matches(labels) {
+		return false, nil
+	}
+
+	return true, nil
 }
@@ -5,9 +5,17 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	auditConstants "github.com/kubewarden/audit-scanner/internal/constants"
@@ -20,16 +28,26 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	admissionv1 "k8s.io/api/admission/v1"
 	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apimachineryErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	dynamicFake "k8s.io/client-go/dynamic/fake"
 	"k8s.io/client-go/kubernetes/fake"
-	"k8s.io/client-go/kubernetes/scheme"
+	clienttesting "k8s.io/client-go/testing"
 	wgpolicy "sigs.k8s.io/wg-policy-prototypes/policy-report/pkg/api/wgpolicyk8s.io/v1alpha2"
 )
 
@@ -56,31 +74,11 @@ func newTestConfig(policiesClient *policies.Client, k8sClient *k8s.Client, polic
 }
 
 func newMockPolicyServer() *httptest.Server {
-	return httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
-		writer.WriteHeader(http.StatusOK)
-
-		admissionReview := admissionv1.AdmissionReview{
-			Response: &admissionv1.AdmissionResponse{
-				Allowed: true,
-				Result:  nil,
-			},
-		}
-		response, err := json.Marshal(admissionReview)
-		if err != nil {
-			writer.WriteHeader(http.StatusInternalServerError)
-		}
-
-		_, err = writer.Write(response)
-		if err != nil {
-			writer.WriteHeader(http.StatusInternalServerError)
-		}
-	}))
+	return testutils.NewFakePolicyServer(testutils.Allow()).Server
 }
 
 func newMockPolicyServerWithErrors() *httptest.Server {
-	return httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
-		writer.WriteHeader(http.StatusBadGateway)
-	}))
+	return testutils.NewFakePolicyServer(testutils.Fail(http.StatusBadGateway, "")).Server
 }
 
 func newMockPolicyServerWithMTLS(caCert, serverCert, serverKey []byte) *httptest.Server {
@@ -133,6 +131,35 @@ func newMockPolicyServerWithMTLS(caCert, serverCert, serverKey []byte) *httptest
 	return server
 }
 
+// newDefaultPolicyServer returns the "default" PolicyServer and its Service.
+func newDefaultPolicyServer() (*policiesv1.PolicyServer, *corev1.Service) {
+	policyServer := &policiesv1.PolicyServer{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+	}
+
+	policyServerService := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				"app": "kubewarden-policy-server-default",
+			},
+			Name:      "policy-server-default",
+			Namespace: "kubewarden",
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{
+					Name: "http",
+					Port: 443,
+				},
+			},
+		},
+	}
+
+	return policyServer, policyServerService
+}
+
 func TestScanAllNamespaces(t *testing.T) {
 	mockPolicyServer := newMockPolicyServer()
 	defer mockPolicyServer.Close()
@@ -561,8 +588,10 @@ func TestScanClusterWideResources(t *testing.T) {
 		RunUID(oldClusterPolicyReportRunUID).
 		Build()
 
+	auditScheme, err := auditscheme.NewScheme()
+	require.NoError(t, err)
 	dynamicClient := dynamicFake.NewSimpleDynamicClient(
-		scheme.Scheme,
+		auditScheme,
 		namespace1,
 		namespace2,
 		oldClusterPolicyReport,
@@ -853,3 +882,2314 @@ func TestScanWithMTLS(t *testing.T) {
 	assert.Equal(t, 0, podPolicyReport.Summary.Skip)
 	assert.Len(t, podPolicyReport.Results, 1)
 }
+
+func TestNewScannerWithIncompleteClientCertificate(t *testing.T) {
+	caCertPEM, caKeyPEM, err := testutils.GenerateTestCA()
+	require.NoError(t, err)
+	clientCertPEM, clientKeyPEM, err := testutils.GenerateTestCert(caCertPEM, caKeyPEM, "client")
+	require.NoError(t, err)
+	clientCertFile, err := testutils.WriteTempFile(clientCertPEM)
+	require.NoError(t, err)
+	clientKeyFile, err := testutils.WriteTempFile(clientKeyPEM)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		tls  TLSConfig
+	}{
+		{"only the client certificate", TLSConfig{ClientCertFile: clientCertFile}},
+		{"only the client key", TLSConfig{ClientKeyFile: clientKeyFile}},
+		{"mismatching certificate and key", TLSConfig{ClientCertFile: clientKeyFile, ClientKeyFile: clientCertFile}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := newTestConfig(nil, nil, nil)
+			config.TLS = test.tls
+
+			_, err := NewScanner(config)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestAuditPoliciesPreservesOrder(t *testing.T) {
+	// the policy server answers slower to the first policies, so that
+	// the evaluations complete in reverse order
+	policyNames := []string{"policy1", "policy2", "policy3", "policy4"}
+	mockPolicyServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, r *http.Request) {
+		for i, name := range policyNames {
+			if r.URL.Path == "/audit/"+name {
+				time.Sleep(time.Duration(len(policyNames)-i) * 20 * time.Millisecond)
+			}
+		}
+
+		response, err := json.Marshal(admissionv1.AdmissionReview{
+			Response: &admissionv1.AdmissionResponse{Allowed: true},
+		})
+		if err != nil {
+			writer.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = writer.Write(response)
+	}))
+	defer mockPolicyServer.Close()
+
+	policiesToAudit := []*policies.Policy{}
+	for _, name := range policyNames {
+		policyServerURL, err := url.Parse(mockPolicyServer.URL + "/audit/" + name)
+		require.NoError(t, err)
+
+		policiesToAudit = append(policiesToAudit, &policies.Policy{
+			Policy: testutils.NewClusterAdmissionPolicyFactory().
+				Name(name).
+				Rule(admissionregistrationv1.Rule{
+					APIGroups:   []string{""},
+					APIVersions: []string{"v1"},
+					Resources:   []string{"pods"},
+				}).
+				Build(),
+			PolicyServer: policyServerURL,
+		})
+	}
+	// a policy that makes the evaluation panic, it must be reported as errored
+	policiesToAudit = append(policiesToAudit, &policies.Policy{})
+
+	config := newTestConfig(nil, nil, nil)
+	config.Parallelization.PoliciesAudits = len(policiesToAudit)
+	scanner, err := NewScanner(config)
+	require.NoError(t, err)
+
+	resource := unstructured.Unstructured{}
+	resource.SetName("pod")
+
+	gvr := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+	auditResults, err := scanner.auditPolicies(context.Background(), policiesToAudit, gvr, resource)
+	require.NoError(t, err)
+	require.Len(t, auditResults, len(policiesToAudit))
+
+	for i, name := range policyNames {
+		assert.Equal(t, name, auditResults[i].policy.GetName())
+		assert.False(t, auditResults[i].errored)
+	}
+	assert.True(t, auditResults[len(policyNames)].errored)
+}
+
+func BenchmarkScanNamespace(b *testing.B) {
+	const resourcesNum = 1000
+
+	// simulate the latency of a policy evaluation
+	mockPolicyServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		time.Sleep(time.Millisecond)
+
+		response, err := json.Marshal(admissionv1.AdmissionReview{
+			Response: &admissionv1.AdmissionResponse{Allowed: true},
+		})
+		if err != nil {
+			writer.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = writer.Write(response)
+	}))
+	defer mockPolicyServer.Close()
+
+	policyServer, policyServerService := newDefaultPolicyServer()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "namespace",
+		},
+	}
+
+	admissionPolicy := testutils.
+		NewAdmissionPolicyFactory().
+		Name("admissionPolicy").
+		Namespace("namespace").
+		Rule(admissionregistrationv1.Rule{
+			APIGroups:   []string{""},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"pods"},
+		}).
+		Status(policiesv1.PolicyStatusActive).
+		Build()
+
+	pods := []runtime.Object{namespace}
+	for i := range resourcesNum {
+		pods = append(pods, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("pod-%d", i),
+				Namespace: "namespace",
+				UID:       types.UID(fmt.Sprintf("pod-%d-uid", i)),
+			},
+		})
+	}
+
+	for _, parallelResources := range []int{1, 10, 100} {
+		b.Run(fmt.Sprintf("parallel-resources=%d", parallelResources), func(b *testing.B) {
+			auditScheme, err := auditscheme.NewScheme()
+			require.NoError(b, err)
+			dynamicClient := dynamicFake.NewSimpleDynamicClient(auditScheme, pods...)
+			clientset := fake.NewSimpleClientset(namespace)
+			client, err := testutils.NewFakeClient(
+				namespace,
+				policyServer,
+				policyServerService,
+				admissionPolicy,
+			)
+			require.NoError(b, err)
+
+			k8sClient, err := k8s.NewClient(dynamicClient, clientset, "kubewarden", nil, pageSize)
+			require.NoError(b, err)
+			policiesClient, err := policies.NewClient(client, "kubewarden", mockPolicyServer.URL)
+			require.NoError(b, err)
+
+			config := newTestConfig(policiesClient, k8sClient, report.NewPolicyReportStore(client))
+			config.Parallelization.ParallelResourcesAudits = parallelResources
+			scanner, err := NewScanner(config)
+			require.NoError(b, err)
+
+			b.ResetTimer()
+			for range b.N {
+				err = scanner.ScanNamespace(context.Background(), "namespace", uuid.New().String())
+				require.NoError(b, err)
+			}
+		})
+	}
+}
+
+func TestScanAllNamespacesWithNamespaceSelector(t *testing.T) {
+	mockPolicyServer := newMockPolicyServer()
+	defer mockPolicyServer.Close()
+
+	policyServer, policyServerService := newDefaultPolicyServer()
+
+	prodNamespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "prod",
+			Labels: map[string]string{"env": "prod"},
+		},
+	}
+
+	devNamespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "dev",
+			Labels: map[string]string{"env": "dev"},
+		},
+	}
+
+	prodPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod",
+			Namespace: "prod",
+			UID:       "prod-pod-uid",
+		},
+	}
+
+	devPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod",
+			Namespace: "dev",
+			UID:       "dev-pod-uid",
+		},
+	}
+
+	// a ClusterAdmissionPolicy targeting pods only in the prod namespaces
+	clusterAdmissionPolicy := testutils.
+		NewClusterAdmissionPolicyFactory().
+		Name("clusterAdmissionPolicy").
+		NamespaceSelector(&metav1.LabelSelector{
+			MatchLabels: map[string]string{"env": "prod"},
+		}).
+		Rule(admissionregistrationv1.Rule{
+			APIGroups:   []string{""},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"pods"},
+		}).
+		Status(policiesv1.PolicyStatusActive).
+		Build()
+
+	auditScheme, err := auditscheme.NewScheme()
+	require.NoError(t, err)
+	dynamicClient := dynamicFake.NewSimpleDynamicClient(auditScheme, prodPod, devPod)
+	clientset := fake.NewSimpleClientset(prodNamespace, devNamespace)
+	client, err := testutils.NewFakeClient(
+		prodNamespace,
+		devNamespace,
+		policyServer,
+		policyServerService,
+		clusterAdmissionPolicy,
+	)
+	require.NoError(t, err)
+
+	k8sClient, err := k8s.NewClient(dynamicClient, clientset, "kubewarden", nil, pageSize)
+	require.NoError(t, err)
+
+	policiesClient, err := policies.NewClient(client, "kubewarden", mockPolicyServer.URL)
+	require.NoError(t, err)
+
+	config := newTestConfig(policiesClient, k8sClient, report.NewPolicyReportStore(client))
+	scanner, err := NewScanner(config)
+	require.NoError(t, err)
+
+	err = scanner.ScanAllNamespaces(context.Background(), uuid.New().String())
+	require.NoError(t, err)
+
+	policyReport := wgpolicy.PolicyReport{}
+	err = client.Get(context.TODO(), types.NamespacedName{Name: string(prodPod.GetUID()), Namespace: "prod"}, &policyReport)
+	require.NoError(t, err)
+	assert.Equal(t, 1, policyReport.Summary.Pass)
+	assert.Len(t, policyReport.Results, 1)
+
+	// the policy doesn't select the dev namespace, hence the pod is not audited
+	err = client.Get(context.TODO(), types.NamespacedName{Name: string(devPod.GetUID()), Namespace: "dev"}, &policyReport)
+	require.True(t, apimachineryErrors.IsNotFound(err))
+}
+
+func TestPolicyMatches(t *testing.T) {
+	podsGVR := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+	deploymentsGVR := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+
+	resource := unstructured.Unstructured{}
+	resource.SetLabels(map[string]string{"env": "test"})
+
+	tests := []struct {
+		name     string
+		policy   policiesv1.Policy
+		gvr      schema.GroupVersionResource
+		expected bool
+	}{
+		{
+			"rule targets the resource",
+			testutils.NewClusterAdmissionPolicyFactory().
+				Rule(admissionregistrationv1.Rule{APIGroups: []string{""}, APIVersions: []string{"v1"}, Resources: []string{"pods"}}).
+				Build(),
+			podsGVR,
+			true,
+		},
+		{
+			"rule targets another resource",
+			testutils.NewClusterAdmissionPolicyFactory().
+				Rule(admissionregistrationv1.Rule{APIGroups: []string{"apps"}, APIVersions: []string{"v1"}, Resources: []string{"deployments"}}).
+				Build(),
+			podsGVR,
+			false,
+		},
+		{
+			"rule with wildcards",
+			testutils.NewClusterAdmissionPolicyFactory().
+				Rule(admissionregistrationv1.Rule{APIGroups: []string{"*"}, APIVersions: []string{"*"}, Resources: []string{"*"}}, admissionregistrationv1.OperationAll).
+				Build(),
+			deploymentsGVR,
+			true,
+		},
+		{
+			"rule without CREATE operation",
+			testutils.NewClusterAdmissionPolicyFactory().
+				Rule(admissionregistrationv1.Rule{APIGroups: []string{""}, APIVersions: []string{"v1"}, Resources: []string{"pods"}}, admissionregistrationv1.Update).
+				Build(),
+			podsGVR,
+			false,
+		},
+		{
+			"object selector does not match",
+			testutils.NewClusterAdmissionPolicyFactory().
+				ObjectSelector(&metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}}).
+				Rule(admissionregistrationv1.Rule{APIGroups: []string{""}, APIVersions: []string{"v1"}, Resources: []string{"pods"}}).
+				Build(),
+			podsGVR,
+			false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matches, err := policyMatches(test.policy, test.gvr, resource, admissionv1.Create)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, matches)
+		})
+	}
+	// the rules are matched against the operation the resources are audited with
+	matches, err := policyMatches(tests[3].policy, podsGVR, resource, admissionv1.Update)
+	require.NoError(t, err)
+	assert.True(t, matches)
+}
+
+func TestAuditPolicyTracing(t *testing.T) {
+	spanRecorder := tracetest.NewSpanRecorder()
+	previousTracerProvider := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previousTracerProvider) })
+
+	mockPolicyServer := newMockPolicyServer()
+	defer mockPolicyServer.Close()
+	policyServerURL, err := url.Parse(mockPolicyServer.URL + "/audit/policy")
+	require.NoError(t, err)
+
+	policy := testutils.NewClusterAdmissionPolicyFactory().
+		Name("policy").
+		Rule(admissionregistrationv1.Rule{
+			APIGroups:   []string{""},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"pods"},
+		}).
+		Build()
+
+	scanner, err := NewScanner(newTestConfig(nil, nil, nil))
+	require.NoError(t, err)
+
+	resource := unstructured.Unstructured{}
+	resource.SetName("pod")
+	resource.SetNamespace("default")
+	gvr := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+
+	result := scanner.auditPolicy(context.Background(), &policies.Policy{Policy: policy, PolicyServer: policyServerURL}, gvr, resource, newAdmissionReviewPayload(resource, AdmissionConfig{}, nil), newResourceContentHash(resource, nil))
+	require.NotNil(t, result)
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range spanRecorder.Ended() {
+		spans[span.Name()] = span
+	}
+	require.Contains(t, spans, "admissionReview")
+	require.Contains(t, spans, "sendAdmissionReviewToPolicyServer")
+
+	admissionReviewSpan := spans["admissionReview"]
+	attributes := map[attribute.Key]attribute.Value{}
+	for _, kv := range admissionReviewSpan.Attributes() {
+		attributes[kv.Key] = kv.Value
+	}
+	assert.Equal(t, policy.GetUniqueName(), attributes["policy"].AsString())
+	assert.Equal(t, gvr.String(), attributes["resource.gvr"].AsString())
+	assert.Equal(t, result.admissionReviewResponse.Response.Allowed, attributes["allowed"].AsBool())
+
+	assert.Equal(t, admissionReviewSpan.SpanContext().SpanID(), spans["sendAdmissionReviewToPolicyServer"].Parent().SpanID())
+}
+
+func TestAuditPolicyResourceTooLarge(t *testing.T) {
+	requests := 0
+	mockPolicyServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		requests++
+		writer.WriteHeader(http.StatusRequestEntityTooLarge)
+	}))
+	defer mockPolicyServer.Close()
+	policyServerURL, err := url.Parse(mockPolicyServer.URL + "/audit/policy")
+	require.NoError(t, err)
+
+	policy := testutils.NewClusterAdmissionPolicyFactory().
+		Name("policy").
+		Rule(admissionregistrationv1.Rule{
+			APIGroups:   []string{""},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"configmaps"},
+		}).
+		Build()
+
+	config := newTestConfig(nil, nil, nil)
+	config.PolicyServer.MaxResourceBytes = 1024
+	config.CollectErrors = true
+	scanner, err := NewScanner(config)
+	require.NoError(t, err)
+
+	resource := unstructured.Unstructured{}
+	resource.SetAPIVersion("v1")
+	resource.SetKind("ConfigMap")
+	resource.SetName("configmap")
+	resource.SetNamespace("default")
+	require.NoError(t, unstructured.SetNestedField(resource.Object, strings.Repeat("x", 2048), "data", "key"))
+	gvr := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "configmaps"}
+
+	result := scanner.auditPolicy(context.Background(), &policies.Policy{Policy: policy, PolicyServer: policyServerURL}, gvr, resource, newAdmissionReviewPayload(resource, AdmissionConfig{}, nil), newResourceContentHash(resource, nil))
+	require.NotNil(t, result)
+	assert.True(t, result.errored)
+	require.ErrorIs(t, result.err, errResourceTooLarge)
+	assert.Contains(t, result.err.Error(), "more than the maximum of 1024 bytes")
+	assert.Equal(t, 0, requests)
+
+	scanErrors := scanner.Errors()
+	require.Len(t, scanErrors, 1)
+	assert.Equal(t, ScanErrorResourceTooLarge, scanErrors[0].Kind)
+}
+
+func TestAuditResourceWithPolicyServerErrors(t *testing.T) {
+	tests := []struct {
+		name            string
+		handler         http.HandlerFunc
+		expectedMessage string
+	}{
+		{
+			name: "timeout",
+			handler: func(writer http.ResponseWriter, r *http.Request) {
+				select {
+				case <-time.After(time.Second):
+					writer.WriteHeader(http.StatusOK)
+				case <-r.Context().Done():
+				}
+			},
+			expectedMessage: "context deadline exceeded",
+		},
+		{
+			name: "server error",
+			handler: func(writer http.ResponseWriter, _ *http.Request) {
+				writer.WriteHeader(http.StatusInternalServerError)
+				fmt.Fprint(writer, "policy not found")
+			},
+			expectedMessage: "unexpected status code: 500 body: policy not found",
+		},
+		{
+			name: "malformed response",
+			handler: func(writer http.ResponseWriter, _ *http.Request) {
+				fmt.Fprint(writer, `{"response": `)
+			},
+			expectedMessage: "cannot deserialize the audit review response",
+		},
+		{
+			name: "missing admission response",
+			handler: func(writer http.ResponseWriter, _ *http.Request) {
+				fmt.Fprint(writer, `{}`)
+			},
+			expectedMessage: "the audit review response has no AdmissionResponse",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockPolicyServer := httptest.NewServer(test.handler)
+			defer mockPolicyServer.Close()
+			policyServerURL, err := url.Parse(mockPolicyServer.URL + "/audit/policy")
+			require.NoError(t, err)
+
+			policy := testutils.NewClusterAdmissionPolicyFactory().
+				Name("policy").
+				Rule(admissionregistrationv1.Rule{
+					APIGroups:   []string{""},
+					APIVersions: []string{"v1"},
+					Resources:   []string{"pods"},
+				}).
+				Build()
+
+			store := report.NewPolicyReportStore(nil, report.WithInMemoryReports())
+			config := newTestConfig(nil, nil, store)
+			config.DisableStore = true
+			config.PolicyServer.Timeout = 50 * time.Millisecond
+			config.PolicyServer.MaxRetries = 0
+			config.CollectErrors = true
+			scanner, err := NewScanner(config)
+			require.NoError(t, err)
+
+			resource := unstructured.Unstructured{}
+			resource.SetName("pod")
+			resource.SetNamespace("default")
+			resource.SetUID("pod-uid")
+			gvr := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+
+			err = scanner.auditResource(context.Background(), []*policies.Policy{{Policy: policy, PolicyServer: policyServerURL}}, gvr, resource, "runUID", 0, 0)
+			require.NoError(t, err)
+
+			policyReports := store.Reports().PolicyReports
+			require.Len(t, policyReports, 1)
+			require.Len(t, policyReports[0].Results, 1)
+			result := policyReports[0].Results[0]
+			// errors are reported apart from the policy violations
+			assert.Equal(t, wgpolicy.PolicyResult("error"), result.Result)
+			assert.Equal(t, 1, policyReports[0].Summary.Error)
+			assert.Equal(t, 0, policyReports[0].Summary.Fail)
+			assert.True(t, strings.HasPrefix(result.Description, "the policy could not be evaluated: "), result.Description)
+			assert.Contains(t, result.Description, test.expectedMessage)
+
+			// the errors are collected besides being logged
+			scanErrors := scanner.Errors()
+			require.Len(t, scanErrors, 1)
+			assert.Equal(t, ScanErrorPolicyServer, scanErrors[0].Kind)
+			assert.Equal(t, "default", scanErrors[0].Namespace)
+			assert.Equal(t, "pod", scanErrors[0].Resource)
+			assert.Equal(t, "clusterwide-policy", scanErrors[0].Policy)
+			assert.Contains(t, scanErrors[0].Message, test.expectedMessage)
+
+			scanner.ResetErrors()
+			assert.Empty(t, scanner.Errors())
+		})
+	}
+}
+
+func TestAuditClusterResourceWithPolicyServerError(t *testing.T) {
+	mockPolicyServer := newMockPolicyServerWithErrors()
+	defer mockPolicyServer.Close()
+	policyServerURL, err := url.Parse(mockPolicyServer.URL + "/audit/policy")
+	require.NoError(t, err)
+
+	policy := testutils.NewClusterAdmissionPolicyFactory().
+		Name("policy").
+		Rule(admissionregistrationv1.Rule{
+			APIGroups:   []string{""},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"namespaces"},
+		}).
+		Build()
+
+	store := report.NewPolicyReportStore(nil, report.WithInMemoryReports())
+	config := newTestConfig(nil, nil, store)
+	config.DisableStore = true
+	scanner, err := NewScanner(config)
+	require.NoError(t, err)
+
+	resource := unstructured.Unstructured{}
+	resource.SetName("namespace")
+	resource.SetUID("namespace-uid")
+	gvr := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "namespaces"}
+
+	err = scanner.auditClusterResource(context.Background(), []*policies.Policy{{Policy: policy, PolicyServer: policyServerURL}}, gvr, resource, "runUID", 0, 0)
+	require.NoError(t, err)
+
+	clusterPolicyReports := store.Reports().ClusterPolicyReports
+	require.Len(t, clusterPolicyReports, 1)
+	require.Len(t, clusterPolicyReports[0].Results, 1)
+	assert.Equal(t, wgpolicy.PolicyResult("error"), clusterPolicyReports[0].Results[0].Result)
+	assert.Equal(t, 1, clusterPolicyReports[0].Summary.Error)
+}
+
+func TestScanAllNamespacesFilteredByLabelSelector(t *testing.T) {
+	mockPolicyServer := newMockPolicyServer()
+	defer mockPolicyServer.Close()
+
+	policyServer, policyServerService := newDefaultPolicyServer()
+
+	paymentsNamespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "payments",
+			Labels: map[string]string{"team": "payments"},
+		},
+	}
+
+	frontendNamespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "frontend",
+			Labels: map[string]string{"team": "frontend"},
+		},
+	}
+
+	paymentsPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod",
+			Namespace: "payments",
+			UID:       "payments-pod-uid",
+		},
+	}
+
+	frontendPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod",
+			Namespace: "frontend",
+			UID:       "frontend-pod-uid",
+		},
+	}
+
+	clusterAdmissionPolicy := testutils.
+		NewClusterAdmissionPolicyFactory().
+		Name("clusterAdmissionPolicy").
+		Rule(admissionregistrationv1.Rule{
+			APIGroups:   []string{""},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"pods"},
+		}).
+		Status(policiesv1.PolicyStatusActive).
+		Build()
+
+	auditScheme, err := auditscheme.NewScheme()
+	require.NoError(t, err)
+	dynamicClient := dynamicFake.NewSimpleDynamicClient(auditScheme, paymentsPod, frontendPod)
+	clientset := fake.NewSimpleClientset(paymentsNamespace, frontendNamespace)
+	client, err := testutils.NewFakeClient(
+		paymentsNamespace,
+		frontendNamespace,
+		policyServer,
+		policyServerService,
+		clusterAdmissionPolicy,
+	)
+	require.NoError(t, err)
+
+	k8sClient, err := k8s.NewClient(dynamicClient, clientset, "kubewarden", nil, pageSize)
+	require.NoError(t, err)
+
+	policiesClient, err := policies.NewClient(client, "kubewarden", mockPolicyServer.URL)
+	require.NoError(t, err)
+
+	store := report.NewPolicyReportStore(client)
+	config := newTestConfig(policiesClient, k8sClient, store)
+	config.NamespaceSelector = labels.SelectorFromSet(labels.Set{"team": "payments"})
+	scanner, err := NewScanner(config)
+	require.NoError(t, err)
+
+	err = scanner.ScanAllNamespaces(context.Background(), uuid.New().String())
+	require.NoError(t, err)
+
+	policyReport := wgpolicy.PolicyReport{}
+	err = client.Get(context.TODO(), types.NamespacedName{Name: string(paymentsPod.GetUID()), Namespace: "payments"}, &policyReport)
+	require.NoError(t, err)
+	assert.Equal(t, 1, policyReport.Summary.Pass)
+
+	// the frontend namespace doesn't match the selector, hence it's not scanned
+	err = client.Get(context.TODO(), types.NamespacedName{Name: string(frontendPod.GetUID()), Namespace: "frontend"}, &policyReport)
+	require.True(t, apimachineryErrors.IsNotFound(err))
+
+	summary := store.ScanSummary()
+	assert.Equal(t, 1, summary.Namespaces)
+	assert.Equal(t, 1, summary.Resources)
+	assert.Equal(t, 1, summary.PolicyEvaluations)
+	assert.Equal(t, 1, summary.Pass)
+}
+
+func TestScanAllNamespacesSkippedNamespaces(t *testing.T) {
+	mockPolicyServer := newMockPolicyServer()
+	defer mockPolicyServer.Close()
+
+	policyServer, policyServerService := newDefaultPolicyServer()
+
+	paymentsNamespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "payments",
+			Labels: map[string]string{"team": "payments"},
+		},
+	}
+
+	frontendNamespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "frontend",
+			Labels: map[string]string{"team": "frontend"},
+		},
+	}
+
+	paymentsPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod",
+			Namespace: "payments",
+			UID:       "payments-pod-uid",
+		},
+	}
+
+	frontendPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod",
+			Namespace: "frontend",
+			UID:       "frontend-pod-uid",
+		},
+	}
+
+	clusterAdmissionPolicy := testutils.
+		NewClusterAdmissionPolicyFactory().
+		Name("clusterAdmissionPolicy").
+		Rule(admissionregistrationv1.Rule{
+			APIGroups:   []string{""},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"pods"},
+		}).
+		Status(policiesv1.PolicyStatusActive).
+		Build()
+
+	auditScheme, err := auditscheme.NewScheme()
+	require.NoError(t, err)
+	dynamicClient := dynamicFake.NewSimpleDynamicClient(auditScheme, paymentsPod, frontendPod)
+	clientset := fake.NewSimpleClientset(paymentsNamespace, frontendNamespace)
+	client, err := testutils.NewFakeClient(
+		paymentsNamespace,
+		frontendNamespace,
+		policyServer,
+		policyServerService,
+		clusterAdmissionPolicy,
+	)
+	require.NoError(t, err)
+
+	// the fake clientset ignores the field selector excluding the skipped
+	// namespaces, hence they must be dropped by the scanner too
+	k8sClient, err := k8s.NewClient(dynamicClient, clientset, "kubewarden", []string{"frontend"}, pageSize)
+	require.NoError(t, err)
+
+	policiesClient, err := policies.NewClient(client, "kubewarden", mockPolicyServer.URL)
+	require.NoError(t, err)
+
+	store := report.NewPolicyReportStore(client)
+	config := newTestConfig(policiesClient, k8sClient, store)
+	scanner, err := NewScanner(config)
+	require.NoError(t, err)
+
+	err = scanner.ScanAllNamespaces(context.Background(), uuid.New().String())
+	require.NoError(t, err)
+
+	policyReport := wgpolicy.PolicyReport{}
+	err = client.Get(context.TODO(), types.NamespacedName{Name: string(paymentsPod.GetUID()), Namespace: "payments"}, &policyReport)
+	require.NoError(t, err)
+	assert.Equal(t, 1, policyReport.Summary.Pass)
+
+	// the frontend namespace is skipped, hence it's not scanned
+	err = client.Get(context.TODO(), types.NamespacedName{Name: string(frontendPod.GetUID()), Namespace: "frontend"}, &policyReport)
+	require.True(t, apimachineryErrors.IsNotFound(err))
+
+	assert.Equal(t, 1, store.ScanSummary().Namespaces)
+}
+
+func TestScanAllNamespacesErrors(t *testing.T) {
+	mockPolicyServer := newMockPolicyServer()
+	defer mockPolicyServer.Close()
+
+	policyServer, policyServerService := newDefaultPolicyServer()
+
+	namespaces := []runtime.Object{}
+	for _, name := range []string{"payments", "frontend", "backend", "storage"} {
+		namespaces = append(namespaces, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}})
+	}
+
+	auditScheme, err := auditscheme.NewScheme()
+	require.NoError(t, err)
+	dynamicClient := dynamicFake.NewSimpleDynamicClient(auditScheme)
+	clientset := fake.NewSimpleClientset(namespaces...)
+	// the namespaces but the payments one cannot be fetched
+	clientset.PrependReactor("get", "namespaces", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		name := action.(clienttesting.GetAction).GetName()
+		if name == "payments" {
+			return false, nil, nil
+		}
+		return true, nil, fmt.Errorf("cannot get namespace %s", name)
+	})
+	client, err := testutils.NewFakeClient(policyServer, policyServerService)
+	require.NoError(t, err)
+
+	k8sClient, err := k8s.NewClient(dynamicClient, clientset, "kubewarden", nil, pageSize)
+	require.NoError(t, err)
+
+	policiesClient, err := policies.NewClient(client, "kubewarden", mockPolicyServer.URL)
+	require.NoError(t, err)
+
+	config := newTestConfig(policiesClient, k8sClient, report.NewPolicyReportStore(client))
+	config.Parallelization.ParallelNamespacesAudits = 4
+	scanner, err := NewScanner(config)
+	require.NoError(t, err)
+
+	// the errors of all the namespaces scanned concurrently are returned
+	err = scanner.ScanAllNamespaces(context.Background(), uuid.New().String())
+	require.Error(t, err)
+	for _, name := range []string{"frontend", "backend", "storage"} {
+		assert.ErrorContains(t, err, "cannot get namespace "+name)
+	}
+	assert.NotContains(t, err.Error(), "payments")
+
+	// the scan fails when the namespaces cannot be listed
+	clientset.PrependReactor("list", "namespaces", func(clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("cannot list namespaces")
+	})
+	err = scanner.ScanAllNamespaces(context.Background(), uuid.New().String())
+	require.ErrorContains(t, err, "cannot list namespaces")
+}
+
+func TestScanAllNamespacesSkipNamespaceRegexes(t *testing.T) {
+	mockPolicyServer := newMockPolicyServer()
+	defer mockPolicyServer.Close()
+
+	policyServer, policyServerService := newDefaultPolicyServer()
+
+	paymentsNamespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "payments",
+			Labels: map[string]string{"team": "payments"},
+		},
+	}
+
+	frontendNamespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "frontend",
+			Labels: map[string]string{"team": "frontend"},
+		},
+	}
+
+	paymentsPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod",
+			Namespace: "payments",
+			UID:       "payments-pod-uid",
+		},
+	}
+
+	frontendPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod",
+			Namespace: "frontend",
+			UID:       "frontend-pod-uid",
+		},
+	}
+
+	clusterAdmissionPolicy := testutils.
+		NewClusterAdmissionPolicyFactory().
+		Name("clusterAdmissionPolicy").
+		Rule(admissionregistrationv1.Rule{
+			APIGroups:   []string{""},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"pods"},
+		}).
+		Status(policiesv1.PolicyStatusActive).
+		Build()
+
+	auditScheme, err := auditscheme.NewScheme()
+	require.NoError(t, err)
+	dynamicClient := dynamicFake.NewSimpleDynamicClient(auditScheme, paymentsPod, frontendPod)
+	clientset := fake.NewSimpleClientset(paymentsNamespace, frontendNamespace)
+	client, err := testutils.NewFakeClient(
+		paymentsNamespace,
+		frontendNamespace,
+		policyServer,
+		policyServerService,
+		clusterAdmissionPolicy,
+	)
+	require.NoError(t, err)
+
+	k8sClient, err := k8s.NewClient(dynamicClient, clientset, "kubewarden", nil, pageSize)
+	require.NoError(t, err)
+
+	policiesClient, err := policies.NewClient(client, "kubewarden", mockPolicyServer.URL)
+	require.NoError(t, err)
+
+	store := report.NewPolicyReportStore(client)
+	config := newTestConfig(policiesClient, k8sClient, store)
+	// the regexes match the whole name, hence "front" doesn't match "frontend"
+	config.SkipNamespaceRegexes = []*regexp.Regexp{regexp.MustCompile("^front$"), regexp.MustCompile("^front.*$")}
+	scanner, err := NewScanner(config)
+	require.NoError(t, err)
+
+	err = scanner.ScanAllNamespaces(context.Background(), uuid.New().String())
+	require.NoError(t, err)
+
+	policyReport := wgpolicy.PolicyReport{}
+	err = client.Get(context.TODO(), types.NamespacedName{Name: string(paymentsPod.GetUID()), Namespace: "payments"}, &policyReport)
+	require.NoError(t, err)
+	assert.Equal(t, 1, policyReport.Summary.Pass)
+
+	// the frontend namespace matches a skip regex, hence it's not scanned
+	err = client.Get(context.TODO(), types.NamespacedName{Name: string(frontendPod.GetUID()), Namespace: "frontend"}, &policyReport)
+	require.True(t, apimachineryErrors.IsNotFound(err))
+
+	assert.Equal(t, 1, store.ScanSummary().Namespaces)
+}
+
+func TestScanAllNamespacesPruneStaleReports(t *testing.T) {
+	mockPolicyServer := newMockPolicyServer()
+	defer mockPolicyServer.Close()
+
+	policyServer, policyServerService := newDefaultPolicyServer()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "namespace",
+		},
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod",
+			Namespace: "namespace",
+			UID:       "pod-uid",
+		},
+	}
+
+	clusterAdmissionPolicy := testutils.
+		NewClusterAdmissionPolicyFactory().
+		Name("clusterAdmissionPolicy").
+		Rule(admissionregistrationv1.Rule{
+			APIGroups:   []string{""},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"pods"},
+		}).
+		Status(policiesv1.PolicyStatusActive).
+		Build()
+
+	// reports left in a namespace that is not scanned anymore
+	staleReport := testutils.NewPolicyReportFactory().Name("stale").Namespace("ignored").RunUID("old-run").WithAppLabel().Build()
+	userReport := testutils.NewPolicyReportFactory().Name("user").Namespace("ignored").Build()
+
+	auditScheme, err := auditscheme.NewScheme()
+	require.NoError(t, err)
+	dynamicClient := dynamicFake.NewSimpleDynamicClient(auditScheme, pod)
+	clientset := fake.NewSimpleClientset(namespace)
+	client, err := testutils.NewFakeClient(
+		namespace,
+		policyServer,
+		policyServerService,
+		clusterAdmissionPolicy,
+		staleReport,
+		userReport,
+	)
+	require.NoError(t, err)
+
+	k8sClient, err := k8s.NewClient(dynamicClient, clientset, "kubewarden", nil, pageSize)
+	require.NoError(t, err)
+
+	policiesClient, err := policies.NewClient(client, "kubewarden", mockPolicyServer.URL)
+	require.NoError(t, err)
+
+	config := newTestConfig(policiesClient, k8sClient, report.NewPolicyReportStore(client))
+	config.PruneStaleReports = true
+	scanner, err := NewScanner(config)
+	require.NoError(t, err)
+
+	err = scanner.ScanAllNamespaces(context.Background(), uuid.New().String())
+	require.NoError(t, err)
+
+	policyReport := wgpolicy.PolicyReport{}
+	err = client.Get(context.TODO(), types.NamespacedName{Name: string(pod.GetUID()), Namespace: "namespace"}, &policyReport)
+	require.NoError(t, err)
+
+	err = client.Get(context.TODO(), types.NamespacedName{Name: "stale", Namespace: "ignored"}, &policyReport)
+	require.True(t, apimachineryErrors.IsNotFound(err))
+
+	// reports not created by the audit scanner are kept
+	err = client.Get(context.TODO(), types.NamespacedName{Name: "user", Namespace: "ignored"}, &policyReport)
+	require.NoError(t, err)
+}
+
+func TestScanClusterWideResourcesMirrorNamespace(t *testing.T) {
+	mockPolicyServer := newMockPolicyServer()
+	defer mockPolicyServer.Close()
+
+	policyServer, policyServerService := newDefaultPolicyServer()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "namespace",
+			UID:  "namespace-uid",
+		},
+	}
+
+	clusterAdmissionPolicy := testutils.
+		NewClusterAdmissionPolicyFactory().
+		Name("clusterAdmissionPolicy").
+		Rule(admissionregistrationv1.Rule{
+			APIGroups:   []string{""},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"namespaces"},
+		}).
+		Status(policiesv1.PolicyStatusActive).
+		Build()
+
+	// a mirror written by a previous scan, and a report of a resource of the
+	// namespace the results are mirrored to
+	oldMirroredReport := testutils.NewPolicyReportFactory().Name("old-mirror").Namespace("compliance").RunUID("old-run").WithAppLabel().Build()
+	oldMirroredReport.Labels["kubewarden.io/policyreport-mirror"] = "true"
+	namespacedReport := testutils.NewPolicyReportFactory().Name("namespaced").Namespace("compliance").RunUID("old-run").WithAppLabel().Build()
+
+	auditScheme, err := auditscheme.NewScheme()
+	require.NoError(t, err)
+	dynamicClient := dynamicFake.NewSimpleDynamicClient(auditScheme, namespace)
+	clientset := fake.NewSimpleClientset(namespace)
+	client, err := testutils.NewFakeClient(
+		namespace,
+		policyServer,
+		policyServerService,
+		clusterAdmissionPolicy,
+		oldMirroredReport,
+		namespacedReport,
+	)
+	require.NoError(t, err)
+
+	k8sClient, err := k8s.NewClient(dynamicClient, clientset, "kubewarden", nil, pageSize)
+	require.NoError(t, err)
+
+	policiesClient, err := policies.NewClient(client, "kubewarden", mockPolicyServer.URL)
+	require.NoError(t, err)
+
+	policyReportStore := report.NewPolicyReportStore(client)
+	config := newTestConfig(policiesClient, k8sClient, policyReportStore)
+	config.MirrorNamespace = "compliance"
+	scanner, err := NewScanner(config)
+	require.NoError(t, err)
+
+	runUID := uuid.New().String()
+	err = scanner.ScanClusterWideResources(context.Background(), runUID)
+	require.NoError(t, err)
+
+	clusterPolicyReport := wgpolicy.ClusterPolicyReport{}
+	err = client.Get(context.TODO(), types.NamespacedName{Name: string(namespace.GetUID())}, &clusterPolicyReport)
+	require.NoError(t, err)
+
+	mirroredReport := wgpolicy.PolicyReport{}
+	err = client.Get(context.TODO(), types.NamespacedName{Name: string(namespace.GetUID()), Namespace: "compliance"}, &mirroredReport)
+	require.NoError(t, err)
+	assert.Equal(t, clusterPolicyReport.Summary, mirroredReport.Summary)
+	assert.Equal(t, clusterPolicyReport.Results, mirroredReport.Results)
+	assert.Equal(t, clusterPolicyReport.Scope, mirroredReport.Scope)
+	assert.Equal(t, runUID, mirroredReport.GetLabels()[auditConstants.AuditScannerRunUIDLabel])
+	assert.Equal(t, "true", mirroredReport.GetLabels()["kubewarden.io/policyreport-mirror"])
+
+	// the mirrored results aren't counted twice
+	assert.Equal(t, clusterPolicyReport.Summary, policyReportStore.Summary())
+
+	err = client.Get(context.TODO(), types.NamespacedName{Name: "old-mirror", Namespace: "compliance"}, &wgpolicy.PolicyReport{})
+	require.True(t, apimachineryErrors.IsNotFound(err))
+	// the reports of the namespace are deleted by its own scan
+	err = client.Get(context.TODO(), types.NamespacedName{Name: "namespaced", Namespace: "compliance"}, &wgpolicy.PolicyReport{})
+	require.NoError(t, err)
+}
+
+func TestScanNamespaceFilteredByResourceSelector(t *testing.T) {
+	mockPolicyServer := newMockPolicyServer()
+	defer mockPolicyServer.Close()
+
+	policyServer, policyServerService := newDefaultPolicyServer()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+	}
+
+	frontendPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "frontend",
+			Namespace: "default",
+			UID:       "frontend-pod-uid",
+			Labels:    map[string]string{"app": "frontend"},
+		},
+	}
+
+	backendPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "backend",
+			Namespace: "default",
+			UID:       "backend-pod-uid",
+			Labels:    map[string]string{"app": "backend"},
+		},
+	}
+
+	clusterAdmissionPolicy := testutils.
+		NewClusterAdmissionPolicyFactory().
+		Name("clusterAdmissionPolicy").
+		Rule(admissionregistrationv1.Rule{
+			APIGroups:   []string{""},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"pods"},
+		}).
+		Status(policiesv1.PolicyStatusActive).
+		Build()
+
+	// the report of the backend pod written by a previous scan
+	backendPolicyReport := testutils.NewPolicyReportFactory().
+		Name(string(backendPod.GetUID())).Namespace("default").RunUID("old-uid").WithAppLabel().Build()
+
+	auditScheme, err := auditscheme.NewScheme()
+	require.NoError(t, err)
+	dynamicClient := dynamicFake.NewSimpleDynamicClient(auditScheme, frontendPod, backendPod)
+	clientset := fake.NewSimpleClientset(namespace)
+	client, err := testutils.NewFakeClient(
+		namespace,
+		policyServer,
+		policyServerService,
+		clusterAdmissionPolicy,
+		backendPolicyReport,
+	)
+	require.NoError(t, err)
+
+	k8sClient, err := k8s.NewClient(dynamicClient, clientset, "kubewarden", nil, pageSize)
+	require.NoError(t, err)
+
+	policiesClient, err := policies.NewClient(client, "kubewarden", mockPolicyServer.URL)
+	require.NoError(t, err)
+
+	config := newTestConfig(policiesClient, k8sClient, report.NewPolicyReportStore(client))
+	config.ResourceSelector = labels.SelectorFromSet(labels.Set{"app": "frontend"})
+	scanner, err := NewScanner(config)
+	require.NoError(t, err)
+
+	err = scanner.ScanNamespace(context.Background(), "default", uuid.New().String())
+	require.NoError(t, err)
+
+	policyReport := wgpolicy.PolicyReport{}
+	err = client.Get(context.TODO(), types.NamespacedName{Name: string(frontendPod.GetUID()), Namespace: "default"}, &policyReport)
+	require.NoError(t, err)
+	assert.Equal(t, 1, policyReport.Summary.Pass)
+
+	// the backend pod doesn't match the resource selector, hence it's not
+	// audited and its report is still current
+	err = client.Get(context.TODO(), types.NamespacedName{Name: string(backendPod.GetUID()), Namespace: "default"}, &policyReport)
+	require.NoError(t, err)
+	assert.Equal(t, "old-uid", policyReport.GetLabels()[auditConstants.AuditScannerRunUIDLabel])
+}
+
+func TestScanNamespaceWithExcludedKind(t *testing.T) {
+	mockPolicyServer := newMockPolicyServer()
+	defer mockPolicyServer.Close()
+
+	policyServer, policyServerService := newDefaultPolicyServer()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod",
+			Namespace: "default",
+			UID:       "pod-uid",
+		},
+	}
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "deployment",
+			Namespace: "default",
+			UID:       "deployment-uid",
+		},
+	}
+
+	podsPolicy := testutils.
+		NewClusterAdmissionPolicyFactory().
+		Name("podsPolicy").
+		Rule(admissionregistrationv1.Rule{
+			APIGroups:   []string{""},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"pods"},
+		}).
+		Status(policiesv1.PolicyStatusActive).
+		Build()
+
+	deploymentsPolicy := testutils.
+		NewClusterAdmissionPolicyFactory().
+		Name("deploymentsPolicy").
+		Rule(admissionregistrationv1.Rule{
+			APIGroups:   []string{"apps"},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"deployments"},
+		}).
+		Status(policiesv1.PolicyStatusActive).
+		Build()
+
+	// the report of the deployment written by a previous scan
+	deploymentPolicyReport := testutils.NewPolicyReportFactory().
+		Name(string(deployment.GetUID())).Namespace("default").RunUID("old-uid").WithAppLabel().Build()
+
+	auditScheme, err := auditscheme.NewScheme()
+	require.NoError(t, err)
+	dynamicClient := dynamicFake.NewSimpleDynamicClient(auditScheme, pod, deployment)
+	clientset := fake.NewSimpleClientset(namespace)
+	client, err := testutils.NewFakeClient(
+		namespace,
+		policyServer,
+		policyServerService,
+		podsPolicy,
+		deploymentsPolicy,
+		deploymentPolicyReport,
+	)
+	require.NoError(t, err)
+
+	k8sClient, err := k8s.NewClient(dynamicClient, clientset, "kubewarden", nil, pageSize)
+	require.NoError(t, err)
+
+	excludedKinds := []schema.GroupKind{{Group: "apps", Kind: "Deployment"}}
+	policiesClient, err := policies.NewClient(client, "kubewarden", mockPolicyServer.URL, policies.WithKindFilter(nil, excludedKinds))
+	require.NoError(t, err)
+
+	config := newTestConfig(policiesClient, k8sClient, report.NewPolicyReportStore(client))
+	config.ExcludedKinds = excludedKinds
+	scanner, err := NewScanner(config)
+	require.NoError(t, err)
+
+	err = scanner.ScanNamespace(context.Background(), "default", uuid.New().String())
+	require.NoError(t, err)
+
+	policyReport := wgpolicy.PolicyReport{}
+	err = client.Get(context.TODO(), types.NamespacedName{Name: string(pod.GetUID()), Namespace: "default"}, &policyReport)
+	require.NoError(t, err)
+	assert.Equal(t, 1, policyReport.Summary.Pass)
+
+	// the deployment is of an excluded kind, hence it's not audited and its
+	// report is still current
+	err = client.Get(context.TODO(), types.NamespacedName{Name: string(deployment.GetUID()), Namespace: "default"}, &policyReport)
+	require.NoError(t, err)
+	assert.Equal(t, "old-uid", policyReport.GetLabels()[auditConstants.AuditScannerRunUIDLabel])
+}
+
+func TestScanNamespaceWithPolicyNames(t *testing.T) {
+	mockPolicyServer := newMockPolicyServer()
+	defer mockPolicyServer.Close()
+
+	policyServer, policyServerService := newDefaultPolicyServer()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod",
+			Namespace: "default",
+			UID:       "pod-uid",
+		},
+	}
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "deployment",
+			Namespace: "default",
+			UID:       "deployment-uid",
+		},
+	}
+
+	podsPolicy := testutils.
+		NewClusterAdmissionPolicyFactory().
+		Name("podsPolicy").
+		Rule(admissionregistrationv1.Rule{
+			APIGroups:   []string{""},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"pods"},
+		}).
+		Status(policiesv1.PolicyStatusActive).
+		Build()
+
+	deploymentsPolicy := testutils.
+		NewClusterAdmissionPolicyFactory().
+		Name("deploymentsPolicy").
+		Rule(admissionregistrationv1.Rule{
+			APIGroups:   []string{"apps"},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"deployments"},
+		}).
+		Status(policiesv1.PolicyStatusActive).
+		Build()
+
+	// the report of the deployment written by a previous scan
+	deploymentPolicyReport := testutils.NewPolicyReportFactory().
+		Name(string(deployment.GetUID())).Namespace("default").RunUID("old-uid").WithAppLabel().Build()
+
+	auditScheme, err := auditscheme.NewScheme()
+	require.NoError(t, err)
+	dynamicClient := dynamicFake.NewSimpleDynamicClient(auditScheme, pod, deployment)
+	clientset := fake.NewSimpleClientset(namespace)
+	client, err := testutils.NewFakeClient(
+		namespace,
+		policyServer,
+		policyServerService,
+		podsPolicy,
+		deploymentsPolicy,
+		deploymentPolicyReport,
+	)
+	require.NoError(t, err)
+
+	k8sClient, err := k8s.NewClient(dynamicClient, clientset, "kubewarden", nil, pageSize)
+	require.NoError(t, err)
+
+	policiesClient, err := policies.NewClient(client, "kubewarden", mockPolicyServer.URL, policies.WithPolicyNames("podsPolicy"))
+	require.NoError(t, err)
+
+	config := newTestConfig(policiesClient, k8sClient, report.NewPolicyReportStore(client))
+	config.PolicyNames = []string{"podsPolicy"}
+	scanner, err := NewScanner(config)
+	require.NoError(t, err)
+
+	err = scanner.ScanNamespace(context.Background(), "default", uuid.New().String())
+	require.NoError(t, err)
+
+	policyReport := wgpolicy.PolicyReport{}
+	err = client.Get(context.TODO(), types.NamespacedName{Name: string(pod.GetUID()), Namespace: "default"}, &policyReport)
+	require.NoError(t, err)
+	assert.Equal(t, 1, policyReport.Summary.Pass)
+
+	// the deployment is matched only by a policy not selected, hence it's not
+	// audited and its report is still current
+	err = client.Get(context.TODO(), types.NamespacedName{Name: string(deployment.GetUID()), Namespace: "default"}, &policyReport)
+	require.NoError(t, err)
+	assert.Equal(t, "old-uid", policyReport.GetLabels()[auditConstants.AuditScannerRunUIDLabel])
+}
+
+func TestScanNamespaceFilteredByFieldSelector(t *testing.T) {
+	mockPolicyServer := newMockPolicyServer()
+	defer mockPolicyServer.Close()
+
+	policyServer, policyServerService := newDefaultPolicyServer()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+	}
+
+	// the only pod listed with the field selector
+	runningPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "running",
+			Namespace: "default",
+			UID:       "running-pod-uid",
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+
+	clusterAdmissionPolicy := testutils.
+		NewClusterAdmissionPolicyFactory().
+		Name("clusterAdmissionPolicy").
+		Rule(admissionregistrationv1.Rule{
+			APIGroups:   []string{""},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"pods"},
+		}).
+		Status(policiesv1.PolicyStatusActive).
+		Build()
+
+	// the report of a pending pod written by a previous scan
+	pendingPolicyReport := testutils.NewPolicyReportFactory().
+		Name("pending-pod-uid").Namespace("default").RunUID("old-uid").WithAppLabel().Build()
+
+	auditScheme, err := auditscheme.NewScheme()
+	require.NoError(t, err)
+	dynamicClient := dynamicFake.NewSimpleDynamicClient(auditScheme, runningPod)
+	clientset := fake.NewSimpleClientset(namespace)
+	client, err := testutils.NewFakeClient(
+		namespace,
+		policyServer,
+		policyServerService,
+		clusterAdmissionPolicy,
+		pendingPolicyReport,
+	)
+	require.NoError(t, err)
+
+	k8sClient, err := k8s.NewClient(dynamicClient, clientset, "kubewarden", nil, pageSize)
+	require.NoError(t, err)
+
+	policiesClient, err := policies.NewClient(client, "kubewarden", mockPolicyServer.URL)
+	require.NoError(t, err)
+
+	config := newTestConfig(policiesClient, k8sClient, report.NewPolicyReportStore(client))
+	config.FieldSelector = fields.OneTermEqualSelector("status.phase", string(corev1.PodRunning))
+	scanner, err := NewScanner(config)
+	require.NoError(t, err)
+
+	err = scanner.ScanNamespace(context.Background(), "default", uuid.New().String())
+	require.NoError(t, err)
+
+	policyReport := wgpolicy.PolicyReport{}
+	err = client.Get(context.TODO(), types.NamespacedName{Name: string(runningPod.GetUID()), Namespace: "default"}, &policyReport)
+	require.NoError(t, err)
+
+	// the pending pod isn't selected, hence its report is still current
+	err = client.Get(context.TODO(), types.NamespacedName{Name: "pending-pod-uid", Namespace: "default"}, &policyReport)
+	require.NoError(t, err)
+}
+
+func TestScanNamespaceWithRejectedFieldSelector(t *testing.T) {
+	mockPolicyServer := newMockPolicyServer()
+	defer mockPolicyServer.Close()
+
+	policyServer, policyServerService := newDefaultPolicyServer()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod",
+			Namespace: "default",
+			UID:       "pod-uid",
+		},
+	}
+
+	clusterAdmissionPolicy := testutils.
+		NewClusterAdmissionPolicyFactory().
+		Name("clusterAdmissionPolicy").
+		Rule(admissionregistrationv1.Rule{
+			APIGroups:   []string{""},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"pods"},
+		}).
+		Status(policiesv1.PolicyStatusActive).
+		Build()
+
+	auditScheme, err := auditscheme.NewScheme()
+	require.NoError(t, err)
+	dynamicClient := dynamicFake.NewSimpleDynamicClient(auditScheme, pod)
+	var requestedFieldSelector string
+	dynamicClient.PrependReactor("list", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		listAction, ok := action.(clienttesting.ListAction)
+		require.True(t, ok)
+		requestedFieldSelector = listAction.GetListRestrictions().Fields.String()
+		return true, nil, apimachineryErrors.NewBadRequest("field label not supported: spec.unknown")
+	})
+	clientset := fake.NewSimpleClientset(namespace)
+	client, err := testutils.NewFakeClient(
+		namespace,
+		policyServer,
+		policyServerService,
+		clusterAdmissionPolicy,
+	)
+	require.NoError(t, err)
+
+	k8sClient, err := k8s.NewClient(dynamicClient, clientset, "kubewarden", nil, pageSize)
+	require.NoError(t, err)
+
+	policiesClient, err := policies.NewClient(client, "kubewarden", mockPolicyServer.URL)
+	require.NoError(t, err)
+
+	config := newTestConfig(policiesClient, k8sClient, report.NewPolicyReportStore(client))
+	config.FieldSelector = fields.OneTermEqualSelector("spec.unknown", "value")
+	scanner, err := NewScanner(config)
+	require.NoError(t, err)
+
+	// the rejection only skips the resource type, it doesn't abort the scan
+	err = scanner.ScanNamespace(context.Background(), "default", uuid.New().String())
+	require.NoError(t, err)
+	assert.Equal(t, "spec.unknown=value", requestedFieldSelector)
+
+	policyReport := wgpolicy.PolicyReport{}
+	err = client.Get(context.TODO(), types.NamespacedName{Name: string(pod.GetUID()), Namespace: "default"}, &policyReport)
+	require.True(t, apimachineryErrors.IsNotFound(err))
+}
+
+func TestScanNamespaceWithUnavailableResource(t *testing.T) {
+	tests := []struct {
+		name    string
+		listErr error
+	}{
+		{"resource not found", apimachineryErrors.NewNotFound(schema.GroupResource{Group: "apps", Resource: "deployments"}, "")},
+		{"no kind match", &meta.NoResourceMatchError{PartialResource: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockPolicyServer := newMockPolicyServer()
+			defer mockPolicyServer.Close()
+
+			policyServer, policyServerService := newDefaultPolicyServer()
+
+			namespace := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "default",
+				},
+			}
+
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pod",
+					Namespace: "default",
+					UID:       "pod-uid",
+				},
+			}
+
+			clusterAdmissionPolicy := testutils.
+				NewClusterAdmissionPolicyFactory().
+				Name("clusterAdmissionPolicy").
+				Rule(admissionregistrationv1.Rule{
+					APIGroups:   []string{""},
+					APIVersions: []string{"v1"},
+					Resources:   []string{"pods"},
+				}).
+				Rule(admissionregistrationv1.Rule{
+					APIGroups:   []string{"apps"},
+					APIVersions: []string{"v1"},
+					Resources:   []string{"deployments"},
+				}).
+				Status(policiesv1.PolicyStatusActive).
+				Build()
+
+			auditScheme, err := auditscheme.NewScheme()
+			require.NoError(t, err)
+			dynamicClient := dynamicFake.NewSimpleDynamicClient(auditScheme, pod)
+			// the deployments are not served, e.g. because their CRD has been removed
+			dynamicClient.PrependReactor("list", "deployments", func(clienttesting.Action) (bool, runtime.Object, error) {
+				return true, nil, test.listErr
+			})
+			clientset := fake.NewSimpleClientset(namespace)
+			client, err := testutils.NewFakeClient(
+				namespace,
+				policyServer,
+				policyServerService,
+				clusterAdmissionPolicy,
+			)
+			require.NoError(t, err)
+
+			k8sClient, err := k8s.NewClient(dynamicClient, clientset, "kubewarden", nil, pageSize)
+			require.NoError(t, err)
+
+			policiesClient, err := policies.NewClient(client, "kubewarden", mockPolicyServer.URL)
+			require.NoError(t, err)
+
+			scanner, err := NewScanner(newTestConfig(policiesClient, k8sClient, report.NewPolicyReportStore(client)))
+			require.NoError(t, err)
+
+			// the unavailable resource type is skipped, the others are audited
+			err = scanner.ScanNamespace(context.Background(), "default", uuid.New().String())
+			require.NoError(t, err)
+
+			policyReport := wgpolicy.PolicyReport{}
+			err = client.Get(context.TODO(), types.NamespacedName{Name: string(pod.GetUID()), Namespace: "default"}, &policyReport)
+			require.NoError(t, err)
+			assert.Equal(t, 1, policyReport.Summary.Pass)
+		})
+	}
+}
+
+func TestScanNamespaceWithListError(t *testing.T) {
+	mockPolicyServer := newMockPolicyServer()
+	defer mockPolicyServer.Close()
+
+	policyServer, policyServerService := newDefaultPolicyServer()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod",
+			Namespace: "default",
+			UID:       "pod-uid",
+		},
+	}
+
+	clusterAdmissionPolicy := testutils.
+		NewClusterAdmissionPolicyFactory().
+		Name("clusterAdmissionPolicy").
+		Rule(admissionregistrationv1.Rule{
+			APIGroups:   []string{""},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"pods"},
+		}).
+		Rule(admissionregistrationv1.Rule{
+			APIGroups:   []string{"apps"},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"deployments"},
+		}).
+		Status(policiesv1.PolicyStatusActive).
+		Build()
+
+	oldPolicyReport := testutils.NewPolicyReportFactory().
+		Name("old-report").Namespace("default").RunUID("old-uid").WithAppLabel().Build()
+
+	auditScheme, err := auditscheme.NewScheme()
+	require.NoError(t, err)
+	dynamicClient := dynamicFake.NewSimpleDynamicClient(auditScheme, pod)
+	dynamicClient.PrependReactor("list", "deployments", func(clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apimachineryErrors.NewInternalError(errors.New("etcd timeout"))
+	})
+	clientset := fake.NewSimpleClientset(namespace)
+	client, err := testutils.NewFakeClient(
+		namespace,
+		policyServer,
+		policyServerService,
+		clusterAdmissionPolicy,
+		oldPolicyReport,
+	)
+	require.NoError(t, err)
+
+	k8sClient, err := k8s.NewClient(dynamicClient, clientset, "kubewarden", nil, pageSize)
+	require.NoError(t, err)
+
+	policiesClient, err := policies.NewClient(client, "kubewarden", mockPolicyServer.URL)
+	require.NoError(t, err)
+
+	config := newTestConfig(policiesClient, k8sClient, report.NewPolicyReportStore(client))
+	config.CollectErrors = true
+	scanner, err := NewScanner(config)
+	require.NoError(t, err)
+
+	err = scanner.ScanNamespace(context.Background(), "default", uuid.New().String())
+	require.ErrorContains(t, err, "etcd timeout")
+	assert.True(t, apimachineryErrors.IsInternalError(err))
+	scanErrors := scanner.Errors()
+	require.Len(t, scanErrors, 1)
+	assert.Equal(t, ScanErrorListResources, scanErrors[0].Kind)
+	assert.Equal(t, "default", scanErrors[0].Namespace)
+	assert.Equal(t, "apps/v1, Resource=deployments", scanErrors[0].GVR)
+
+	// the resources of the other types are audited anyway
+	policyReport := wgpolicy.PolicyReport{}
+	err = client.Get(context.TODO(), types.NamespacedName{Name: string(pod.GetUID()), Namespace: "default"}, &policyReport)
+	require.NoError(t, err)
+	assert.Equal(t, 1, policyReport.Summary.Pass)
+
+	// the reports of the resources that could not be listed are kept
+	err = client.Get(context.TODO(), types.NamespacedName{Name: "old-report", Namespace: "default"}, &policyReport)
+	require.NoError(t, err)
+}
+
+func TestScanNamespaceCancelledKeepsOldReports(t *testing.T) {
+	mockPolicyServer := newMockPolicyServer()
+	defer mockPolicyServer.Close()
+
+	policyServer, policyServerService := newDefaultPolicyServer()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod",
+			Namespace: "default",
+			UID:       "pod-uid",
+		},
+	}
+
+	clusterAdmissionPolicy := testutils.
+		NewClusterAdmissionPolicyFactory().
+		Name("clusterAdmissionPolicy").
+		Rule(admissionregistrationv1.Rule{
+			APIGroups:   []string{""},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"pods"},
+		}).
+		Status(policiesv1.PolicyStatusActive).
+		Build()
+
+	oldPolicyReport := testutils.NewPolicyReportFactory().
+		Name("old-report").Namespace("default").RunUID("old-uid").WithAppLabel().Build()
+
+	auditScheme, err := auditscheme.NewScheme()
+	require.NoError(t, err)
+	dynamicClient := dynamicFake.NewSimpleDynamicClient(auditScheme, pod)
+	clientset := fake.NewSimpleClientset(namespace)
+	client, err := testutils.NewFakeClient(
+		namespace,
+		policyServer,
+		policyServerService,
+		clusterAdmissionPolicy,
+		oldPolicyReport,
+	)
+	require.NoError(t, err)
+
+	k8sClient, err := k8s.NewClient(dynamicClient, clientset, "kubewarden", nil, pageSize)
+	require.NoError(t, err)
+
+	policiesClient, err := policies.NewClient(client, "kubewarden", mockPolicyServer.URL)
+	require.NoError(t, err)
+
+	scanner, err := NewScanner(newTestConfig(policiesClient, k8sClient, report.NewPolicyReportStore(client)))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = scanner.ScanNamespace(ctx, "default", uuid.New().String())
+	require.ErrorIs(t, err, context.Canceled)
+
+	// an interrupted scan must not delete the reports of the previous runs
+	policyReport := wgpolicy.PolicyReport{}
+	err = client.Get(context.TODO(), types.NamespacedName{Name: "old-report", Namespace: "default"}, &policyReport)
+	require.NoError(t, err)
+}
+
+func TestScanNamespaceCancelledRecordsNoAuditErrors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mockPolicyServer := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		// the scan is cancelled while the resource is being evaluated. The body
+		// is read first, so that the server notices the client going away
+		_, _ = io.Copy(io.Discard, r.Body)
+		cancel()
+		<-r.Context().Done()
+	}))
+	defer mockPolicyServer.Close()
+
+	policyServer, policyServerService := newDefaultPolicyServer()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod",
+			Namespace: "default",
+			UID:       "pod-uid",
+		},
+	}
+
+	clusterAdmissionPolicy := testutils.
+		NewClusterAdmissionPolicyFactory().
+		Name("clusterAdmissionPolicy").
+		Rule(admissionregistrationv1.Rule{
+			APIGroups:   []string{""},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"pods"},
+		}).
+		Status(policiesv1.PolicyStatusActive).
+		Build()
+
+	auditScheme, err := auditscheme.NewScheme()
+	require.NoError(t, err)
+	dynamicClient := dynamicFake.NewSimpleDynamicClient(auditScheme, pod)
+	clientset := fake.NewSimpleClientset(namespace)
+	client, err := testutils.NewFakeClient(
+		namespace,
+		policyServer,
+		policyServerService,
+		clusterAdmissionPolicy,
+	)
+	require.NoError(t, err)
+
+	k8sClient, err := k8s.NewClient(dynamicClient, clientset, "kubewarden", nil, pageSize)
+	require.NoError(t, err)
+
+	policiesClient, err := policies.NewClient(client, "kubewarden", mockPolicyServer.URL)
+	require.NoError(t, err)
+
+	config := newTestConfig(policiesClient, k8sClient, report.NewPolicyReportStore(client))
+	config.CollectErrors = true
+	scanner, err := NewScanner(config)
+	require.NoError(t, err)
+
+	err = scanner.ScanNamespace(ctx, "default", uuid.New().String())
+	require.ErrorIs(t, err, context.Canceled)
+
+	// the cancellation is not an error of the resource, it must not end in
+	// the errors written to --errors-file
+	for _, scanError := range scanner.Errors() {
+		assert.NotEqual(t, ScanErrorAuditResource, scanError.Kind, scanError.Message)
+	}
+}
+
+func TestScanNamespaceKeepOldReports(t *testing.T) {
+	mockPolicyServer := newMockPolicyServer()
+	defer mockPolicyServer.Close()
+
+	policyServer, policyServerService := newDefaultPolicyServer()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod",
+			Namespace: "default",
+			UID:       "pod-uid",
+		},
+	}
+
+	clusterAdmissionPolicy := testutils.
+		NewClusterAdmissionPolicyFactory().
+		Name("clusterAdmissionPolicy").
+		Rule(admissionregistrationv1.Rule{
+			APIGroups:   []string{""},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"pods"},
+		}).
+		Status(policiesv1.PolicyStatusActive).
+		Build()
+
+	oldPolicyReport := testutils.NewPolicyReportFactory().
+		Name("old-report").Namespace("default").RunUID("old-uid").WithAppLabel().Build()
+
+	auditScheme, err := auditscheme.NewScheme()
+	require.NoError(t, err)
+	dynamicClient := dynamicFake.NewSimpleDynamicClient(auditScheme, pod)
+	clientset := fake.NewSimpleClientset(namespace)
+	client, err := testutils.NewFakeClient(
+		namespace,
+		policyServer,
+		policyServerService,
+		clusterAdmissionPolicy,
+		oldPolicyReport,
+	)
+	require.NoError(t, err)
+
+	k8sClient, err := k8s.NewClient(dynamicClient, clientset, "kubewarden", nil, pageSize)
+	require.NoError(t, err)
+
+	policiesClient, err := policies.NewClient(client, "kubewarden", mockPolicyServer.URL)
+	require.NoError(t, err)
+
+	config := newTestConfig(policiesClient, k8sClient, report.NewPolicyReportStore(client))
+	config.KeepOldReports = true
+	scanner, err := NewScanner(config)
+	require.NoError(t, err)
+
+	err = scanner.ScanNamespace(context.Background(), "default", uuid.New().String())
+	require.NoError(t, err)
+
+	policyReport := wgpolicy.PolicyReport{}
+	err = client.Get(context.TODO(), types.NamespacedName{Name: string(pod.GetUID()), Namespace: "default"}, &policyReport)
+	require.NoError(t, err)
+
+	// the report written by the previous run is kept
+	err = client.Get(context.TODO(), types.NamespacedName{Name: "old-report", Namespace: "default"}, &policyReport)
+	require.NoError(t, err)
+}
+
+func TestScanNamespaceRecordTimings(t *testing.T) {
+	mockPolicyServer := newMockPolicyServer()
+	defer mockPolicyServer.Close()
+
+	policyServer, policyServerService := newDefaultPolicyServer()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod",
+			Namespace: "default",
+			UID:       "pod-uid",
+		},
+	}
+
+	clusterAdmissionPolicy := testutils.
+		NewClusterAdmissionPolicyFactory().
+		Name("clusterAdmissionPolicy").
+		Rule(admissionregistrationv1.Rule{
+			APIGroups:   []string{""},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"pods"},
+		}).
+		Status(policiesv1.PolicyStatusActive).
+		Build()
+
+	auditScheme, err := auditscheme.NewScheme()
+	require.NoError(t, err)
+	dynamicClient := dynamicFake.NewSimpleDynamicClient(auditScheme, pod)
+	clientset := fake.NewSimpleClientset(namespace)
+	client, err := testutils.NewFakeClient(
+		namespace,
+		policyServer,
+		policyServerService,
+		clusterAdmissionPolicy,
+	)
+	require.NoError(t, err)
+
+	k8sClient, err := k8s.NewClient(dynamicClient, clientset, "kubewarden", nil, pageSize)
+	require.NoError(t, err)
+
+	policiesClient, err := policies.NewClient(client, "kubewarden", mockPolicyServer.URL)
+	require.NoError(t, err)
+
+	config := newTestConfig(policiesClient, k8sClient, report.NewPolicyReportStore(client))
+	config.RecordTimings = true
+	scanner, err := NewScanner(config)
+	require.NoError(t, err)
+
+	err = scanner.ScanNamespace(context.Background(), "default", uuid.New().String())
+	require.NoError(t, err)
+
+	policyReport := wgpolicy.PolicyReport{}
+	err = client.Get(context.TODO(), types.NamespacedName{Name: string(pod.GetUID()), Namespace: "default"}, &policyReport)
+	require.NoError(t, err)
+
+	require.Len(t, policyReport.Results, 1)
+	duration, err := strconv.Atoi(policyReport.Results[0].Properties["evaluation-duration-ms"])
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, duration, 0)
+}
+
+func TestAuditResourceSkipsOwnedResources(t *testing.T) {
+	mockPolicyServer := newMockPolicyServer()
+	defer mockPolicyServer.Close()
+	policyServerURL, err := url.Parse(mockPolicyServer.URL + "/audit/policy")
+	require.NoError(t, err)
+
+	policy := testutils.NewClusterAdmissionPolicyFactory().
+		Name("policy").
+		Rule(admissionregistrationv1.Rule{
+			APIGroups:   []string{""},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"pods"},
+		}).
+		Build()
+
+	newPod := func(name string, owners ...metav1.OwnerReference) unstructured.Unstructured {
+		pod := unstructured.Unstructured{}
+		pod.SetName(name)
+		pod.SetNamespace("default")
+		pod.SetUID(types.UID(name + "-uid"))
+		pod.SetOwnerReferences(owners)
+		return pod
+	}
+	controller := true
+	pods := []unstructured.Unstructured{
+		newPod("standalone"),
+		newPod("replicaset-pod", metav1.OwnerReference{Kind: "ReplicaSet", Name: "nginx", Controller: &controller}),
+		newPod("job-pod", metav1.OwnerReference{Kind: "Job", Name: "backup", Controller: &controller}),
+		newPod("not-controlled", metav1.OwnerReference{Kind: "ReplicaSet", Name: "nginx"}),
+	}
+
+	tests := []struct {
+		name         string
+		ownerKinds   []string
+		expectedPods []string
+	}{
+		{"all the controllers", nil, []string{"standalone", "not-controlled"}},
+		{"selected controllers", []string{"ReplicaSet"}, []string{"standalone", "job-pod", "not-controlled"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := report.NewPolicyReportStore(nil, report.WithInMemoryReports())
+			config := newTestConfig(nil, nil, store)
+			config.DisableStore = true
+			config.SkipOwnedResources = true
+			config.SkipOwnerKinds = test.ownerKinds
+			scanner, err := NewScanner(config)
+			require.NoError(t, err)
+
+			gvr := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+			for _, pod := range pods {
+				err = scanner.auditResource(context.Background(), []*policies.Policy{{Policy: policy, PolicyServer: policyServerURL}}, gvr, pod, "runUID", 0, 0)
+				require.NoError(t, err)
+			}
+
+			auditedPods := []string{}
+			for _, policyReport := range store.Reports().PolicyReports {
+				auditedPods = append(auditedPods, policyReport.Scope.Name)
+			}
+			assert.ElementsMatch(t, test.expectedPods, auditedPods)
+		})
+	}
+}
+
+func TestScanNamespaceSkipOwnedResourcesKeepsOldReports(t *testing.T) {
+	mockPolicyServer := newMockPolicyServer()
+	defer mockPolicyServer.Close()
+
+	policyServer, policyServerService := newDefaultPolicyServer()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+	}
+
+	controller := true
+	standalonePod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "standalone",
+			Namespace: "default",
+			UID:       "standalone-uid",
+		},
+	}
+	ownedPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "owned",
+			Namespace:       "default",
+			UID:             "owned-uid",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "nginx", Controller: &controller}},
+		},
+	}
+
+	clusterAdmissionPolicy := testutils.
+		NewClusterAdmissionPolicyFactory().
+		Name("clusterAdmissionPolicy").
+		Rule(admissionregistrationv1.Rule{
+			APIGroups:   []string{""},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"pods"},
+		}).
+		Status(policiesv1.PolicyStatusActive).
+		Build()
+
+	// the report of the owned pod written by a previous scan
+	ownedPolicyReport := testutils.NewPolicyReportFactory().
+		Name(string(ownedPod.GetUID())).Namespace("default").RunUID("old-uid").WithAppLabel().Build()
+
+	auditScheme, err := auditscheme.NewScheme()
+	require.NoError(t, err)
+	dynamicClient := dynamicFake.NewSimpleDynamicClient(auditScheme, standalonePod, ownedPod)
+	clientset := fake.NewSimpleClientset(namespace)
+	client, err := testutils.NewFakeClient(
+		namespace,
+		policyServer,
+		policyServerService,
+		clusterAdmissionPolicy,
+		ownedPolicyReport,
+	)
+	require.NoError(t, err)
+
+	k8sClient, err := k8s.NewClient(dynamicClient, clientset, "kubewarden", nil, pageSize)
+	require.NoError(t, err)
+
+	policiesClient, err := policies.NewClient(client, "kubewarden", mockPolicyServer.URL)
+	require.NoError(t, err)
+
+	config := newTestConfig(policiesClient, k8sClient, report.NewPolicyReportStore(client))
+	config.SkipOwnedResources = true
+	scanner, err := NewScanner(config)
+	require.NoError(t, err)
+
+	err = scanner.ScanNamespace(context.Background(), "default", uuid.New().String())
+	require.NoError(t, err)
+
+	policyReport := wgpolicy.PolicyReport{}
+	err = client.Get(context.TODO(), types.NamespacedName{Name: string(standalonePod.GetUID()), Namespace: "default"}, &policyReport)
+	require.NoError(t, err)
+	assert.Equal(t, 1, policyReport.Summary.Pass)
+
+	// the owned pod is skipped, hence it's not audited and its report is
+	// still current
+	err = client.Get(context.TODO(), types.NamespacedName{Name: string(ownedPod.GetUID()), Namespace: "default"}, &policyReport)
+	require.NoError(t, err)
+	assert.Equal(t, "old-uid", policyReport.GetLabels()[auditConstants.AuditScannerRunUIDLabel])
+}
+
+func TestAuditResourceWithFakePolicyServer(t *testing.T) {
+	policyServer := testutils.NewFakePolicyServer(testutils.Allow())
+	defer policyServer.Close()
+	policyServer.SetResponse("clusterwide-deny", testutils.Deny("privileged containers are not allowed"))
+	policyServer.SetResponse("clusterwide-fail", testutils.Fail(http.StatusInternalServerError, "policy not found"))
+	policyServer.SetResponse("clusterwide-slow", testutils.Slow(testutils.Allow(), time.Second))
+	policyServer.SetResponse("clusterwide-no-response", testutils.PolicyServerResponse{Body: "{}"})
+
+	expectedResults := map[string]wgpolicy.PolicyResult{
+		"clusterwide-allow":       "pass",
+		"clusterwide-deny":        "fail",
+		"clusterwide-fail":        "error",
+		"clusterwide-slow":        "error",
+		"clusterwide-no-response": "error",
+	}
+	podsPolicies := []*policies.Policy{}
+	for _, name := range []string{"allow", "deny", "fail", "slow", "no-response"} {
+		policy := testutils.NewClusterAdmissionPolicyFactory().
+			Name(name).
+			Rule(admissionregistrationv1.Rule{
+				APIGroups:   []string{""},
+				APIVersions: []string{"v1"},
+				Resources:   []string{"pods"},
+			}).
+			Build()
+		podsPolicies = append(podsPolicies, &policies.Policy{Policy: policy, PolicyServer: policyServer.PolicyURL(policy.GetUniqueName())})
+	}
+
+	client, err := testutils.NewFakeClient()
+	require.NoError(t, err)
+	config := newTestConfig(nil, nil, report.NewPolicyReportStore(client))
+	config.PolicyServer.Timeout = 100 * time.Millisecond
+	config.PolicyServer.MaxRetries = 0
+	scanner, err := NewScanner(config)
+	require.NoError(t, err)
+
+	resource := unstructured.Unstructured{}
+	resource.SetAPIVersion("v1")
+	resource.SetKind("Pod")
+	resource.SetName("nginx")
+	resource.SetNamespace("default")
+	resource.SetUID("nginx-uid")
+	gvr := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+	require.NoError(t, scanner.auditResource(context.Background(), podsPolicies, gvr, resource, "runUID", 0, 0))
+
+	policyReport := wgpolicy.PolicyReport{}
+	err = client.Get(context.Background(), types.NamespacedName{Name: "nginx-uid", Namespace: "default"}, &policyReport)
+	require.NoError(t, err)
+	results := map[string]wgpolicy.PolicyResult{}
+	for _, result := range policyReport.Results {
+		results[result.Policy] = result.Result
+		if result.Policy == "clusterwide-deny" {
+			assert.Equal(t, "privileged containers are not allowed", result.Description)
+		}
+	}
+	assert.Equal(t, expectedResults, results)
+	assert.Equal(t, wgpolicy.PolicyReportSummary{Pass: 1, Fail: 1, Error: 3}, policyReport.Summary)
+
+	// every policy received the AdmissionReview of the resource
+	for policy := range expectedResults {
+		requests := policyServer.Requests(policy)
+		require.Len(t, requests, 1, policy)
+		assert.Equal(t, "nginx", requests[0].Request.Name)
+		assert.Equal(t, admissionv1.Create, requests[0].Request.Operation)
+	}
+}
+
+func TestAuditResourceCancelledSendsNoRequests(t *testing.T) {
+	policyServer := testutils.NewFakePolicyServer(testutils.Allow())
+	defer policyServer.Close()
+
+	policy := testutils.NewClusterAdmissionPolicyFactory().
+		Name("allow").
+		Rule(admissionregistrationv1.Rule{
+			APIGroups:   []string{""},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"pods"},
+		}).
+		Build()
+	podsPolicies := []*policies.Policy{{Policy: policy, PolicyServer: policyServer.PolicyURL(policy.GetUniqueName())}}
+
+	client, err := testutils.NewFakeClient()
+	require.NoError(t, err)
+	scanner, err := NewScanner(newTestConfig(nil, nil, report.NewPolicyReportStore(client)))
+	require.NoError(t, err)
+
+	resource := unstructured.Unstructured{}
+	resource.SetAPIVersion("v1")
+	resource.SetKind("Pod")
+	resource.SetName("nginx")
+	resource.SetNamespace("default")
+	resource.SetUID("nginx-uid")
+	gvr := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = scanner.auditResource(ctx, podsPolicies, gvr, resource, "runUID", 0, 0)
+	require.ErrorIs(t, err, context.Canceled)
+
+	// the cancelled audit doesn't reach the Policy Server, nor writes a report
+	assert.Empty(t, policyServer.Requests(policy.GetUniqueName()))
+	policyReport := wgpolicy.PolicyReport{}
+	err = client.Get(context.Background(), types.NamespacedName{Name: "nginx-uid", Namespace: "default"}, &policyReport)
+	require.True(t, apimachineryErrors.IsNotFound(err))
+}
+
+func TestScanNamespaceSampled(t *testing.T) {
+	mockPolicyServer := newMockPolicyServer()
+	defer mockPolicyServer.Close()
+
+	policyServer, policyServerService := newDefaultPolicyServer()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+	}
+
+	pods := []runtime.Object{}
+	for _, name := range []string{"pod-1", "pod-2", "pod-3"} {
+		pods = append(pods, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				UID:       types.UID(name + "-uid"),
+			},
+		})
+	}
+
+	clusterAdmissionPolicy := testutils.
+		NewClusterAdmissionPolicyFactory().
+		Name("clusterAdmissionPolicy").
+		Rule(admissionregistrationv1.Rule{
+			APIGroups:   []string{""},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"pods"},
+		}).
+		Status(policiesv1.PolicyStatusActive).
+		Build()
+
+	oldPolicyReport := testutils.NewPolicyReportFactory().
+		Name("old-report").Namespace("default").RunUID("old-uid").WithAppLabel().Build()
+
+	auditScheme, err := auditscheme.NewScheme()
+	require.NoError(t, err)
+	dynamicClient := dynamicFake.NewSimpleDynamicClient(auditScheme, pods...)
+	clientset := fake.NewSimpleClientset(namespace)
+	client, err := testutils.NewFakeClient(
+		namespace,
+		policyServer,
+		policyServerService,
+		clusterAdmissionPolicy,
+		oldPolicyReport,
+	)
+	require.NoError(t, err)
+
+	k8sClient, err := k8s.NewClient(dynamicClient, clientset, "kubewarden", nil, pageSize)
+	require.NoError(t, err)
+
+	policiesClient, err := policies.NewClient(client, "kubewarden", mockPolicyServer.URL)
+	require.NoError(t, err)
+
+	policyReportStore := report.NewPolicyReportStore(client, report.WithSampleSize(2))
+	config := newTestConfig(policiesClient, k8sClient, policyReportStore)
+	config.SampleSize = 2
+	scanner, err := NewScanner(config)
+	require.NoError(t, err)
+
+	err = scanner.ScanNamespace(context.Background(), "default", uuid.New().String())
+	require.NoError(t, err)
+
+	// only the first resources are audited, and their reports tell it
+	sampledReports := 0
+	for _, pod := range pods {
+		policyReport := wgpolicy.PolicyReport{}
+		err = client.Get(context.TODO(), types.NamespacedName{Name: string(pod.(*corev1.Pod).GetUID()), Namespace: "default"}, &policyReport)
+		if apimachineryErrors.IsNotFound(err) {
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, "2", policyReport.GetLabels()["kubewarden.io/policyreport-sample-size"])
+		sampledReports++
+	}
+	assert.Equal(t, 2, sampledReports)
+	assert.Equal(t, 2, policyReportStore.ScanSummary().Resources)
+	assert.Equal(t, 2, policyReportStore.ScanSummary().SampleSize)
+
+	// the reports of the resources not sampled are still current
+	policyReport := wgpolicy.PolicyReport{}
+	err = client.Get(context.TODO(), types.NamespacedName{Name: "old-report", Namespace: "default"}, &policyReport)
+	require.NoError(t, err)
+}
+
+func TestScanNamespaceTerminating(t *testing.T) {
+	fakePolicyServer := testutils.NewFakePolicyServer(testutils.Allow())
+	defer fakePolicyServer.Close()
+
+	policyServer, policyServerService := newDefaultPolicyServer()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+		Status: corev1.NamespaceStatus{Phase: corev1.NamespaceTerminating},
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod",
+			Namespace: "default",
+			UID:       "pod-uid",
+		},
+	}
+
+	clusterAdmissionPolicy := testutils.
+		NewClusterAdmissionPolicyFactory().
+		Name("clusterAdmissionPolicy").
+		Rule(admissionregistrationv1.Rule{
+			APIGroups:   []string{""},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"pods"},
+		}).
+		Status(policiesv1.PolicyStatusActive).
+		Build()
+
+	auditScheme, err := auditscheme.NewScheme()
+	require.NoError(t, err)
+	dynamicClient := dynamicFake.NewSimpleDynamicClient(auditScheme, pod)
+	clientset := fake.NewSimpleClientset(namespace)
+	client, err := testutils.NewFakeClient(
+		namespace,
+		policyServer,
+		policyServerService,
+		clusterAdmissionPolicy,
+	)
+	require.NoError(t, err)
+
+	k8sClient, err := k8s.NewClient(dynamicClient, clientset, "kubewarden", nil, pageSize)
+	require.NoError(t, err)
+
+	policiesClient, err := policies.NewClient(client, "kubewarden", fakePolicyServer.URL)
+	require.NoError(t, err)
+
+	policyReportStore := report.NewPolicyReportStore(client)
+	scanner, err := NewScanner(newTestConfig(policiesClient, k8sClient, policyReportStore))
+	require.NoError(t, err)
+
+	// the namespace being deleted is skipped without failing the scan
+	err = scanner.ScanNamespace(context.Background(), "default", uuid.New().String())
+	require.NoError(t, err)
+
+	assert.Empty(t, fakePolicyServer.Requests(clusterAdmissionPolicy.GetUniqueName()))
+	policyReport := wgpolicy.PolicyReport{}
+	err = client.Get(context.TODO(), types.NamespacedName{Name: string(pod.GetUID()), Namespace: "default"}, &policyReport)
+	require.True(t, apimachineryErrors.IsNotFound(err))
+	assert.Equal(t, 0, policyReportStore.ScanSummary().Namespaces)
+}
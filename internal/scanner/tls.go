@@ -0,0 +1,157 @@
+package scanner
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// caCertExtensions are the extensions of the files read from the CA directory.
+var caCertExtensions = []string{".crt", ".pem"}
+
+// appendCACertsFromDir appends the certificates of every .crt and .pem file of
+// dir to the pool. The files that cannot be read or parsed are logged and
+// skipped, an error is returned only when the directory can't be listed.
+func appendCACertsFromDir(rootCAs *x509.CertPool, dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read directory %q with CA certs: %w", dir, err)
+	}
+
+	appended := 0
+	for _, entry := range entries {
+		if entry.IsDir() || !isCACertFile(entry.Name()) {
+			continue
+		}
+		// the entries of the mounted Secrets and ConfigMaps are symlinks, hence
+		// the file is read instead of relying on the type of the entry
+		path := filepath.Join(dir, entry.Name())
+		caCert, err := os.ReadFile(path)
+		if err != nil {
+			log.Warn().Err(err).Str("ca-cert", path).
+				Msg("skipping CA cert file that cannot be read")
+			continue
+		}
+		if ok := rootCAs.AppendCertsFromPEM(caCert); !ok {
+			log.Warn().Str("ca-cert", path).
+				Msg("skipping CA cert file without valid PEM certificates")
+			continue
+		}
+		appended++
+		log.Debug().Str("ca-cert", path).
+			Msg("appended cert file to in-app RootCAs trust store")
+	}
+	if appended == 0 {
+		log.Warn().Str("ca-cert-dir", dir).
+			Msg("no CA cert appended to in-app RootCAs trust store from directory")
+	}
+
+	return nil
+}
+
+func isCACertFile(name string) bool {
+	for _, extension := range caCertExtensions {
+		if strings.EqualFold(filepath.Ext(name), extension) {
+			return true
+		}
+	}
+	return false
+}
+
+// caReloadingTransport sends the requests with a transport trusting the
+// current content of the CA file. The file is checked before every request and,
+// when its modification time or size change, a new transport trusting the new
+// CA replaces the previous one. The requests in flight complete with the
+// previous transport, and a CA file that cannot be parsed is ignored, keeping
+// the trusted CAs that are working.
+type caReloadingTransport struct {
+	path string
+	// basePool holds the trusted CAs other than the ones of the CA file
+	basePool  *x509.CertPool
+	transport atomic.Pointer[http.Transport]
+
+	mutex   sync.Mutex
+	modTime time.Time
+	size    int64
+}
+
+// newCAReloadingTransport returns a transport wrapping transport, which must
+// trust already the current content of the CA file.
+func newCAReloadingTransport(path string, basePool *x509.CertPool, transport *http.Transport) (*caReloadingTransport, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %q with CA cert: %w", path, err)
+	}
+	reloadingTransport := &caReloadingTransport{
+		path:     path,
+		basePool: basePool,
+		modTime:  info.ModTime(),
+		size:     info.Size(),
+	}
+	reloadingTransport.transport.Store(transport)
+
+	return reloadingTransport, nil
+}
+
+func (t *caReloadingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.reload()
+
+	return t.transport.Load().RoundTrip(req)
+}
+
+// CloseIdleConnections closes the idle connections of the current transport.
+func (t *caReloadingTransport) CloseIdleConnections() {
+	t.transport.Load().CloseIdleConnections()
+}
+
+// reload replaces the transport when the CA file has changed.
+func (t *caReloadingTransport) reload() {
+	info, err := os.Stat(t.path)
+	if err != nil {
+		// the file is being replaced, or it has been removed: the previous CA is kept
+		log.Debug().Err(err).Str("ca-cert", t.path).
+			Msg("cannot check CA cert file, keeping the trusted CAs")
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if info.ModTime().Equal(t.modTime) && info.Size() == t.size {
+		return
+	}
+	// the same content isn't checked again until the file changes
+	t.modTime = info.ModTime()
+	t.size = info.Size()
+
+	caCert, err := os.ReadFile(t.path)
+	if err != nil {
+		log.Warn().Err(err).Str("ca-cert", t.path).
+			Msg("cannot read changed CA cert file, keeping the trusted CAs")
+		return
+	}
+	rootCAs := t.basePool.Clone()
+	if ok := rootCAs.AppendCertsFromPEM(caCert); !ok {
+		log.Warn().Str("ca-cert", t.path).
+			Msg("changed CA cert file has no valid PEM certificates, keeping the trusted CAs")
+		return
+	}
+
+	previous := t.transport.Load()
+	transport := previous.Clone()
+	transport.TLSClientConfig.RootCAs = rootCAs
+	t.transport.Store(transport)
+	// the connections in use are closed once their requests complete
+	previous.CloseIdleConnections()
+
+	log.Info().Str("ca-cert", t.path).
+		Msg("reloaded changed CA cert file in in-app RootCAs trust store")
+}
@@ -0,0 +1,119 @@
+package scanner
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kubewarden/audit-scanner/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewScannerWithCADir(t *testing.T) {
+	caCertPEM, caKeyPEM, err := testutils.GenerateTestCA()
+	require.NoError(t, err)
+	serverCertPEM, serverKeyPEM, err := testutils.GenerateTestCert(caCertPEM, caKeyPEM, "server")
+	require.NoError(t, err)
+	otherCACertPEM, _, err := testutils.GenerateTestCA()
+	require.NoError(t, err)
+
+	caDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(caDir, "other.crt"), otherCACertPEM, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(caDir, "policy-server.pem"), caCertPEM, 0o600))
+	// malformed and unrelated files are skipped
+	require.NoError(t, os.WriteFile(filepath.Join(caDir, "malformed.crt"), []byte("not a certificate"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(caDir, "README.txt"), []byte("not a certificate"), 0o600))
+	require.NoError(t, os.Mkdir(filepath.Join(caDir, "nested.pem"), 0o700))
+
+	serverCert, err := tls.X509KeyPair(serverCertPEM, serverKeyPEM)
+	require.NoError(t, err)
+	mockPolicyServer := httptest.NewUnstartedServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_, _ = writer.Write([]byte(`{"response":{"allowed":true}}`))
+	}))
+	mockPolicyServer.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	mockPolicyServer.StartTLS()
+	defer mockPolicyServer.Close()
+
+	policyServerURL, err := url.Parse(mockPolicyServer.URL)
+	require.NoError(t, err)
+
+	config := newTestConfig(nil, nil, nil)
+	config.TLS = TLSConfig{CADir: caDir}
+	scanner, err := NewScanner(config)
+	require.NoError(t, err)
+
+	admissionReview, _, err := scanner.sendAdmissionReviewToPolicyServer(context.Background(), policyServerURL, newTestAdmissionReviewPayload(t))
+	require.NoError(t, err)
+	assert.True(t, admissionReview.Response.Allowed)
+
+	// the PolicyServer is not trusted without the CA of the directory
+	scanner, err = NewScanner(newTestConfig(nil, nil, nil))
+	require.NoError(t, err)
+	_, _, err = scanner.sendAdmissionReviewToPolicyServer(context.Background(), policyServerURL, newTestAdmissionReviewPayload(t))
+	require.Error(t, err)
+
+	config.TLS = TLSConfig{CADir: filepath.Join(caDir, "missing")}
+	_, err = NewScanner(config)
+	require.Error(t, err)
+}
+
+func TestNewScannerReloadsCAFile(t *testing.T) {
+	caCertPEM, caKeyPEM, err := testutils.GenerateTestCA()
+	require.NoError(t, err)
+	serverCertPEM, serverKeyPEM, err := testutils.GenerateTestCert(caCertPEM, caKeyPEM, "server")
+	require.NoError(t, err)
+	oldCACertPEM, _, err := testutils.GenerateTestCA()
+	require.NoError(t, err)
+
+	caFile := filepath.Join(t.TempDir(), "ca.crt")
+	require.NoError(t, os.WriteFile(caFile, oldCACertPEM, 0o600))
+	// make sure the modification time changes, even on coarse grained filesystems
+	rotate := func(t *testing.T, content []byte, modTime time.Time) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(caFile, content, 0o600))
+		require.NoError(t, os.Chtimes(caFile, modTime, modTime))
+	}
+
+	serverCert, err := tls.X509KeyPair(serverCertPEM, serverKeyPEM)
+	require.NoError(t, err)
+	mockPolicyServer := httptest.NewUnstartedServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_, _ = writer.Write([]byte(`{"response":{"allowed":true}}`))
+	}))
+	mockPolicyServer.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	mockPolicyServer.StartTLS()
+	defer mockPolicyServer.Close()
+
+	policyServerURL, err := url.Parse(mockPolicyServer.URL)
+	require.NoError(t, err)
+
+	config := newTestConfig(nil, nil, nil)
+	config.TLS = TLSConfig{CAFile: caFile}
+	scanner, err := NewScanner(config)
+	require.NoError(t, err)
+
+	// the PolicyServer certificate is signed by the new CA
+	_, _, err = scanner.sendAdmissionReviewToPolicyServer(context.Background(), policyServerURL, newTestAdmissionReviewPayload(t))
+	require.Error(t, err)
+
+	rotate(t, caCertPEM, time.Now().Add(time.Minute))
+	_, _, err = scanner.sendAdmissionReviewToPolicyServer(context.Background(), policyServerURL, newTestAdmissionReviewPayload(t))
+	require.NoError(t, err)
+
+	// a malformed CA file doesn't replace the working CA
+	rotate(t, []byte("not a certificate"), time.Now().Add(2*time.Minute))
+	_, _, err = scanner.sendAdmissionReviewToPolicyServer(context.Background(), policyServerURL, newTestAdmissionReviewPayload(t))
+	require.NoError(t, err)
+}
@@ -0,0 +1,71 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenSource provides the bearer token sent to the Policy Server.
+type tokenSource interface {
+	Token() (string, error)
+}
+
+// staticTokenSource always returns the same token.
+type staticTokenSource string
+
+func (t staticTokenSource) Token() (string, error) {
+	return string(t), nil
+}
+
+// fileTokenSource reads the token from a file. The file is read again when its
+// modification time or size change, so rotated tokens are picked up during
+// long-running scans.
+type fileTokenSource struct {
+	path string
+
+	mutex   sync.Mutex
+	modTime time.Time
+	size    int64
+	token   string
+}
+
+func newFileTokenSource(path string) (*fileTokenSource, error) {
+	source := &fileTokenSource{path: path}
+	// fail early when the token file cannot be read
+	if _, err := source.Token(); err != nil {
+		return nil, err
+	}
+
+	return source, nil
+}
+
+func (f *fileTokenSource) Token() (string, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return "", fmt.Errorf("cannot read token file: %w", err)
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.token != "" && info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return f.token, nil
+	}
+
+	content, err := os.ReadFile(f.path)
+	if err != nil {
+		return "", fmt.Errorf("cannot read token file: %w", err)
+	}
+	token := strings.TrimSpace(string(content))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", f.path)
+	}
+	f.token = token
+	f.modTime = info.ModTime()
+	f.size = info.Size()
+
+	return f.token, nil
+}
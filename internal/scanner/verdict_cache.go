@@ -0,0 +1,169 @@
+package scanner
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	policiesv1 "github.com/kubewarden/kubewarden-controller/api/policies/v1"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// instanceMetadataFields are the fields of the metadata telling apart the
+// instances of resources with the same content, left out of the content hash.
+var instanceMetadataFields = []string{"uid", "resourceVersion", "generation", "creationTimestamp", "managedFields"}
+
+// verdictCache keeps the verdicts of the policies for a limited time, keyed by
+// the content of the resources evaluated, so that the same content isn't sent
+// again and again to the Policy Servers, e.g. by the periodic scans of a
+// cluster that didn't change.
+type verdictCache struct {
+	mutex    sync.Mutex
+	ttl      time.Duration
+	verdicts map[verdictKey]cachedVerdict
+	// nextPrune is when the expired verdicts are dropped next
+	nextPrune time.Time
+	// now returns the current time, it's replaced by tests
+	now func() time.Time
+}
+
+// verdictKey identifies the evaluation of a policy against a resource content.
+type verdictKey struct {
+	policy      string
+	contentHash string
+}
+
+type cachedVerdict struct {
+	// policyVersion is the resourceVersion of the policy giving the verdict,
+	// which is stale once the policy changes
+	policyVersion   string
+	admissionReview *admissionv1.AdmissionReview
+	expiresAt       time.Time
+}
+
+func newVerdictCache(ttl time.Duration) *verdictCache {
+	return &verdictCache{
+		ttl:      ttl,
+		verdicts: map[verdictKey]cachedVerdict{},
+		now:      time.Now,
+	}
+}
+
+// get returns a copy of the verdict of the policy on the resource content, if
+// it's cached, not expired and given by the current version of the policy.
+// The response is addressed to the request auditing uid.
+func (c *verdictCache) get(policy policiesv1.Policy, contentHash string, uid types.UID) (*admissionv1.AdmissionReview, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key := verdictKey{policy: policy.GetUniqueName(), contentHash: contentHash}
+	cached, found := c.verdicts[key]
+	if !found {
+		return nil, false
+	}
+	if cached.policyVersion != policy.GetResourceVersion() || !c.now().Before(cached.expiresAt) {
+		delete(c.verdicts, key)
+		return nil, false
+	}
+
+	admissionReview := cached.admissionReview.DeepCopy()
+	admissionReview.Response.UID = uid
+
+	return admissionReview, true
+}
+
+// add caches the verdict of the policy on the resource content.
+func (c *verdictCache) add(policy policiesv1.Policy, contentHash string, admissionReview *admissionv1.AdmissionReview) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := c.now()
+	if !now.Before(c.nextPrune) {
+		// the verdicts of the contents not seen anymore are never looked up
+		// again, hence they are dropped here
+		for key, cached := range c.verdicts {
+			if !now.Before(cached.expiresAt) {
+				delete(c.verdicts, key)
+			}
+		}
+		c.nextPrune = now.Add(c.ttl)
+	}
+
+	c.verdicts[verdictKey{policy: policy.GetUniqueName(), contentHash: contentHash}] = cachedVerdict{
+		policyVersion:   policy.GetResourceVersion(),
+		admissionReview: admissionReview.DeepCopy(),
+		expiresAt:       now.Add(c.ttl),
+	}
+}
+
+// newResourceContentHash returns a function hashing the content of the
+// resource sent to the Policy Servers, i.e. with the redactions applied. The
+// metadata telling apart the instances of the same content, like the UID and
+// the resourceVersion, are left out, the name, the namespace and the labels
+// aren't since the policies evaluate them. Hence the resources with generated
+// names, like the Pods of a ReplicaSet, have different hashes even when they
+// are created from the same template. The hash is computed only once, the
+// first time it's needed.
+func newResourceContentHash(resource unstructured.Unstructured, redactions []Redaction) func() (string, error) {
+	return sync.OnceValues(func() (string, error) {
+		content := redact(resource, redactions)
+		// the resource is shared with the other evaluations, when not redacted
+		content = *content.DeepCopy()
+		for _, field := range instanceMetadataFields {
+			unstructured.RemoveNestedField(content.Object, "metadata", field)
+		}
+		serialized, err := json.Marshal(content.Object)
+		if err != nil {
+			return "", fmt.Errorf("cannot serialize the content of %q: %w", resource.GetName(), err)
+		}
+		hash := sha256.Sum256(serialized)
+
+		return hex.EncodeToString(hash[:]), nil
+	})
+}
+
+// evaluatePolicy sends the AdmissionReview of the resource to the Policy
+// Server evaluating the policy, unless the verdict of the policy on the content
+// of the resource is cached. The successful evaluations are cached, the failed
+// ones are tried again by the next audits. The verdicts of the context aware
+// policies are never cached, since they depend on other resources of the
+// cluster too.
+// It returns the round-trip time of the request to the Policy Server too, 0
+// when the verdict is cached.
+func (s *Scanner) evaluatePolicy(ctx context.Context, url *url.URL, policy policiesv1.Policy, resource unstructured.Unstructured, payload []byte, contentHash func() (string, error)) (*admissionv1.AdmissionReview, time.Duration, error) {
+	if s.verdictCache == nil || policy.IsContextAware() {
+		return s.sendAdmissionReviewToPolicyServer(ctx, url, payload)
+	}
+	hash, err := contentHash()
+	if err != nil {
+		log.Warn().Err(err).Str("resource", resource.GetName()).Msg("cannot hash the resource, evaluating it without the verdict cache")
+		return s.sendAdmissionReviewToPolicyServer(ctx, url, payload)
+	}
+
+	if admissionReview, found := s.verdictCache.get(policy, hash, resource.GetUID()); found {
+		log.Debug().Dict("dict", zerolog.Dict().
+			Str("policy", policy.GetUniqueName()).
+			Str("resource", resource.GetName()),
+		).Msg("verdict found in the cache, the AdmissionReview is not sent to the PolicyServer")
+		trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("cached", true))
+		return admissionReview, 0, nil
+	}
+
+	admissionReview, roundTrip, err := s.sendAdmissionReviewToPolicyServer(ctx, url, payload)
+	if err == nil && (admissionReview.Response.Result == nil || admissionReview.Response.Result.Code != 500) {
+		s.verdictCache.add(policy, hash, admissionReview)
+	}
+
+	return admissionReview, roundTrip, err
+}
@@ -0,0 +1,204 @@
+package scanner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kubewarden/audit-scanner/internal/policies"
+	"github.com/kubewarden/audit-scanner/internal/report"
+	testutils "github.com/kubewarden/audit-scanner/internal/testutils"
+	policiesv1 "github.com/kubewarden/kubewarden-controller/api/policies/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	wgpolicy "sigs.k8s.io/wg-policy-prototypes/policy-report/pkg/api/wgpolicyk8s.io/v1alpha2"
+)
+
+func newVerdictCachePod(name, uid string) unstructured.Unstructured {
+	resource := unstructured.Unstructured{}
+	resource.SetAPIVersion("v1")
+	resource.SetKind("Pod")
+	resource.SetName(name)
+	resource.SetNamespace("default")
+	resource.SetUID(types.UID(uid))
+	resource.SetResourceVersion(uid + "-version")
+	resource.SetLabels(map[string]string{"app": "nginx"})
+	return resource
+}
+
+func TestVerdictCache(t *testing.T) {
+	policy := testutils.NewClusterAdmissionPolicyFactory().Name("policy").Build()
+	policy.SetResourceVersion("1")
+	now := time.Now()
+	cache := newVerdictCache(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	_, found := cache.get(policy, "hash", "uid")
+	assert.False(t, found)
+
+	cache.add(policy, "hash", &admissionv1.AdmissionReview{Response: &admissionv1.AdmissionResponse{UID: "uid", Allowed: true}})
+	admissionReview, found := cache.get(policy, "hash", "other-uid")
+	require.True(t, found)
+	assert.True(t, admissionReview.Response.Allowed)
+	// the verdict answers the request auditing the other resource
+	assert.Equal(t, types.UID("other-uid"), admissionReview.Response.UID)
+	_, found = cache.get(policy, "other-hash", "uid")
+	assert.False(t, found)
+
+	// the verdicts expire
+	now = now.Add(time.Minute)
+	_, found = cache.get(policy, "hash", "uid")
+	assert.False(t, found)
+
+	// the verdicts of a previous version of the policy are dropped
+	cache.add(policy, "hash", &admissionv1.AdmissionReview{Response: &admissionv1.AdmissionResponse{Allowed: true}})
+	updatedPolicy := policy.DeepCopy()
+	updatedPolicy.SetResourceVersion("2")
+	_, found = cache.get(updatedPolicy, "hash", "uid")
+	assert.False(t, found)
+	_, found = cache.get(policy, "hash", "uid")
+	assert.False(t, found)
+}
+
+func TestResourceContentHash(t *testing.T) {
+	hash, err := newResourceContentHash(newVerdictCachePod("nginx", "uid"), nil)()
+	require.NoError(t, err)
+
+	// the instances of the same content have the same hash
+	recreated := newVerdictCachePod("nginx", "recreated-uid")
+	recreated.SetCreationTimestamp(metav1.Now())
+	recreatedHash, err := newResourceContentHash(recreated, nil)()
+	require.NoError(t, err)
+	assert.Equal(t, hash, recreatedHash)
+	// the resource itself is left untouched
+	assert.Equal(t, types.UID("recreated-uid"), recreated.GetUID())
+
+	// the fields evaluated by the policies are part of the content
+	renamedHash, err := newResourceContentHash(newVerdictCachePod("httpd", "uid"), nil)()
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, renamedHash)
+	relabeled := newVerdictCachePod("nginx", "uid")
+	relabeled.SetLabels(map[string]string{"app": "httpd"})
+	relabeledHash, err := newResourceContentHash(relabeled, nil)()
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, relabeledHash)
+
+	// the name is part of the content, hence the Pods of the same ReplicaSet,
+	// created from the same template, deliberately don't share their verdicts
+	replicas := []unstructured.Unstructured{}
+	for _, name := range []string{"nginx-7c5ddbdf54-2xq8z", "nginx-7c5ddbdf54-9lbv4"} {
+		replica := newVerdictCachePod(name, name)
+		replica.SetGenerateName("nginx-7c5ddbdf54-")
+		replica.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "nginx-7c5ddbdf54", UID: "replicaset-uid"}})
+		replicas = append(replicas, replica)
+	}
+	firstReplicaHash, err := newResourceContentHash(replicas[0], nil)()
+	require.NoError(t, err)
+	secondReplicaHash, err := newResourceContentHash(replicas[1], nil)()
+	require.NoError(t, err)
+	assert.NotEqual(t, firstReplicaHash, secondReplicaHash)
+
+	// the redacted fields are not sent, hence they aren't part of the content
+	redaction, err := ParseRedaction(".metadata.labels")
+	require.NoError(t, err)
+	redactions := []Redaction{redaction}
+	redactedHash, err := newResourceContentHash(newVerdictCachePod("nginx", "uid"), redactions)()
+	require.NoError(t, err)
+	relabeledRedactedHash, err := newResourceContentHash(relabeled, redactions)()
+	require.NoError(t, err)
+	assert.Equal(t, redactedHash, relabeledRedactedHash)
+}
+
+func TestAuditResourceWithVerdictCache(t *testing.T) {
+	policyServer := testutils.NewFakePolicyServer(testutils.Deny("nginx is not allowed"))
+	defer policyServer.Close()
+
+	policy := testutils.NewClusterAdmissionPolicyFactory().
+		Name("deny").
+		Rule(admissionregistrationv1.Rule{
+			APIGroups:   []string{""},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"pods"},
+		}).
+		Build()
+	policy.SetResourceVersion("1")
+	podsPolicies := []*policies.Policy{{Policy: policy, PolicyServer: policyServer.PolicyURL(policy.GetUniqueName())}}
+
+	client, err := testutils.NewFakeClient()
+	require.NoError(t, err)
+	config := newTestConfig(nil, nil, report.NewPolicyReportStore(client))
+	config.VerdictCacheTTL = time.Hour
+	config.RecordTimings = true
+	scanner, err := NewScanner(config)
+	require.NoError(t, err)
+	gvr := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+
+	// the recreated resource is not sent again to the Policy Server
+	require.NoError(t, scanner.auditResource(context.Background(), podsPolicies, gvr, newVerdictCachePod("nginx", "uid"), "runUID", 0, 0))
+	require.NoError(t, scanner.auditResource(context.Background(), podsPolicies, gvr, newVerdictCachePod("nginx", "recreated-uid"), "runUID", 0, 0))
+	assert.Len(t, policyServer.Requests(policy.GetUniqueName()), 1)
+
+	policyReport := wgpolicy.PolicyReport{}
+	err = client.Get(context.Background(), types.NamespacedName{Name: "recreated-uid", Namespace: "default"}, &policyReport)
+	require.NoError(t, err)
+	require.Len(t, policyReport.Results, 1)
+	assert.Equal(t, wgpolicy.PolicyResult("fail"), policyReport.Results[0].Result)
+	assert.Equal(t, "nginx is not allowed", policyReport.Results[0].Description)
+	// the cached verdict has no round-trip to the Policy Server to time
+	assert.NotContains(t, policyReport.Results[0].Properties, "evaluation-duration-ms")
+	err = client.Get(context.Background(), types.NamespacedName{Name: "uid", Namespace: "default"}, &policyReport)
+	require.NoError(t, err)
+	assert.Contains(t, policyReport.Results[0].Properties, "evaluation-duration-ms")
+
+	// the resource is sent again once the policy changes
+	podsPolicies[0].Policy.SetResourceVersion("2")
+	require.NoError(t, scanner.auditResource(context.Background(), podsPolicies, gvr, newVerdictCachePod("nginx", "uid"), "runUID", 0, 0))
+	assert.Len(t, policyServer.Requests(policy.GetUniqueName()), 2)
+}
+
+func TestAuditResourceWithVerdictCacheContextAwarePolicy(t *testing.T) {
+	policyServer := testutils.NewFakePolicyServer(testutils.Deny("nginx is not allowed"))
+	defer policyServer.Close()
+
+	policy := testutils.NewClusterAdmissionPolicyFactory().
+		Name("context-aware").
+		Rule(admissionregistrationv1.Rule{
+			APIGroups:   []string{""},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"pods"},
+		}).
+		Build()
+	policy.SetResourceVersion("1")
+	policy.Spec.ContextAwareResources = []policiesv1.ContextAwareResource{{APIVersion: "v1", Kind: "ConfigMap"}}
+	podsPolicies := []*policies.Policy{{Policy: policy, PolicyServer: policyServer.PolicyURL(policy.GetUniqueName())}}
+
+	client, err := testutils.NewFakeClient()
+	require.NoError(t, err)
+	config := newTestConfig(nil, nil, report.NewPolicyReportStore(client))
+	config.VerdictCacheTTL = time.Hour
+	scanner, err := NewScanner(config)
+	require.NoError(t, err)
+	gvr := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+
+	// the verdict depends on the ConfigMaps too, hence the same content is sent again
+	require.NoError(t, scanner.auditResource(context.Background(), podsPolicies, gvr, newVerdictCachePod("nginx", "uid"), "runUID", 0, 0))
+	require.NoError(t, scanner.auditResource(context.Background(), podsPolicies, gvr, newVerdictCachePod("nginx", "recreated-uid"), "runUID", 0, 0))
+	assert.Len(t, policyServer.Requests(policy.GetUniqueName()), 2)
+}
+
+func TestNewScannerVerdictCache(t *testing.T) {
+	scanner, err := NewScanner(newTestConfig(nil, nil, nil))
+	require.NoError(t, err)
+	assert.Nil(t, scanner.verdictCache)
+
+	config := newTestConfig(nil, nil, nil)
+	config.VerdictCacheTTL = -time.Minute
+	_, err = NewScanner(config)
+	require.Error(t, err)
+}
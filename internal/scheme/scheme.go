@@ -3,13 +3,20 @@ package scheme
 import (
 	policiesv1 "github.com/kubewarden/kubewarden-controller/api/policies/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/client-go/kubernetes/scheme"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	wgpolicy "sigs.k8s.io/wg-policy-prototypes/policy-report/pkg/api/wgpolicyk8s.io/v1alpha2"
 )
 
+// NewScheme returns a new scheme with the Kubernetes built-in types, the
+// Kubewarden policies and the PolicyReports registered. Every call returns a
+// scheme of its own, the global scheme of client-go is left untouched.
 func NewScheme() (*runtime.Scheme, error) {
-	scheme := scheme.Scheme
-	err := policiesv1.AddToScheme(scheme)
+	scheme := runtime.NewScheme()
+	err := clientgoscheme.AddToScheme(scheme)
+	if err != nil {
+		return nil, err
+	}
+	err = policiesv1.AddToScheme(scheme)
 	if err != nil {
 		return nil, err
 	}
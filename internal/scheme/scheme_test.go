@@ -0,0 +1,60 @@
+package scheme
+
+import (
+	"context"
+	"testing"
+
+	policiesv1 "github.com/kubewarden/kubewarden-controller/api/policies/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	wgpolicy "sigs.k8s.io/wg-policy-prototypes/policy-report/pkg/api/wgpolicyk8s.io/v1alpha2"
+)
+
+func TestNewScheme(t *testing.T) {
+	first, err := NewScheme()
+	require.NoError(t, err)
+	second, err := NewScheme()
+	require.NoError(t, err)
+	assert.NotSame(t, first, second)
+
+	policyReportKind := wgpolicy.SchemeGroupVersion.WithKind("PolicyReport")
+	clusterAdmissionPolicyKind := policiesv1.GroupVersion.WithKind("ClusterAdmissionPolicy")
+	for _, kind := range []schema.GroupVersionKind{
+		corev1.SchemeGroupVersion.WithKind("Pod"),
+		policyReportKind,
+		clusterAdmissionPolicyKind,
+	} {
+		assert.True(t, first.Recognizes(kind), kind.String())
+	}
+
+	// the global scheme of client-go doesn't get the types of the audit scanner
+	assert.False(t, clientgoscheme.Scheme.Recognizes(policyReportKind))
+	assert.False(t, clientgoscheme.Scheme.Recognizes(clusterAdmissionPolicyKind))
+}
+
+func TestNewSchemeClusterPolicyReport(t *testing.T) {
+	auditScheme, err := NewScheme()
+	require.NoError(t, err)
+	client := fake.NewClientBuilder().WithScheme(auditScheme).Build()
+
+	clusterPolicyReport := &wgpolicy.ClusterPolicyReport{
+		ObjectMeta: metav1.ObjectMeta{Name: "report"},
+		Summary:    wgpolicy.PolicyReportSummary{Fail: 1},
+	}
+	require.NoError(t, client.Create(context.Background(), clusterPolicyReport))
+
+	storedClusterPolicyReport := &wgpolicy.ClusterPolicyReport{}
+	err = client.Get(context.Background(), types.NamespacedName{Name: "report"}, storedClusterPolicyReport)
+	require.NoError(t, err)
+	assert.Equal(t, 1, storedClusterPolicyReport.Summary.Fail)
+
+	clusterPolicyReports := &wgpolicy.ClusterPolicyReportList{}
+	require.NoError(t, client.List(context.Background(), clusterPolicyReports))
+	assert.Len(t, clusterPolicyReports.Items, 1)
+}
@@ -0,0 +1,159 @@
+//go:build testing
+
+package testutils
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"sync"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PolicyServerResponse is how a FakePolicyServer answers the evaluation of a policy.
+type PolicyServerResponse struct {
+	// Allowed tells whether the policy accepts the resource
+	Allowed bool
+	// Message is the reason of the rejection
+	Message string
+	// StatusCode is sent instead of 200, together with Body
+	StatusCode int
+	// Body is sent as it is instead of the AdmissionReview, when not empty
+	Body string
+	// Delay is waited before answering, unless the request is cancelled first
+	Delay time.Duration
+}
+
+// Allow returns the response of a policy accepting the resource.
+func Allow() PolicyServerResponse {
+	return PolicyServerResponse{Allowed: true}
+}
+
+// Deny returns the response of a policy rejecting the resource with the given message.
+func Deny(message string) PolicyServerResponse {
+	return PolicyServerResponse{Message: message}
+}
+
+// Fail returns the response of a Policy Server failing with the given status code and body.
+func Fail(statusCode int, body string) PolicyServerResponse {
+	return PolicyServerResponse{StatusCode: statusCode, Body: body}
+}
+
+// Slow returns the given response, sent after waiting delay.
+func Slow(response PolicyServerResponse, delay time.Duration) PolicyServerResponse {
+	response.Delay = delay
+	return response
+}
+
+// FakePolicyServer is an HTTP server emulating a Policy Server. It answers the
+// requests to /audit/<policy> with the response configured for the policy, or
+// with the default one, and records the AdmissionReviews it receives.
+type FakePolicyServer struct {
+	*httptest.Server
+
+	mutex           sync.Mutex
+	defaultResponse PolicyServerResponse
+	responses       map[string]PolicyServerResponse
+	requests        map[string][]admissionv1.AdmissionReview
+}
+
+// NewFakePolicyServer starts a FakePolicyServer answering with defaultResponse
+// the policies without a response of their own. It must be closed by the caller.
+func NewFakePolicyServer(defaultResponse PolicyServerResponse) *FakePolicyServer {
+	policyServer := &FakePolicyServer{
+		defaultResponse: defaultResponse,
+		responses:       map[string]PolicyServerResponse{},
+		requests:        map[string][]admissionv1.AdmissionReview{},
+	}
+	policyServer.Server = httptest.NewServer(http.HandlerFunc(policyServer.serveHTTP))
+
+	return policyServer
+}
+
+// SetResponse sets the response to the evaluations of the given policy.
+func (s *FakePolicyServer) SetResponse(policy string, response PolicyServerResponse) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.responses[policy] = response
+}
+
+// PolicyURL returns the URL evaluating the given policy.
+func (s *FakePolicyServer) PolicyURL(policy string) *url.URL {
+	policyURL, err := url.Parse(s.URL + "/audit/" + policy)
+	if err != nil {
+		panic(err)
+	}
+
+	return policyURL
+}
+
+// Requests returns the AdmissionReviews received to evaluate the given policy.
+func (s *FakePolicyServer) Requests(policy string) []admissionv1.AdmissionReview {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]admissionv1.AdmissionReview(nil), s.requests[policy]...)
+}
+
+func (s *FakePolicyServer) serveHTTP(writer http.ResponseWriter, r *http.Request) {
+	policy := path.Base(r.URL.Path)
+	body := io.Reader(r.Body)
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gzipReader, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		body = gzipReader
+	}
+	admissionReview := admissionv1.AdmissionReview{}
+	if err := json.NewDecoder(body).Decode(&admissionReview); err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mutex.Lock()
+	s.requests[policy] = append(s.requests[policy], admissionReview)
+	response, found := s.responses[policy]
+	if !found {
+		response = s.defaultResponse
+	}
+	s.mutex.Unlock()
+
+	if response.Delay > 0 {
+		select {
+		case <-time.After(response.Delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if response.StatusCode != 0 || response.Body != "" {
+		if response.StatusCode != 0 {
+			writer.WriteHeader(response.StatusCode)
+		}
+		_, _ = writer.Write([]byte(response.Body))
+		return
+	}
+
+	admissionResponse := &admissionv1.AdmissionResponse{Allowed: response.Allowed}
+	if admissionReview.Request != nil {
+		admissionResponse.UID = admissionReview.Request.UID
+	}
+	if !response.Allowed {
+		admissionResponse.Result = &metav1.Status{Message: response.Message}
+	}
+	responseBody, err := json.Marshal(admissionv1.AdmissionReview{Response: admissionResponse})
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	_, _ = writer.Write(responseBody)
+}
@@ -132,12 +132,14 @@ type AdmissionPolicyFactory struct {
 	objectSelector  *metav1.LabelSelector
 	rules           []admissionregistrationv1.RuleWithOperations
 	backgroundAudit bool
+	mode            policiesv1.PolicyMode
 	status          policiesv1.PolicyStatusEnum
 }
 
 func NewAdmissionPolicyFactory() *AdmissionPolicyFactory {
 	return &AdmissionPolicyFactory{
 		backgroundAudit: true,
+		mode:            "protect",
 		status:          policiesv1.PolicyStatusActive,
 	}
 }
@@ -178,6 +180,12 @@ func (factory *AdmissionPolicyFactory) BackgroundAudit(backgroundAudit bool) *Ad
 	return factory
 }
 
+func (factory *AdmissionPolicyFactory) Mode(mode policiesv1.PolicyMode) *AdmissionPolicyFactory {
+	factory.mode = mode
+
+	return factory
+}
+
 func (factory *AdmissionPolicyFactory) Status(status policiesv1.PolicyStatusEnum) *AdmissionPolicyFactory {
 	factory.status = status
 
@@ -196,6 +204,7 @@ func (factory *AdmissionPolicyFactory) Build() *policiesv1.AdmissionPolicy {
 				PolicyServer:    "default",
 				Rules:           factory.rules,
 				BackgroundAudit: factory.backgroundAudit,
+				Mode:            factory.mode,
 			},
 		},
 		Status: policiesv1.PolicyStatus{
@@ -300,16 +309,20 @@ func (factory *AdmissionPolicyGroupFactory) Build() *policiesv1.AdmissionPolicyG
 
 type ClusterAdmissionPolicyFactory struct {
 	name              string
+	policyServer      string
 	namespaceSelector *metav1.LabelSelector
 	objectSelector    *metav1.LabelSelector
 	rules             []admissionregistrationv1.RuleWithOperations
 	backgroundAudit   bool
+	mode              policiesv1.PolicyMode
 	status            policiesv1.PolicyStatusEnum
 }
 
 func NewClusterAdmissionPolicyFactory() *ClusterAdmissionPolicyFactory {
 	return &ClusterAdmissionPolicyFactory{
+		policyServer:    "default",
 		backgroundAudit: true,
+		mode:            "protect",
 		status:          policiesv1.PolicyStatusActive,
 	}
 }
@@ -320,6 +333,12 @@ func (factory *ClusterAdmissionPolicyFactory) Name(name string) *ClusterAdmissio
 	return factory
 }
 
+func (factory *ClusterAdmissionPolicyFactory) PolicyServer(policyServer string) *ClusterAdmissionPolicyFactory {
+	factory.policyServer = policyServer
+
+	return factory
+}
+
 func (factory *ClusterAdmissionPolicyFactory) NamespaceSelector(selector *metav1.LabelSelector) *ClusterAdmissionPolicyFactory {
 	factory.namespaceSelector = selector
 
@@ -350,6 +369,12 @@ func (factory *ClusterAdmissionPolicyFactory) BackgroundAudit(backgroundAudit bo
 	return factory
 }
 
+func (factory *ClusterAdmissionPolicyFactory) Mode(mode policiesv1.PolicyMode) *ClusterAdmissionPolicyFactory {
+	factory.mode = mode
+
+	return factory
+}
+
 func (factory *ClusterAdmissionPolicyFactory) Status(status policiesv1.PolicyStatusEnum) *ClusterAdmissionPolicyFactory {
 	factory.status = status
 
@@ -369,9 +394,10 @@ func (factory *ClusterAdmissionPolicyFactory) Build() *policiesv1.ClusterAdmissi
 			NamespaceSelector: factory.namespaceSelector,
 			PolicySpec: policiesv1.PolicySpec{
 				ObjectSelector:  factory.objectSelector,
-				PolicyServer:    "default",
+				PolicyServer:    factory.policyServer,
 				Rules:           factory.rules,
 				BackgroundAudit: factory.backgroundAudit,
+				Mode:            factory.mode,
 			},
 		},
 		Status: policiesv1.PolicyStatus{
@@ -0,0 +1,50 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	instrumentationName = "github.com/kubewarden/audit-scanner"
+	serviceName         = "audit-scanner"
+)
+
+// Setup configures the global tracer provider to export the spans to the
+// OTLP/HTTP endpoint, e.g. http://otel-collector:4318.
+// When endpoint is empty tracing is left disabled: the global tracer provider
+// stays the no-op one, so creating spans has no overhead.
+// The returned function flushes the pending spans and must be called before
+// the program exits.
+func Setup(ctx context.Context, endpoint string) (func(context.Context) error, error) {
+	if endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("cannot create OTLP exporter for %s: %w", endpoint, err)
+	}
+
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", serviceName))),
+	)
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	return tracerProvider.Shutdown, nil
+}
+
+// Tracer returns the tracer used to instrument the audit scanner.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
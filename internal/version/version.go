@@ -0,0 +1,26 @@
+package version
+
+import "fmt"
+
+// The build information of the audit scanner, set at build time with -ldflags, e.g.
+// -X github.com/kubewarden/audit-scanner/internal/version.Version=v1.2.3.
+var (
+	// Version is the released version, or "dev" for development builds
+	Version = "dev"
+	// Commit is the git commit the audit scanner has been built from
+	Commit = "unknown"
+	// BuildDate is the time of the build, in RFC 3339 format
+	BuildDate = "unknown"
+)
+
+// String returns the version, the commit and the build date, e.g.
+// "v1.2.3 (commit 1a2b3c4, built 2025-03-04T10:30:00Z)".
+func String() string {
+	return fmt.Sprintf("%s (commit %s, built %s)", Version, Commit, BuildDate)
+}
+
+// UserAgent returns the default User-Agent of the requests sent by the audit
+// scanner, e.g. kubewarden-audit-scanner/v1.2.3.
+func UserAgent() string {
+	return "kubewarden-audit-scanner/" + Version
+}